@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+
+	"github.com/nullbull/gotch/half"
 )
 
 // CInt is equal to C type int. Go type is int32
@@ -314,13 +316,31 @@ func GoKind2DType(kind reflect.Kind, opts ...DTypeOpt) (DType, error) {
 	}
 }
 
+var (
+	float16Type  = reflect.TypeOf(half.Float16(0))
+	bfloat16Type = reflect.TypeOf(half.BFloat16(0))
+)
+
+// GoType2DType returns DType of Go type. Unlike GoKind2DType, it maps `half.Float16`
+// and `half.BFloat16` to `Half` and `BFloat16` regardless of HalfDTypePref option.
+func GoType2DType(typ reflect.Type, opts ...DTypeOpt) (DType, error) {
+	switch typ {
+	case float16Type:
+		return Half, nil
+	case bfloat16Type:
+		return BFloat16, nil
+	}
+
+	return GoKind2DType(typ.Kind(), opts...)
+}
+
 var dtype2GoType map[DType]reflect.Type = map[DType]reflect.Type{
 	Uint8:  reflect.TypeOf(uint8(0)),
 	Int8:   reflect.TypeOf(int8(0)),
 	Int16:  reflect.TypeOf(int16(0)),
 	Int:    reflect.TypeOf(int(0)),
 	Int64:  reflect.TypeOf(int64(0)),
-	Half:   reflect.TypeOf(uint16(0)), // <- just uint16
+	Half:   reflect.TypeOf(half.Float16(0)),
 	Float:  reflect.TypeOf(float32(0)),
 	Double: reflect.TypeOf(float64(0)),
 	// ComplexHalf:   reflect.Invalid, // no equivalent in Go. Would it be reflect.Float64?
@@ -330,7 +350,7 @@ var dtype2GoType map[DType]reflect.Type = map[DType]reflect.Type{
 	QInt8:         reflect.TypeOf(int8(0)),
 	QUInt8:        reflect.TypeOf(uint8(0)),
 	QInt32:        reflect.TypeOf(int32(0)),
-	BFloat16:      reflect.TypeOf(uint16(0)), // <- just uint16
+	BFloat16:      reflect.TypeOf(half.BFloat16(0)),
 	// ---not implemented ---
 	QUInt4x2: reflect.TypeOf(int8(0)),
 	QUInt2x4: reflect.TypeOf(uint8(0)),
@@ -387,12 +407,11 @@ func DTypeFromData(data interface{}) (DType, error) {
 
 	// Data is a slice/array
 	if dataKind == reflect.Slice || dataKind == reflect.Array {
-		elementKind := reflect.TypeOf(data).Elem().Kind()
-		return GoKind2DType(elementKind)
+		return GoType2DType(reflect.TypeOf(data).Elem())
 	}
 
 	// single element
-	return GoKind2DType(dataKind)
+	return GoType2DType(reflect.TypeOf(data))
 }
 
 // IsFloatDType returns whether dtype is floating point data type.
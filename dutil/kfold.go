@@ -2,8 +2,9 @@ package dutil
 
 import (
 	"fmt"
-	"math/rand"
 	"sort"
+
+	"github.com/nullbull/gotch"
 )
 
 // KFold represents a struct helper to
@@ -81,7 +82,7 @@ func (kf *KFold) Split() []Fold {
 	fsize := nsamples / kf.nfolds
 	var indices []int
 
-	allIndices := rand.Perm(kf.n)
+	allIndices := gotch.RandPerm(kf.n)
 	// Drop last odd-time elements
 	indices = allIndices[:nsamples]
 
@@ -3,7 +3,8 @@ package dutil
 import (
 	"fmt"
 	"math/rand"
-	"time"
+
+	"github.com/nullbull/gotch"
 )
 
 // Sampler represents an interface to draw sample
@@ -112,7 +113,7 @@ func NewRandomSampler(n int, opt ...RandOption) (*RandomSampler, error) {
 
 // Sample implements Sampler interface.
 func (s *RandomSampler) Sample() []int {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := rand.New(rand.NewSource(gotch.RandInt63()))
 	var indices []int
 
 	if !s.replacement {
@@ -205,7 +206,7 @@ func (s *BatchSampler) Sample() []int {
 		}
 	case true:
 		// random permutation
-		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		r := rand.New(rand.NewSource(gotch.RandInt63()))
 		indices = r.Perm(s.n)
 	}
 
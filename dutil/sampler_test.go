@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/nullbull/gotch"
 	"github.com/nullbull/gotch/dutil"
 )
 
@@ -142,3 +143,19 @@ func seq(n int) []int {
 	}
 	return s
 }
+
+func TestRandomSamplerSeed(t *testing.T) {
+	s, err := dutil.NewRandomSampler(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotch.SeedRand(42)
+	want := s.Sample()
+	gotch.SeedRand(42)
+	got := s.Sample()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Want: %+v\n", want)
+		t.Errorf("Got: %+v\n", got)
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/nullbull/gotch
 
-go 1.19
+go 1.21
+
+require gonum.org/v1/gonum v0.12.0
@@ -3,6 +3,7 @@ package half
 import (
 	"math"
 	"math/bits"
+	"strconv"
 )
 
 // A 16-bit floating point type implementing the bfloat16 format.
@@ -165,3 +166,30 @@ func BFloat16ToFloat64(i uint16) float64 {
 
 	return math.Float64frombits(sign | exp | man)
 }
+
+// BFloat16Fromfloat32 returns a BFloat16 value converted from f32 with rounding to nearest.
+func BFloat16Fromfloat32(f32 float32) BFloat16 {
+	return BFloat16(Float32ToBFloat16(f32))
+}
+
+// BFloat16Fromfloat64 returns a BFloat16 value converted from f64 with rounding to nearest.
+func BFloat16Fromfloat64(f64 float64) BFloat16 {
+	return BFloat16(Float64ToBFloat16(f64))
+}
+
+// Float32 returns a float32 converted from b (BFloat16).
+// This is a lossless conversion.
+func (b BFloat16) Float32() float32 {
+	return BFloat16ToFloat32(uint16(b))
+}
+
+// Float64 returns a float64 converted from b (BFloat16).
+// This is a lossless conversion.
+func (b BFloat16) Float64() float64 {
+	return BFloat16ToFloat64(uint16(b))
+}
+
+// String satisfies the fmt.Stringer interface.
+func (b BFloat16) String() string {
+	return strconv.FormatFloat(float64(b.Float32()), 'f', -1, 32)
+}
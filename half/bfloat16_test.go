@@ -1 +1,32 @@
 package half
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBFloat16(t *testing.T) {
+	for _, v := range []float32{0, 1, -2.5, 0.15625, 3.3895314e+38} {
+		b := BFloat16Fromfloat32(v)
+		if got := b.Float32(); got != v {
+			t.Errorf("Expected %v, got %v", v, got)
+		}
+		if got := b.Float64(); got != float64(v) {
+			t.Errorf("Expected %v, got %v", v, got)
+		}
+		if got := BFloat16Fromfloat64(float64(v)); got != b {
+			t.Errorf("Expected %#04x, got %#04x", b, got)
+		}
+	}
+
+	// 1 + 2^-8 is not representable and rounds to 1.
+	if got := BFloat16Fromfloat32(1 + 1.0/256).Float32(); got != 1 {
+		t.Errorf("Expected 1, got %v", got)
+	}
+	if got := BFloat16Fromfloat32(float32(math.Inf(-1))).Float32(); !math.IsInf(float64(got), -1) {
+		t.Errorf("Expected -Inf, got %v", got)
+	}
+	if got := BFloat16Fromfloat32(-2.5).String(); got != "-2.5" {
+		t.Errorf("Expected -2.5, got %v", got)
+	}
+}
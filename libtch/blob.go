@@ -0,0 +1,61 @@
+package libtch
+
+// Tensors over external memory released via Go deleters.
+
+//#include "stdbool.h"
+//#include "stdint.h"
+//#include "torch_api.h"
+//void blob_free_fn(int64_t);
+//typedef void (*blob_free_f)(int64_t);
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// blobs stores deleters of tensor memory by ids as Go pointers can not be passed to C.
+var blobs = struct {
+	sync.Mutex
+	fns    map[int64]func()
+	nextID int64
+}{fns: make(map[int64]func())}
+
+// tensor at_tensor_of_blob_with_deleter(void *data, int64_t *dims, size_t ndims, int64_t *strides, size_t nstrides, int type, int device, int64_t id, void (*free_f)(int64_t));
+func AtTensorOfBlob(data unsafe.Pointer, dims, strides []int64, kind int, device int, deleter func()) Ctensor {
+	blobs.Lock()
+	id := blobs.nextID
+	blobs.nextID++
+	blobs.fns[id] = deleter
+	blobs.Unlock()
+
+	var cdimsPtr, cstridesPtr *C.int64_t
+	if len(dims) > 0 {
+		cdimsPtr = (*C.int64_t)(unsafe.Pointer(&dims[0]))
+	}
+	if len(strides) > 0 {
+		cstridesPtr = (*C.int64_t)(unsafe.Pointer(&strides[0]))
+	}
+
+	// NOTE. deleter is called via `blob_free_fn` when libtorch releases the tensor storage,
+	// or right away if tensor creation fails.
+	ctensor := C.at_tensor_of_blob_with_deleter(data, cdimsPtr, C.size_t(len(dims)), cstridesPtr, C.size_t(len(strides)),
+		C.int(kind), C.int(device), C.int64_t(id), C.blob_free_f(C.blob_free_fn))
+	if ctensor == nil {
+		blob_free_fn(C.int64_t(id))
+	}
+
+	return ctensor
+}
+
+//export blob_free_fn
+func blob_free_fn(id C.int64_t) {
+	blobs.Lock()
+	fn, ok := blobs.fns[int64(id)]
+	delete(blobs.fns, int64(id))
+	blobs.Unlock()
+
+	if ok && fn != nil {
+		fn()
+	}
+}
@@ -0,0 +1,93 @@
+package libtch
+
+// Custom autograd functions with backward called back from libtorch autograd.
+
+//#include "stdbool.h"
+//#include "stdint.h"
+//#include "stdlib.h"
+//#include "torch_api.h"
+//char *function_backward_fn(int64_t, tensor *, int, tensor *, int);
+//void function_free_fn(int64_t);
+//typedef char *(*function_backward_f)(int64_t, tensor *, int, tensor *, int);
+//typedef void (*function_free_f)(int64_t);
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// FunctionBackwardFn is a Go function called by libtorch with gradients with respect to
+// outputs of a custom function (nil for non-differentiable outputs). It returns new C tensors
+// (to be owned by libtorch) of gradients with respect to inputs, nil for no gradient.
+type FunctionBackwardFn func(grads []Ctensor) ([]Ctensor, error)
+
+// functions stores backward functions by ids as Go pointers can not be passed to C.
+var functions = struct {
+	sync.Mutex
+	fns    map[int64]FunctionBackwardFn
+	nextID int64
+}{fns: make(map[int64]FunctionBackwardFn)}
+
+// void at_function_apply(int64_t id, tensor *inputs, int ninputs, tensor *outputs, int noutputs, tensor *results, char *(*backward_f)(int64_t, tensor *, int, tensor *, int), void (*free_f)(int64_t));
+func AtFunctionApply(inputs, outputs, results []Ctensor, fn FunctionBackwardFn) {
+	functions.Lock()
+	id := functions.nextID
+	functions.nextID++
+	functions.fns[id] = fn
+	functions.Unlock()
+
+	var cinputsPtr *Ctensor
+	if len(inputs) > 0 {
+		cinputsPtr = (*Ctensor)(unsafe.Pointer(&inputs[0]))
+	}
+	coutputsPtr := (*Ctensor)(unsafe.Pointer(&outputs[0]))
+	cresultsPtr := (*Ctensor)(unsafe.Pointer(&results[0]))
+
+	// NOTE. backward function is released via `function_free_fn` when libtorch
+	// drops the graph node, or right away if no gradient is required.
+	C.at_function_apply(C.int64_t(id), cinputsPtr, C.int(len(inputs)), coutputsPtr, C.int(len(outputs)), cresultsPtr,
+		C.function_backward_f(C.function_backward_fn), C.function_free_f(C.function_free_fn))
+}
+
+//export function_backward_fn
+func function_backward_fn(id C.int64_t, cgrads *C.tensor, ngrads C.int, cresults *C.tensor, nresults C.int) *C.char {
+	grads := unsafe.Slice((*Ctensor)(unsafe.Pointer(cgrads)), int(ngrads))
+	results := unsafe.Slice((*Ctensor)(unsafe.Pointer(cresults)), int(nresults))
+
+	functions.Lock()
+	fn, ok := functions.fns[int64(id)]
+	functions.Unlock()
+	if !ok {
+		for _, g := range grads {
+			if g != nil {
+				C.at_free(g)
+			}
+		}
+		return C.CString(fmt.Sprintf("custom function %d has been released", int64(id)))
+	}
+
+	out, err := fn(append([]Ctensor{}, grads...))
+	if err == nil && len(out) != len(results) {
+		err = fmt.Errorf("custom function backward returned %d gradients, expected %d", len(out), len(results))
+	}
+	if err != nil {
+		for _, g := range out {
+			if g != nil {
+				C.at_free(g)
+			}
+		}
+		return C.CString(err.Error())
+	}
+	copy(results, out)
+
+	return nil
+}
+
+//export function_free_fn
+func function_free_fn(id C.int64_t) {
+	functions.Lock()
+	delete(functions.fns, int64(id))
+	functions.Unlock()
+}
@@ -0,0 +1,94 @@
+package libtch
+
+// Random number generators.
+
+//#include "stdbool.h"
+//#include "stdint.h"
+//#include "torch_api.h"
+import "C"
+
+import "unsafe"
+
+type Cgenerator = C.generator
+
+// generator at_default_generator(int device);
+func AtDefaultGenerator(device int) Cgenerator {
+	return C.at_default_generator(C.int(device))
+}
+
+// generator at_generator_new(int device, int64_t seed);
+func AtGeneratorNew(device int, seed int64) Cgenerator {
+	return C.at_generator_new(C.int(device), C.int64_t(seed))
+}
+
+// void at_generator_free(generator);
+func AtGeneratorFree(g Cgenerator) {
+	C.at_generator_free(g)
+}
+
+// void at_generator_manual_seed(generator, int64_t seed);
+func AtGeneratorManualSeed(g Cgenerator, seed int64) {
+	C.at_generator_manual_seed(g, C.int64_t(seed))
+}
+
+// int64_t at_generator_seed(generator);
+func AtGeneratorSeed(g Cgenerator) int64 {
+	return int64(C.at_generator_seed(g))
+}
+
+// tensor at_generator_get_state(generator);
+func AtGeneratorGetState(g Cgenerator) Ctensor {
+	return C.at_generator_get_state(g)
+}
+
+// void at_generator_set_state(generator, tensor state);
+func AtGeneratorSetState(g Cgenerator, state Ctensor) {
+	C.at_generator_set_state(g, state)
+}
+
+func sizePtr(size []int64) *C.int64_t {
+	if len(size) == 0 {
+		return nil
+	}
+	return (*C.int64_t)(unsafe.Pointer(&size[0]))
+}
+
+// tensor at_rand_gen(int64_t *size, int ndims, int kind, int device, generator);
+func AtRandGen(size []int64, kind, device int, g Cgenerator) Ctensor {
+	return C.at_rand_gen(sizePtr(size), C.int(len(size)), C.int(kind), C.int(device), g)
+}
+
+// tensor at_randn_gen(int64_t *size, int ndims, int kind, int device, generator);
+func AtRandnGen(size []int64, kind, device int, g Cgenerator) Ctensor {
+	return C.at_randn_gen(sizePtr(size), C.int(len(size)), C.int(kind), C.int(device), g)
+}
+
+// tensor at_randint_gen(int64_t low, int64_t high, int64_t *size, int ndims, int kind, int device, generator);
+func AtRandintGen(low, high int64, size []int64, kind, device int, g Cgenerator) Ctensor {
+	return C.at_randint_gen(C.int64_t(low), C.int64_t(high), sizePtr(size), C.int(len(size)), C.int(kind), C.int(device), g)
+}
+
+// tensor at_randperm_gen(int64_t n, int kind, int device, generator);
+func AtRandpermGen(n int64, kind, device int, g Cgenerator) Ctensor {
+	return C.at_randperm_gen(C.int64_t(n), C.int(kind), C.int(device), g)
+}
+
+// void at_uniform_gen_(tensor, double from, double to, generator);
+func AtUniformGen_(ts Ctensor, from, to float64, g Cgenerator) {
+	C.at_uniform_gen_(ts, C.double(from), C.double(to), g)
+}
+
+// void at_normal_gen_(tensor, double mean, double stddev, generator);
+func AtNormalGen_(ts Ctensor, mean, stddev float64, g Cgenerator) {
+	C.at_normal_gen_(ts, C.double(mean), C.double(stddev), g)
+}
+
+// tensor at_bernoulli_gen(tensor p, generator);
+func AtBernoulliGen(p Ctensor, g Cgenerator) Ctensor {
+	return C.at_bernoulli_gen(p, g)
+}
+
+// tensor at_multinomial_gen(tensor, int64_t num_samples, int replacement, generator);
+func AtMultinomialGen(ts Ctensor, numSamples int64, replacement int, g Cgenerator) Ctensor {
+	return C.at_multinomial_gen(ts, C.int64_t(numSamples), C.int(replacement), g)
+}
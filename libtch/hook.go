@@ -0,0 +1,71 @@
+package libtch
+
+// Gradient hooks called back from libtorch autograd.
+
+//#include "stdbool.h"
+//#include "stdint.h"
+//#include "torch_api.h"
+//tensor grad_hook_fn(int64_t, tensor, char **);
+//void grad_hook_free_fn(int64_t);
+//typedef tensor (*grad_hook_f)(int64_t, tensor, char **);
+//typedef void (*grad_hook_free_f)(int64_t);
+import "C"
+
+import "sync"
+
+// GradHookFn is a Go function called by libtorch with the gradient of a tensor.
+// It returns a new C tensor (to be owned by libtorch) to replace the gradient or
+// nil to keep it. An error fails the backward pass.
+type GradHookFn func(grad Ctensor) (Ctensor, error)
+
+// gradHooks stores Go hook functions by ids as Go pointers can not be passed to C.
+var gradHooks = struct {
+	sync.Mutex
+	fns    map[int64]GradHookFn
+	nextID int64
+}{fns: make(map[int64]GradHookFn)}
+
+// int at_register_hook(tensor, int64_t id, tensor (*f)(int64_t, tensor, char **), void (*free_f)(int64_t));
+func AtRegisterHook(ts Ctensor, fn GradHookFn) int {
+	gradHooks.Lock()
+	id := gradHooks.nextID
+	gradHooks.nextID++
+	gradHooks.fns[id] = fn
+	gradHooks.Unlock()
+
+	// NOTE. hook function is released via `grad_hook_free_fn` when libtorch
+	// drops the hook, i.e. hook is removed, tensor is freed or registration fails.
+	pos := C.at_register_hook(ts, C.int64_t(id), C.grad_hook_f(C.grad_hook_fn), C.grad_hook_free_f(C.grad_hook_free_fn))
+	return int(pos)
+}
+
+// void at_remove_hook(tensor, int pos);
+func AtRemoveHook(ts Ctensor, pos int) {
+	C.at_remove_hook(ts, C.int(pos))
+}
+
+//export grad_hook_fn
+func grad_hook_fn(id C.int64_t, grad C.tensor, cerr **C.char) C.tensor {
+	gradHooks.Lock()
+	fn, ok := gradHooks.fns[int64(id)]
+	gradHooks.Unlock()
+	if !ok {
+		C.at_free(grad)
+		return nil
+	}
+
+	// NOTE. error message is freed by libtorch side which raises it as C++ exception.
+	out, err := fn(grad)
+	if err != nil {
+		*cerr = C.CString(err.Error())
+	}
+
+	return out
+}
+
+//export grad_hook_free_fn
+func grad_hook_free_fn(id C.int64_t) {
+	gradHooks.Lock()
+	delete(gradHooks.fns, int64(id))
+	gradHooks.Unlock()
+}
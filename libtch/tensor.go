@@ -54,6 +54,11 @@ func AtNewTensor() Ctensor {
 	return C.at_new_tensor()
 }
 
+// void at_manual_seed(int64_t);
+func AtManualSeed(seed int64) {
+	C.at_manual_seed(C.int64_t(seed))
+}
+
 // tensor at_new_tensor();
 func NewTensor() Ctensor {
 	return C.at_new_tensor()
@@ -100,6 +105,11 @@ func AtIsSparse(ts Ctensor) bool {
 	return *(*bool)(unsafe.Pointer(&retVal))
 }
 
+// int at_layout(tensor);
+func AtLayout(ts Ctensor) int {
+	return int(C.at_layout(ts))
+}
+
 // int at_device(tensor);
 func AtDevice(ts Ctensor) int {
 	cint := C.at_device(ts)
@@ -231,6 +241,16 @@ func AtRunBackward(tensorsPtr *Ctensor, ntensors int, inputsPtr *Ctensor, ninput
 	C.at_run_backward(tensorsPtr, cntensors, inputsPtr, cninputs, outputsPtr, ckeepGraph, ccreateGraph)
 }
 
+// void at_grad(tensor *outputs, int noutputs, tensor *inputs, int ninputs, tensor *grad_outputs, tensor *results, int retain_graph, int create_graph, int allow_unused);
+func AtGrad(outputs []Ctensor, inputs []Ctensor, gradOutputs []Ctensor, results []Ctensor, retainGraph, createGraph, allowUnused int) {
+	coutputsPtr := (*Ctensor)(unsafe.Pointer(&outputs[0]))
+	cinputsPtr := (*Ctensor)(unsafe.Pointer(&inputs[0]))
+	cgradOutputsPtr := (*Ctensor)(unsafe.Pointer(&gradOutputs[0]))
+	cresultsPtr := (*Ctensor)(unsafe.Pointer(&results[0]))
+	C.at_grad(coutputsPtr, C.int(len(outputs)), cinputsPtr, C.int(len(inputs)), cgradOutputsPtr, cresultsPtr,
+		C.int(retainGraph), C.int(createGraph), C.int(allowUnused))
+}
+
 // void at_copy_data(tensor tensor, void *vs, size_t numel, size_t element_size_in_bytes);
 func AtCopyData(ts Ctensor, vs unsafe.Pointer, numel uint, element_size_in_bytes uint) {
 	cnumel := *(*C.size_t)(unsafe.Pointer(&numel))
@@ -407,6 +427,51 @@ func AtGradSetEnabled(b int) int {
 	return *(*int)(unsafe.Pointer(&cretVal))
 }
 
+// int at_grad_is_enabled();
+func AtGradIsEnabled() int {
+	return int(C.at_grad_is_enabled())
+}
+
+// void *at_inference_mode_new(int enabled);
+func AtInferenceModeNew(enabled int) unsafe.Pointer {
+	return C.at_inference_mode_new(C.int(enabled))
+}
+
+// void at_inference_mode_free(void *guard);
+func AtInferenceModeFree(guard unsafe.Pointer) {
+	C.at_inference_mode_free(guard)
+}
+
+// int at_is_inference_mode_enabled();
+func AtIsInferenceModeEnabled() int {
+	return int(C.at_is_inference_mode_enabled())
+}
+
+// void at_set_anomaly_mode(int enabled, int check_nan);
+func AtSetAnomalyMode(enabled, checkNaN int) {
+	C.at_set_anomaly_mode(C.int(enabled), C.int(checkNaN))
+}
+
+// int at_is_anomaly_enabled();
+func AtIsAnomalyEnabled() int {
+	return int(C.at_is_anomaly_enabled())
+}
+
+// void at_set_deterministic_algorithms(int enabled, int warn_only);
+func AtSetDeterministicAlgorithms(enabled, warnOnly int) {
+	C.at_set_deterministic_algorithms(C.int(enabled), C.int(warnOnly))
+}
+
+// int at_deterministic_algorithms();
+func AtDeterministicAlgorithms() int {
+	return int(C.at_deterministic_algorithms())
+}
+
+// int at_deterministic_algorithms_warn_only();
+func AtDeterministicAlgorithmsWarnOnly() int {
+	return int(C.at_deterministic_algorithms_warn_only())
+}
+
 /*
  * optimizer ato_adam(double learning_rate,
  *                    double beta1,
@@ -593,6 +658,33 @@ func AtSaveImage(ts Ctensor, path string) {
 	_ = C.at_save_image(ts, cpath)
 }
 
+// tensor at_load_image_from_memory(unsigned char *data, size_t len);
+func AtLoadImageFromMemory(data []byte) Ctensor {
+	var cdata *C.uchar
+	if len(data) > 0 {
+		cdata = (*C.uchar)(unsafe.Pointer(&data[0]))
+	}
+	return C.at_load_image_from_memory(cdata, C.size_t(len(data)))
+}
+
+// int at_encode_image(tensor, char *format, int quality, unsigned char **out, size_t *out_len);
+func AtEncodeImage(ts Ctensor, format string, quality int) []byte {
+	cformat := C.CString(format)
+	defer C.free(unsafe.Pointer(cformat))
+
+	var (
+		out    *C.uchar
+		outLen C.size_t
+	)
+	if C.at_encode_image(ts, cformat, C.int(quality), &out, &outLen) != 1 {
+		// error is handled with `TorchErr()`
+		return nil
+	}
+	defer C.free(unsafe.Pointer(out))
+
+	return C.GoBytes(unsafe.Pointer(out), C.int(outLen))
+}
+
 // tensor at_resize_image(tensor, int w, int h);
 func AtResizeImage(ts Ctensor, w, h int64) Ctensor {
 
@@ -602,6 +694,21 @@ func AtResizeImage(ts Ctensor, w, h int64) Ctensor {
 	return C.at_resize_image(ts, cw, ch)
 }
 
+// void *at_to_dlpack(tensor);
+func AtToDLPack(ts Ctensor) unsafe.Pointer {
+	return C.at_to_dlpack(ts)
+}
+
+// tensor at_from_dlpack(void *dl);
+func AtFromDLPack(dl unsafe.Pointer) Ctensor {
+	return C.at_from_dlpack(dl)
+}
+
+// void at_dlpack_free(void *dl);
+func AtDLPackFree(dl unsafe.Pointer) {
+	C.at_dlpack_free(dl)
+}
+
 // ivalue ati_none();
 func AtiNone() Civalue {
 	return C.ati_none()
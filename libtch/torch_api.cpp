@@ -1,8 +1,10 @@
 #include "torch_api.h"
 #include "ATen/core/interned_strings.h"
+#include <ATen/DLConvertor.h>
 #include <ATen/autocast_mode.h>
 #include <stdexcept>
 #include <torch/csrc/autograd/engine.h>
+#include <torch/csrc/autograd/functions/utils.h>
 #include <torch/csrc/jit/passes/fixup_trace_scope_blocks.h>
 #include <torch/csrc/jit/passes/normalize_ops.h>
 #include <torch/csrc/jit/runtime/graph_executor.h>
@@ -27,7 +29,7 @@ char *get_and_reset_last_err() {
   return tmp;
 }
 
-void at_manual_seed(int64_t seed) { torch::manual_seed(seed); }
+void at_manual_seed(int64_t seed) { PROTECT(torch::manual_seed(seed);) }
 
 vector<torch::Tensor> of_carray_tensor(torch::Tensor **vs, int len) {
   vector<torch::Tensor> result;
@@ -72,6 +74,29 @@ tensor at_tensor_of_blob(void *data, int64_t *dims, size_t ndims,
   return nullptr;
 }
 
+static torch::Tensor tensor_of_blob_with_deleter(void *data, int64_t *dims,
+                                                 size_t ndims, int64_t *strides,
+                                                 size_t nstrides, int type,
+                                                 int device, int64_t id,
+                                                 void (*free_f)(int64_t)) {
+  at::TensorOptions blobOptions = at::TensorOptions()
+                                      .device(device_of_int(device))
+                                      .dtype(torch::ScalarType(type));
+  return torch::from_blob(
+      data, torch::IntArrayRef(dims, ndims),
+      torch::IntArrayRef(strides, nstrides),
+      [id, free_f](void *) { free_f(id); }, blobOptions);
+}
+
+tensor at_tensor_of_blob_with_deleter(void *data, int64_t *dims, size_t ndims,
+                                      int64_t *strides, size_t nstrides,
+                                      int type, int device, int64_t id,
+                                      void (*free_f)(int64_t)) {
+  PROTECT(return new torch::Tensor(tensor_of_blob_with_deleter(
+              data, dims, ndims, strides, nstrides, type, device, id, free_f));)
+  return nullptr;
+}
+
 tensor at_tensor_of_data(void *vs, int64_t *dims, size_t ndims,
                          size_t element_size_in_bytes, int type) {
   PROTECT(torch::Tensor tensor = torch::zeros(torch::IntArrayRef(dims, ndims),
@@ -127,6 +152,11 @@ int at_is_sparse(tensor t) {
   return -1;
 }
 
+int at_layout(tensor t) {
+  PROTECT(return static_cast<int>(t->layout());)
+  return -1;
+}
+
 size_t at_dim(tensor t) {
   PROTECT(return t->dim();)
   return -1;
@@ -199,12 +229,276 @@ int at_requires_grad(tensor t) {
   return -1;
 }
 
+// GradHookGuard notifies Go side when a gradient hook is released.
+struct GradHookGuard {
+  int64_t id;
+  void (*free_f)(int64_t);
+  ~GradHookGuard() { free_f(id); }
+};
+
+static std::function<torch::Tensor(torch::Tensor)>
+make_grad_hook(int64_t id, tensor (*f)(int64_t, tensor, char **),
+               void (*free_f)(int64_t)) {
+  auto guard = std::shared_ptr<GradHookGuard>(new GradHookGuard{id, free_f});
+  return [guard, f](torch::Tensor grad) -> torch::Tensor {
+    char *err = nullptr;
+    tensor out = f(guard->id, new torch::Tensor(grad), &err);
+    if (err != nullptr) {
+      std::string msg(err);
+      free(err);
+      delete out;
+      throw std::runtime_error(msg);
+    }
+    if (out == nullptr)
+      return torch::Tensor(); // undefined tensor keeps the gradient
+    torch::Tensor result = *out;
+    delete out;
+    return result;
+  };
+}
+
+int at_register_hook(tensor t, int64_t id,
+                     tensor (*f)(int64_t, tensor, char **),
+                     void (*free_f)(int64_t)) {
+  PROTECT(return t->register_hook(make_grad_hook(id, f, free_f));)
+  return -1;
+}
+
+void at_remove_hook(tensor t, int pos) { PROTECT(t->remove_hook(pos);) }
+
+typedef char *(*function_backward_f)(int64_t, tensor *, int, tensor *, int);
+
+// GoFunction is an autograd node of a custom function with backward in Go.
+struct GoFunction : public torch::autograd::Node {
+  int64_t id;
+  function_backward_f backward_f;
+  void (*free_f)(int64_t);
+  vector<bool> differentiable;
+
+  GoFunction(int64_t id, function_backward_f backward_f,
+             void (*free_f)(int64_t), torch::autograd::edge_list &&next_edges)
+      : Node(std::move(next_edges)), id(id), backward_f(backward_f),
+        free_f(free_f) {}
+
+  ~GoFunction() override { free_f(id); }
+
+  std::string name() const override { return "GoFunction"; }
+
+  torch::autograd::variable_list
+  apply(torch::autograd::variable_list &&grads) override {
+    int ngrads = grads.size();
+    vector<tensor> cgrads(ngrads, nullptr);
+    for (int i = 0; i < ngrads; ++i) {
+      if (!differentiable[i])
+        continue;
+      // NOTE. Go side takes ownership of gradients.
+      cgrads[i] = new torch::Tensor(grads[i].defined()
+                                        ? grads[i]
+                                        : input_metadata(i).zeros_like());
+    }
+
+    int ninputs = num_outputs();
+    vector<tensor> cresults(ninputs, nullptr);
+    char *err = backward_f(id, cgrads.data(), ngrads, cresults.data(), ninputs);
+
+    torch::autograd::variable_list results(ninputs);
+    for (int i = 0; i < ninputs; ++i) {
+      if (cresults[i] != nullptr) {
+        results[i] = *cresults[i];
+        delete cresults[i];
+      }
+    }
+    if (err != nullptr) {
+      std::string msg(err);
+      free(err);
+      throw std::runtime_error(msg);
+    }
+
+    return results;
+  }
+};
+
+static void function_apply(int64_t id, tensor *inputs, int ninputs,
+                           tensor *outputs, int noutputs, tensor *results,
+                           function_backward_f backward_f,
+                           void (*free_f)(int64_t)) {
+  vector<torch::Tensor> inputs_ = of_carray_tensor(inputs, ninputs);
+  bool requires_grad = false;
+  for (auto &x : inputs_)
+    requires_grad |= x.defined() && x.requires_grad();
+
+  std::shared_ptr<GoFunction> node;
+  if (torch::autograd::GradMode::is_enabled() && requires_grad) {
+    node = std::shared_ptr<GoFunction>(
+        new GoFunction(id, backward_f, free_f,
+                       torch::autograd::collect_next_edges(inputs_)),
+        torch::autograd::deleteNode);
+  } else {
+    free_f(id);
+  }
+
+  for (int i = 0; i < noutputs; ++i) {
+    // NOTE. detach so that outputs aliasing inputs get their own history.
+    torch::Tensor out = outputs[i]->detach();
+    if (node) {
+      bool differentiable = out.is_floating_point() || out.is_complex();
+      if (differentiable)
+        torch::autograd::set_history(out, node);
+      else
+        node->add_input_metadata(torch::autograd::Node::undefined_input());
+      node->differentiable.push_back(differentiable);
+    }
+    results[i] = new torch::Tensor(out);
+  }
+}
+
+void at_function_apply(int64_t id, tensor *inputs, int ninputs,
+                       tensor *outputs, int noutputs, tensor *results,
+                       function_backward_f backward_f,
+                       void (*free_f)(int64_t)) {
+  PROTECT(function_apply(id, inputs, ninputs, outputs, noutputs, results,
+                         backward_f, free_f);)
+}
+
 int at_grad_set_enabled(int b) {
   PROTECT(bool is_enabled = torch::autograd::GradMode::is_enabled();
           torch::autograd::GradMode::set_enabled(b); return is_enabled;)
   return -1;
 }
 
+int at_grad_is_enabled() {
+  PROTECT(return torch::autograd::GradMode::is_enabled();)
+  return -1;
+}
+
+void *at_inference_mode_new(int enabled) {
+  PROTECT(return new c10::InferenceMode(enabled);)
+  return nullptr;
+}
+
+void at_inference_mode_free(void *guard) {
+  PROTECT(delete static_cast<c10::InferenceMode *>(guard);)
+}
+
+int at_is_inference_mode_enabled() {
+  PROTECT(return c10::InferenceMode::is_enabled();)
+  return -1;
+}
+
+void at_set_anomaly_mode(int enabled, int check_nan) {
+  PROTECT(torch::autograd::AnomalyMode::set_enabled(enabled, check_nan);)
+}
+
+int at_is_anomaly_enabled() {
+  PROTECT(return torch::autograd::AnomalyMode::is_enabled();)
+  return -1;
+}
+
+void at_set_deterministic_algorithms(int enabled, int warn_only) {
+  PROTECT(at::globalContext().setDeterministicAlgorithms(enabled, warn_only);)
+}
+
+int at_deterministic_algorithms() {
+  PROTECT(return at::globalContext().deterministicAlgorithms();)
+  return -1;
+}
+
+int at_deterministic_algorithms_warn_only() {
+  PROTECT(return at::globalContext().deterministicAlgorithmsWarnOnly();)
+  return -1;
+}
+
+generator at_default_generator(int device) {
+  PROTECT(return new at::Generator(
+              at::globalContext().defaultGenerator(device_of_int(device)));)
+  return nullptr;
+}
+
+generator at_generator_new(int device, int64_t seed) {
+  PROTECT(
+      // Generator copy shares the default generator implementation and its mutex.
+      auto def = at::globalContext().defaultGenerator(device_of_int(device));
+      std::unique_lock<std::mutex> lock(def.mutex());
+      auto gen = def.clone();
+      lock.unlock();
+      gen.set_current_seed(seed);
+      return new at::Generator(gen);)
+  return nullptr;
+}
+
+void at_generator_free(generator g) { delete g; }
+
+void at_generator_manual_seed(generator g, int64_t seed) {
+  PROTECT(std::lock_guard<std::mutex> lock(g->mutex());
+          g->set_current_seed(seed);)
+}
+
+int64_t at_generator_seed(generator g) {
+  PROTECT(return (int64_t)g->current_seed();)
+  return -1;
+}
+
+tensor at_generator_get_state(generator g) {
+  PROTECT(std::lock_guard<std::mutex> lock(g->mutex());
+          return new torch::Tensor(g->get_state());)
+  return nullptr;
+}
+
+void at_generator_set_state(generator g, tensor state) {
+  PROTECT(std::lock_guard<std::mutex> lock(g->mutex());
+          g->set_state(*state);)
+}
+
+static at::TensorOptions options_of(int kind, int device) {
+  return at::device(device_of_int(device)).dtype(at::ScalarType(kind));
+}
+
+tensor at_rand_gen(int64_t *size, int ndims, int kind, int device, generator g) {
+  PROTECT(return new torch::Tensor(torch::rand(
+              torch::IntArrayRef(size, ndims), *g, options_of(kind, device)));)
+  return nullptr;
+}
+
+tensor at_randn_gen(int64_t *size, int ndims, int kind, int device, generator g) {
+  PROTECT(return new torch::Tensor(torch::randn(
+              torch::IntArrayRef(size, ndims), *g, options_of(kind, device)));)
+  return nullptr;
+}
+
+tensor at_randint_gen(int64_t low, int64_t high, int64_t *size, int ndims,
+                      int kind, int device, generator g) {
+  PROTECT(return new torch::Tensor(
+              torch::randint(low, high, torch::IntArrayRef(size, ndims), *g,
+                             options_of(kind, device)));)
+  return nullptr;
+}
+
+tensor at_randperm_gen(int64_t n, int kind, int device, generator g) {
+  PROTECT(return new torch::Tensor(
+              torch::randperm(n, *g, options_of(kind, device)));)
+  return nullptr;
+}
+
+void at_uniform_gen_(tensor t, double from, double to, generator g) {
+  PROTECT(t->uniform_(from, to, *g);)
+}
+
+void at_normal_gen_(tensor t, double mean, double stddev, generator g) {
+  PROTECT(t->normal_(mean, stddev, *g);)
+}
+
+tensor at_bernoulli_gen(tensor p, generator g) {
+  PROTECT(return new torch::Tensor(torch::bernoulli(*p, *g));)
+  return nullptr;
+}
+
+tensor at_multinomial_gen(tensor t, int64_t num_samples, int replacement,
+                          generator g) {
+  PROTECT(return new torch::Tensor(
+              torch::multinomial(*t, num_samples, replacement, *g));)
+  return nullptr;
+}
+
 tensor at_get(tensor t, int index) {
   PROTECT(return new torch::Tensor((*t)[index]);)
   return nullptr;
@@ -351,8 +645,10 @@ int at_save_image(tensor tensor, char *filename) {
   PROTECT(auto sizes = tensor->sizes();
           if (sizes.size() != 3) throw std::invalid_argument(
               "invalid number of dimensions, should be 3");
+          if (tensor->scalar_type() != at::kByte) throw std::invalid_argument(
+              "invalid dtype, should be uint8");
           int h = sizes[0]; int w = sizes[1]; int c = sizes[2];
-          auto tmp_tensor = tensor->contiguous();
+          auto tmp_tensor = tensor->to(at::kCPU).contiguous();
           void *tensor_data = tmp_tensor.data_ptr();
           if (ends_with(filename, ".jpg")) return stbi_write_jpg(
               filename, w, h, c, tensor_data, 90);
@@ -364,6 +660,53 @@ int at_save_image(tensor tensor, char *filename) {
   return -1;
 }
 
+tensor at_load_image_from_memory(unsigned char *data, size_t len) {
+  PROTECT(
+      int w = -1; int h = -1; int c = -1;
+      void *pixels = stbi_load_from_memory(data, (int)len, &w, &h, &c, 3);
+      if (pixels == nullptr) throw std::invalid_argument(stbi_failure_reason());
+      torch::Tensor tensor = torch::zeros({h, w, 3}, at::ScalarType::Byte);
+      memcpy(tensor.data_ptr(), pixels, h * w * 3); free(pixels);
+      return new torch::Tensor(tensor);)
+  return nullptr;
+}
+
+static void image_write_fn(void *context, void *data, int size) {
+  auto buf = (std::vector<unsigned char> *)context;
+  buf->insert(buf->end(), (unsigned char *)data, (unsigned char *)data + size);
+}
+
+static int encode_image(std::vector<unsigned char> *buf, const char *format,
+                        int w, int h, int c, void *data, int quality) {
+  if (strcmp(format, "jpg") == 0)
+    return stbi_write_jpg_to_func(image_write_fn, buf, w, h, c, data, quality);
+  if (strcmp(format, "bmp") == 0)
+    return stbi_write_bmp_to_func(image_write_fn, buf, w, h, c, data);
+  if (strcmp(format, "tga") == 0)
+    return stbi_write_tga_to_func(image_write_fn, buf, w, h, c, data);
+  if (strcmp(format, "png") == 0)
+    return stbi_write_png_to_func(image_write_fn, buf, w, h, c, data, 0);
+  throw std::invalid_argument(std::string("unsupported image format ") + format);
+}
+
+int at_encode_image(tensor tensor, char *format, int quality, unsigned char **out,
+                    size_t *out_len) {
+  PROTECT(auto sizes = tensor->sizes();
+          if (sizes.size() != 3) throw std::invalid_argument(
+              "invalid number of dimensions, should be 3");
+          if (tensor->scalar_type() != at::kByte) throw std::invalid_argument(
+              "invalid dtype, should be uint8");
+          int h = sizes[0]; int w = sizes[1]; int c = sizes[2];
+          auto tmp_tensor = tensor->to(at::kCPU).contiguous();
+          std::vector<unsigned char> buf;
+          if (!encode_image(&buf, format, w, h, c, tmp_tensor.data_ptr(), quality))
+              throw std::invalid_argument("image encoding failed");
+          *out = (unsigned char *)malloc(buf.size());
+          memcpy(*out, buf.data(), buf.size()); *out_len = buf.size();
+          return 1;)
+  return -1;
+}
+
 int at_get_num_interop_threads() {
   PROTECT(return at::get_num_interop_threads();)
   return -1;
@@ -399,6 +742,21 @@ tensor at_resize_image(tensor tensor, int out_w, int out_h) {
 
 void at_free(tensor t) { delete (t); }
 
+void *at_to_dlpack(tensor t) {
+  PROTECT(return at::toDLPack(*t);)
+  return nullptr;
+}
+
+tensor at_from_dlpack(void *dl) {
+  PROTECT(return new torch::Tensor(at::fromDLPack((DLManagedTensor *)dl));)
+  return nullptr;
+}
+
+void at_dlpack_free(void *dl) {
+  PROTECT(auto t = (DLManagedTensor *)dl;
+          if (t != nullptr && t->deleter != nullptr) t->deleter(t);)
+}
+
 void at_run_backward(tensor *tensors, int ntensors, tensor *inputs, int ninputs,
                      tensor *outputs, int keep_graph, int create_graph) {
   PROTECT(
@@ -425,6 +783,27 @@ void at_run_backward(tensor *tensors, int ntensors, tensor *inputs, int ninputs,
       })
 }
 
+void at_grad(tensor *outputs, int noutputs, tensor *inputs, int ninputs,
+             tensor *grad_outputs, tensor *results, int retain_graph,
+             int create_graph, int allow_unused) {
+  PROTECT(
+      vector<torch::Tensor> grad_outputs_;
+      for (int i = 0; i < noutputs; ++i) {
+        if (grad_outputs[i] == nullptr)
+          grad_outputs_.push_back(torch::ones_like(*outputs[i]));
+        else
+          grad_outputs_.push_back(*grad_outputs[i]);
+      }
+
+      auto grads = torch::autograd::grad(
+          of_carray_tensor(outputs, noutputs), of_carray_tensor(inputs, ninputs),
+          grad_outputs_, (bool)retain_graph, (bool)create_graph,
+          (bool)allow_unused);
+      for (int i = 0; i < ninputs; ++i) {
+        results[i] = grads[i].defined() ? new torch::Tensor(grads[i]) : nullptr;
+      })
+}
+
 optimizer ato_adam(double learning_rate, double beta1, double beta2,
                    double weight_decay) {
   PROTECT(auto options = torch::optim::AdamOptions(learning_rate)
@@ -15,6 +15,7 @@ typedef torch::Scalar *scalar;
 typedef torch::optim::Optimizer *optimizer;
 typedef torch::jit::script::Module *module;
 typedef torch::jit::IValue *ivalue;
+typedef at::Generator *generator;
 #define PROTECT(x)                                                             \
   try {                                                                        \
     x                                                                          \
@@ -27,6 +28,7 @@ typedef void *optimizer;
 typedef void *scalar;
 typedef void *module;
 typedef void *ivalue;
+typedef void *generator;
 #endif
 
 char *get_and_reset_last_err(); // thread-local
@@ -35,6 +37,12 @@ tensor at_new_tensor();
 tensor at_tensor_of_blob(void *data, int64_t *dims, size_t ndims,
                          int64_t *strides, size_t nstrides, int type,
                          int device);
+/* [at_tensor_of_blob_with_deleter] is [at_tensor_of_blob] with [free_f] called
+ * with [id] once the tensor storage releases [data]. */
+tensor at_tensor_of_blob_with_deleter(void *data, int64_t *dims, size_t ndims,
+                                      int64_t *strides, size_t nstrides,
+                                      int type, int device, int64_t id,
+                                      void (*free_f)(int64_t));
 tensor at_tensor_of_data(void *vs, int64_t *dims, size_t ndims,
                          size_t element_size_in_bytes, int type);
 void at_copy_data(tensor tensor, void *vs, size_t numel,
@@ -45,6 +53,7 @@ void *at_data_ptr(tensor);
 int at_defined(tensor);
 int at_is_mkldnn(tensor);
 int at_is_sparse(tensor);
+int at_layout(tensor);
 int at_device(tensor);
 size_t at_dim(tensor);
 void at_shape(tensor, int64_t *);
@@ -63,6 +72,68 @@ bool at_autocast_set_enabled(bool b);
 void at_backward(tensor, int, int);
 int at_requires_grad(tensor);
 int at_grad_set_enabled(int);
+int at_grad_is_enabled();
+
+/* [at_inference_mode_new] creates a c10::InferenceMode guard on the current
+ * thread. It must be freed on the same thread. */
+void *at_inference_mode_new(int enabled);
+void at_inference_mode_free(void *guard);
+int at_is_inference_mode_enabled();
+
+/* [at_set_anomaly_mode] enables/disables autograd anomaly detection globally.
+ * With [check_nan], backward ops returning NaN values raise an error. */
+void at_set_anomaly_mode(int enabled, int check_nan);
+int at_is_anomaly_enabled();
+
+/* [at_set_deterministic_algorithms] makes ops use deterministic algorithms and
+ * error (or warn with [warn_only]) on ops without one. */
+void at_set_deterministic_algorithms(int enabled, int warn_only);
+int at_deterministic_algorithms();
+int at_deterministic_algorithms_warn_only();
+
+/* Random number generators. [at_default_generator] returns a handle to the
+ * global generator of a device, [at_generator_new] a new generator. */
+generator at_default_generator(int device);
+generator at_generator_new(int device, int64_t seed);
+void at_generator_free(generator);
+void at_generator_manual_seed(generator, int64_t seed);
+int64_t at_generator_seed(generator);
+tensor at_generator_get_state(generator);
+void at_generator_set_state(generator, tensor state);
+
+tensor at_rand_gen(int64_t *size, int ndims, int kind, int device, generator);
+tensor at_randn_gen(int64_t *size, int ndims, int kind, int device, generator);
+tensor at_randint_gen(int64_t low, int64_t high, int64_t *size, int ndims,
+                      int kind, int device, generator);
+tensor at_randperm_gen(int64_t n, int kind, int device, generator);
+void at_uniform_gen_(tensor, double from, double to, generator);
+void at_normal_gen_(tensor, double mean, double stddev, generator);
+tensor at_bernoulli_gen(tensor p, generator);
+tensor at_multinomial_gen(tensor, int64_t num_samples, int replacement,
+                          generator);
+
+/* [at_register_hook] registers a gradient hook on a tensor and returns its
+ * position. [f] is called with hook [id] and the gradient. It returns a new
+ * gradient or nullptr to keep the original one, and sets a malloc-ed error
+ * message on failure. [free_f] is called with [id] once the hook is released
+ * by libtorch. */
+int at_register_hook(tensor, int64_t id,
+                     tensor (*f)(int64_t, tensor, char **),
+                     void (*free_f)(int64_t));
+void at_remove_hook(tensor, int pos);
+
+/* [at_function_apply] connects [outputs] of a custom function computed from
+ * [inputs] to the autograd graph and returns them as new tensors in [results].
+ * [backward_f] is called with function [id], gradients with respect to outputs
+ * (nullptr for non-differentiable outputs) and an array to be filled with
+ * gradients with respect to inputs. It returns an error message allocated
+ * with malloc or nullptr. [free_f] is called with [id] once the function is
+ * released by libtorch. */
+void at_function_apply(int64_t id, tensor *inputs, int ninputs,
+                       tensor *outputs, int noutputs, tensor *results,
+                       char *(*backward_f)(int64_t, tensor *, int, tensor *,
+                                           int),
+                       void (*free_f)(int64_t));
 
 tensor at_get(tensor, int index);
 void at_fill_double(tensor, double);
@@ -83,8 +154,18 @@ void at_save(tensor, char *filename);
 tensor at_load(char *filename);
 tensor at_load_image(char *filename);
 int at_save_image(tensor, char *filename);
+tensor at_load_image_from_memory(unsigned char *data, size_t len);
+/* [at_encode_image] returns encoded image in a malloc-ed buffer to be freed by the caller. */
+int at_encode_image(tensor, char *format, int quality, unsigned char **out,
+                    size_t *out_len);
 tensor at_resize_image(tensor, int w, int h);
 
+/* DLPack tensors are passed as opaque DLManagedTensor pointers. */
+void *at_to_dlpack(tensor);
+/* [at_from_dlpack] takes ownership of [dl] on success only. */
+tensor at_from_dlpack(void *dl);
+void at_dlpack_free(void *dl);
+
 void at_save_multi(tensor *tensors, char **tensor_names, int ntensors,
                    char *filename);
 /* [at_load_multi] takes as input an array of nullptr for [tensors]. */
@@ -113,6 +194,13 @@ void at_free(tensor);
 void at_run_backward(tensor *tensors, int ntensors, tensor *inputs, int ninputs,
                      tensor *outputs, int keep_graph, int create_graph);
 
+/* [at_grad] computes gradients of [outputs] with respect to [inputs] into
+ * [results]. [grad_outputs] elements can be nullptr for ones. Gradients of
+ * unused inputs are nullptr when [allow_unused] is set. */
+void at_grad(tensor *outputs, int noutputs, tensor *inputs, int ninputs,
+             tensor *grad_outputs, tensor *results, int retain_graph,
+             int create_graph, int allow_unused);
+
 optimizer ato_adam(double learning_rate, double beta1, double beta2,
                    double weight_decay);
 optimizer ato_adamw(double learning_rate, double beta1, double beta2,
@@ -76,6 +76,7 @@ func BatchNorm3D(vs *Path, outDim int64, config *BatchNormConfig) *BatchNorm {
 // ==========================================
 
 func (bn *BatchNorm) ForwardT(xs *ts.Tensor, train bool) (retVal *ts.Tensor) {
+	xs = preForward(bn, xs)
 
 	dim := xs.Dim()
 
@@ -87,8 +88,8 @@ func (bn *BatchNorm) ForwardT(xs *ts.Tensor, train bool) (retVal *ts.Tensor) {
 		log.Fatalf("Expected an input tensor with %v dims, got %v\n", bn.Nd+2, xs.MustSize())
 	}
 
-	return ts.MustBatchNorm(xs, bn.Ws, bn.Bs, bn.RunningMean, bn.RunningVar, train, bn.config.Momentum, bn.config.Eps, bn.config.CudnnEnable)
-
+	retVal = ts.MustBatchNorm(xs, bn.Ws, bn.Bs, bn.RunningMean, bn.RunningVar, train, bn.config.Momentum, bn.config.Eps, bn.config.CudnnEnable)
+	return postForward(bn, xs, retVal)
 }
 
 // Forward forwards inputs through the module.
@@ -96,6 +97,7 @@ func (bn *BatchNorm) ForwardT(xs *ts.Tensor, train bool) (retVal *ts.Tensor) {
 // This forwarding will update BatchNorm weight by default (training=true).
 // Wrap module with tensor.NoGrad() when running model inference mode.
 func (bn *BatchNorm) Forward(xs *ts.Tensor) (retVal *ts.Tensor) {
+	xs = preForward(bn, xs)
 	dim := xs.Dim()
 
 	if bn.Nd == 1 && dim != 2 && dim != 3 {
@@ -106,5 +108,6 @@ func (bn *BatchNorm) Forward(xs *ts.Tensor) (retVal *ts.Tensor) {
 		log.Fatalf("Expected an input tensor with %v dims, got %v\n", bn.Nd+2, xs.MustSize())
 	}
 
-	return ts.MustBatchNorm(xs, bn.Ws, bn.Bs, bn.RunningMean, bn.RunningVar, true, bn.config.Momentum, bn.config.Eps, bn.config.CudnnEnable)
+	retVal = ts.MustBatchNorm(xs, bn.Ws, bn.Bs, bn.RunningMean, bn.RunningVar, true, bn.config.Momentum, bn.config.Eps, bn.config.CudnnEnable)
+	return postForward(bn, xs, retVal)
 }
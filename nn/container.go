@@ -0,0 +1,330 @@
+package nn
+
+// Container modules and combinators of branches.
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+// ModuleList:
+// ===========
+
+// ModuleList holds submodules in a list. Unlike Sequential, it does not
+// define forward pass. It is used to hold modules called in custom forward code.
+type ModuleList struct {
+	modules []interface{}
+}
+
+// NewModuleList creates a ModuleList of modules (ts.Module or ts.ModuleT).
+func NewModuleList(modules ...interface{}) *ModuleList {
+	return &ModuleList{modules: append([]interface{}{}, modules...)}
+}
+
+// Len returns number of modules.
+func (l *ModuleList) Len() int {
+	return len(l.modules)
+}
+
+// Append appends modules to the end of the list.
+func (l *ModuleList) Append(modules ...interface{}) {
+	l.modules = append(l.modules, modules...)
+}
+
+// Get returns module at index.
+func (l *ModuleList) Get(idx int) interface{} {
+	return l.modules[idx]
+}
+
+// Insert inserts a module at index.
+func (l *ModuleList) Insert(idx int, m interface{}) error {
+	if idx < 0 || idx > len(l.modules) {
+		return fmt.Errorf("ModuleList.Insert() failed: index %d out of range [0, %d].", idx, len(l.modules))
+	}
+	l.modules = append(l.modules[:idx], append([]interface{}{m}, l.modules[idx:]...)...)
+
+	return nil
+}
+
+// Remove removes and returns module at index.
+func (l *ModuleList) Remove(idx int) (interface{}, error) {
+	if idx < 0 || idx >= len(l.modules) {
+		return nil, fmt.Errorf("ModuleList.Remove() failed: index %d out of range [0, %d).", idx, len(l.modules))
+	}
+	m := l.modules[idx]
+	l.modules = append(l.modules[:idx], l.modules[idx+1:]...)
+
+	return m, nil
+}
+
+// Modules returns a copy of the list of modules.
+func (l *ModuleList) Modules() []interface{} {
+	return append([]interface{}{}, l.modules...)
+}
+
+func (l *ModuleList) Name() string { return "ModuleList" }
+func (l *ModuleList) Children() []NamedModule {
+	children := make([]NamedModule, len(l.modules))
+	for i, m := range l.modules {
+		children[i] = NamedModule{Name: fmt.Sprint(i), Module: m}
+	}
+
+	return children
+}
+func (l *ModuleList) Parameters() []ts.NamedTensor { return nil }
+
+// ModuleDict:
+// ===========
+
+// ModuleDict holds named submodules preserving insertion order.
+type ModuleDict struct {
+	keys    []string
+	modules map[string]interface{}
+}
+
+// NewModuleDict creates an empty ModuleDict.
+func NewModuleDict() *ModuleDict {
+	return &ModuleDict{modules: make(map[string]interface{})}
+}
+
+// Len returns number of modules.
+func (d *ModuleDict) Len() int {
+	return len(d.keys)
+}
+
+// Set adds or replaces a module with given name. New names are appended at the end.
+func (d *ModuleDict) Set(name string, m interface{}) {
+	if _, ok := d.modules[name]; !ok {
+		d.keys = append(d.keys, name)
+	}
+	d.modules[name] = m
+}
+
+// Get returns module with given name.
+func (d *ModuleDict) Get(name string) (interface{}, bool) {
+	m, ok := d.modules[name]
+	return m, ok
+}
+
+// MustGet returns module with given name. It panics if not found.
+func (d *ModuleDict) MustGet(name string) interface{} {
+	m, ok := d.modules[name]
+	if !ok {
+		log.Fatalf("ModuleDict.MustGet() failed: module %q not found.\n", name)
+	}
+
+	return m
+}
+
+// Delete removes module with given name. It is a no-op if not found.
+func (d *ModuleDict) Delete(name string) {
+	if _, ok := d.modules[name]; !ok {
+		return
+	}
+	delete(d.modules, name)
+	for i, k := range d.keys {
+		if k == name {
+			d.keys = append(d.keys[:i], d.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns names of modules in insertion order.
+func (d *ModuleDict) Keys() []string {
+	return append([]string{}, d.keys...)
+}
+
+func (d *ModuleDict) Name() string { return "ModuleDict" }
+func (d *ModuleDict) Children() []NamedModule {
+	children := make([]NamedModule, len(d.keys))
+	for i, k := range d.keys {
+		children[i] = NamedModule{Name: k, Module: d.modules[k]}
+	}
+
+	return children
+}
+func (d *ModuleDict) Parameters() []ts.NamedTensor { return nil }
+
+// Branches:
+// =========
+
+// branches holds optionally named branches of a combinator.
+type branches struct {
+	modules []ts.ModuleT
+	names   []string
+}
+
+func newBranches(modules []ts.ModuleT) branches {
+	return branches{
+		modules: append([]ts.ModuleT{}, modules...),
+		names:   make([]string, len(modules)),
+	}
+}
+
+// Add appends a branch.
+func (b *branches) Add(m ts.ModuleT) {
+	b.AddNamed("", m)
+}
+
+// AddNamed appends a named branch.
+func (b *branches) AddNamed(name string, m ts.ModuleT) {
+	b.modules = append(b.modules, m)
+	b.names = append(b.names, name)
+}
+
+// Len returns number of branches.
+func (b *branches) Len() int {
+	return len(b.modules)
+}
+
+func (b *branches) Children() []NamedModule {
+	children := make([]NamedModule, len(b.modules))
+	for i, m := range b.modules {
+		children[i] = NamedModule{Name: layerName(b.names, i), Module: m}
+	}
+
+	return children
+}
+
+func (b *branches) Parameters() []ts.NamedTensor { return nil }
+
+// forwardT runs all branches on the same input.
+func (b *branches) forwardT(xs *ts.Tensor, train bool) []*ts.Tensor {
+	outs := make([]*ts.Tensor, len(b.modules))
+	for i, m := range b.modules {
+		outs[i] = forwardLayerT(m, xs, train)
+	}
+
+	return outs
+}
+
+// Parallel:
+// =========
+
+// Parallel runs branches on the same input and merges their outputs.
+type Parallel struct {
+	branches
+	merge func(outs []*ts.Tensor) *ts.Tensor
+}
+
+// NewParallel creates a Parallel combinator. `merge` combines outputs of branches
+// and must not drop them. If `merge` is nil, outputs are summed.
+func NewParallel(merge func(outs []*ts.Tensor) *ts.Tensor, branches ...ts.ModuleT) *Parallel {
+	return &Parallel{branches: newBranches(branches), merge: merge}
+}
+
+// ForwardT implements ts.ModuleT interface.
+func (p *Parallel) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
+	xs = preForward(p, xs)
+	outs := p.forwardT(xs, train)
+
+	var out *ts.Tensor
+	if p.merge != nil {
+		out = p.merge(outs)
+	} else {
+		out = outs[0].MustShallowClone()
+		for _, o := range outs[1:] {
+			out = out.MustAdd(o, true)
+		}
+	}
+	for _, o := range outs {
+		o.MustDrop()
+	}
+
+	return runForwardHooks(p, xs, out)
+}
+
+// Forward implements ts.Module interface. Branches run in evaluation mode.
+func (p *Parallel) Forward(xs *ts.Tensor) *ts.Tensor {
+	return p.ForwardT(xs, false)
+}
+
+func (p *Parallel) Name() string { return "Parallel" }
+
+// Concat:
+// =======
+
+// Concat runs branches on the same input and concatenates their outputs along a dimension.
+type Concat struct {
+	branches
+	Dim int64
+}
+
+// NewConcat creates a Concat combinator, e.g. for Inception-style multi-branch blocks.
+func NewConcat(dim int64, branches ...ts.ModuleT) *Concat {
+	return &Concat{branches: newBranches(branches), Dim: dim}
+}
+
+// ForwardT implements ts.ModuleT interface.
+func (c *Concat) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
+	xs = preForward(c, xs)
+	outs := c.forwardT(xs, train)
+	out := ts.MustCat(outs, c.Dim)
+	for _, o := range outs {
+		o.MustDrop()
+	}
+
+	return runForwardHooks(c, xs, out)
+}
+
+// Forward implements ts.Module interface. Branches run in evaluation mode.
+func (c *Concat) Forward(xs *ts.Tensor) *ts.Tensor {
+	return c.ForwardT(xs, false)
+}
+
+func (c *Concat) Name() string { return "Concat" }
+
+// Residual:
+// =========
+
+// Residual adds output of a module to its input, i.e. out = body(xs) + shortcut(xs)
+// where shortcut is identity unless specified.
+type Residual struct {
+	body     ts.ModuleT
+	shortcut ts.ModuleT
+}
+
+// NewResidual creates a Residual combinator. Optional shortcut, e.g. a 1x1 convolution,
+// projects input to the shape of body output.
+func NewResidual(body ts.ModuleT, shortcutOpt ...ts.ModuleT) *Residual {
+	var shortcut ts.ModuleT
+	if len(shortcutOpt) > 0 {
+		shortcut = shortcutOpt[0]
+	}
+
+	return &Residual{body: body, shortcut: shortcut}
+}
+
+// ForwardT implements ts.ModuleT interface.
+func (r *Residual) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
+	xs = preForward(r, xs)
+	out := forwardLayerT(r.body, xs, train)
+	if r.shortcut == nil {
+		out = out.MustAdd(xs, true)
+	} else {
+		short := forwardLayerT(r.shortcut, xs, train)
+		out = out.MustAdd(short, true)
+		short.MustDrop()
+	}
+
+	return runForwardHooks(r, xs, out)
+}
+
+// Forward implements ts.Module interface. Body runs in evaluation mode.
+func (r *Residual) Forward(xs *ts.Tensor) *ts.Tensor {
+	return r.ForwardT(xs, false)
+}
+
+func (r *Residual) Name() string { return "Residual" }
+func (r *Residual) Children() []NamedModule {
+	children := []NamedModule{{Name: "body", Module: r.body}}
+	if r.shortcut != nil {
+		children = append(children, NamedModule{Name: "shortcut", Module: r.shortcut})
+	}
+
+	return children
+}
+func (r *Residual) Parameters() []ts.NamedTensor { return nil }
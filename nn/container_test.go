@@ -0,0 +1,128 @@
+package nn_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch/nn"
+	"github.com/nullbull/gotch/ts"
+)
+
+func addFn(v float64) nn.Func {
+	return nn.NewFunc(func(xs *ts.Tensor) *ts.Tensor {
+		return xs.MustAddScalar(ts.FloatScalar(v), false)
+	})
+}
+
+func mulFn(v float64) nn.Func {
+	return nn.NewFunc(func(xs *ts.Tensor) *ts.Tensor {
+		return xs.MustMulScalar(ts.FloatScalar(v), false)
+	})
+}
+
+func assertValues(t *testing.T, name string, got *ts.Tensor, want []float64) {
+	if !reflect.DeepEqual(want, got.Float64Values()) {
+		t.Errorf("%s - Expected: %v, got %v\n", name, want, got.Float64Values())
+	}
+}
+
+func TestSequentialSurgery(t *testing.T) {
+	xs := ts.MustOfSlice([]float64{1, 2})
+
+	seq := nn.SeqT()
+	seq.AddNamed("add1", addFn(1))
+	seq.AddNamed("mul2", mulFn(2))
+	seq.AddNamed("classifier", addFn(10))
+	assertValues(t, "Initial", seq.ForwardT(xs, false), []float64{14, 16})
+
+	// Duplicate and index-like names are rejected.
+	for _, name := range []string{"mul2", "2", "0"} {
+		if err := seq.AddNamed(name, addFn(1)); err == nil {
+			t.Errorf("Expected AddNamed(%q) error\n", name)
+		}
+		if err := seq.InsertNamed(0, name, addFn(1)); err == nil {
+			t.Errorf("Expected InsertNamed(%q) error\n", name)
+		}
+	}
+	if got := seq.Len(); got != 3 {
+		t.Errorf("Expected 3 layers, got %v\n", got)
+	}
+
+	if err := seq.ReplaceByName("classifier", addFn(100)); err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, "ReplaceByName", seq.ForwardT(xs, false), []float64{104, 106})
+
+	if err := seq.InsertBefore("mul2", mulFn(3)); err != nil {
+		t.Fatal(err)
+	}
+	if got := seq.Index("mul2"); got != 2 {
+		t.Errorf("Expected index of mul2: 2, got %v\n", got)
+	}
+	// (x + 1)*3*2 + 100
+	assertValues(t, "InsertBefore", seq.ForwardT(xs, false), []float64{112, 118})
+
+	head, err := seq.SliceByName("add1", "classifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, "SliceByName", head.ForwardT(xs, false), []float64{12, 18})
+
+	if _, err := seq.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := seq.RemoveByName("classifier"); err != nil {
+		t.Fatal(err)
+	}
+	if seq.Len() != 2 {
+		t.Fatalf("Expected 2 layers, got %v\n", seq.Len())
+	}
+	assertValues(t, "Remove", seq.ForwardT(xs, false), []float64{4, 6})
+
+	if err := seq.Insert(5, addFn(1)); err == nil {
+		t.Errorf("Expected error inserting out of range")
+	}
+	if _, err := seq.Slice(0, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModuleDict(t *testing.T) {
+	d := nn.NewModuleDict()
+	d.Set("b", addFn(1))
+	d.Set("a", addFn(2))
+	d.Set("b", addFn(3))
+	d.Delete("missing")
+
+	if want, got := []string{"b", "a"}, d.Keys(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected keys: %v, got %v\n", want, got)
+	}
+	xs := ts.MustOfSlice([]float64{0})
+	assertValues(t, "ModuleDict", d.MustGet("b").(nn.Func).Forward(xs), []float64{3})
+
+	l := nn.NewModuleList(addFn(1), addFn(2))
+	l.Append(addFn(3))
+	if l.Len() != 3 || len(nn.ModuleChildren(l)) != 3 {
+		t.Errorf("Expected 3 modules in ModuleList, got %v\n", l.Len())
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	xs := ts.MustOfSlice([]float64{1, 2}).MustView([]int64{1, 2}, true)
+
+	assertValues(t, "Residual", nn.NewResidual(mulFn(2)).ForwardT(xs, false), []float64{3, 6})
+	assertValues(t, "Residual(shortcut)", nn.NewResidual(mulFn(2), addFn(1)).ForwardT(xs, false), []float64{4, 7})
+
+	concat := nn.NewConcat(1, addFn(1), mulFn(3))
+	out := concat.ForwardT(xs, false)
+	if want := []int64{1, 4}; !reflect.DeepEqual(want, out.MustSize()) {
+		t.Errorf("Expected Concat shape: %v, got %v\n", want, out.MustSize())
+	}
+	assertValues(t, "Concat", out, []float64{2, 3, 3, 6})
+
+	assertValues(t, "Parallel(sum)", nn.NewParallel(nil, addFn(1), mulFn(3)).ForwardT(xs, false), []float64{5, 9})
+	maxOf := nn.NewParallel(func(outs []*ts.Tensor) *ts.Tensor {
+		return outs[0].MustMaximum(outs[1], false)
+	}, addFn(1), mulFn(3))
+	assertValues(t, "Parallel(max)", maxOf.ForwardT(xs, false), []float64{3, 6})
+}
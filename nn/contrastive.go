@@ -0,0 +1,225 @@
+package nn
+
+// Contrastive and metric-learning losses.
+
+import (
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+// l2Normalize normalizes input along dimension `dim` to unit L2 norm.
+func l2Normalize(x *ts.Tensor, dim int64) *ts.Tensor {
+	norm := x.MustSquare(false).MustSumDimIntlist([]int64{dim}, true, x.DType(), true).MustSqrt(true).MustClampMin(ts.FloatScalar(1e-12), true)
+	out := x.MustDiv(norm, false)
+	norm.MustDrop()
+
+	return out
+}
+
+// similarity calculates temperature-scaled cosine similarity matrix between rows of x1 and x2.
+func similarity(x1, x2 *ts.Tensor, temperature float64) *ts.Tensor {
+	z1 := l2Normalize(x1, 1)
+	z2 := l2Normalize(x2, 1)
+	z2T := z2.MustT(true)
+	sim := z1.MustMatmul(z2T, true).MustDivScalar(ts.FloatScalar(temperature), true)
+	z2T.MustDrop()
+
+	return sim
+}
+
+// InfoNCELoss calculates InfoNCE loss (https://arxiv.org/abs/1807.03748) with in-batch negatives.
+//
+// - query: embeddings of shape [N, D]
+// - key: embeddings of shape [N, D]. key[i] is the positive of query[i], other keys in the batch
+// are negatives.
+// Options: WithLossFnTemperature (default=0.07), WithLossFnReduction.
+func InfoNCELoss(query, key *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	n := query.MustSize()[0]
+	logits := similarity(query, key, options.Temperature)
+	target := ts.MustArange(ts.IntScalar(n), gotch.Int64, query.MustDevice())
+	loss := CrossEntropyLoss(logits, target, WithLossFnReduction(options.Reduction))
+	logits.MustDrop()
+	target.MustDrop()
+
+	return loss
+}
+
+// NTXentLoss calculates normalized temperature-scaled cross entropy loss as in SimCLR
+// (https://arxiv.org/abs/2002.05709).
+//
+// - z1, z2: embeddings of two augmented views of shape [N, D]. (z1[i], z2[i]) are positive pairs,
+// all other 2(N-1) embeddings in the batch are negatives.
+// Options: WithLossFnTemperature (default=0.5), WithLossFnReduction.
+func NTXentLoss(z1, z2 *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Temperature = 0.5
+	for _, o := range opts {
+		o(options)
+	}
+
+	n := z1.MustSize()[0]
+	device := z1.MustDevice()
+	z := ts.MustCat([]*ts.Tensor{z1, z2}, 0)
+	sim := similarity(z, z, options.Temperature)
+	z.MustDrop()
+
+	// exclude self-similarity
+	eye := ts.MustEye(2*n, gotch.Bool, device)
+	logits := sim.MustMaskedFill(eye, ts.FloatScalar(-1e9), true)
+	eye.MustDrop()
+
+	// positive of i is i+N and vice versa
+	idx := ts.MustArange(ts.IntScalar(n), gotch.Int64, device)
+	shifted := idx.MustAddScalar(ts.IntScalar(n), false)
+	target := ts.MustCat([]*ts.Tensor{shifted, idx}, 0)
+	idx.MustDrop()
+	shifted.MustDrop()
+
+	loss := CrossEntropyLoss(logits, target, WithLossFnReduction(options.Reduction))
+	logits.MustDrop()
+	target.MustDrop()
+
+	return loss
+}
+
+// SupConLoss calculates supervised contrastive loss (https://arxiv.org/abs/2004.11362).
+//
+// - features: embeddings of shape [N, D]. For multiple views, concatenate views along
+// batch dimension and repeat labels accordingly.
+// - labels: class labels of shape [N]. Samples with the same label are positives.
+// Anchors without any positive are excluded.
+// Options: WithLossFnTemperature (default=0.07), WithLossFnReduction.
+func SupConLoss(features, labels *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	n := features.MustSize()[0]
+	device := features.MustDevice()
+	dtype := features.DType()
+
+	sim := similarity(features, features, options.Temperature)
+	// for numerical stability
+	rowMax, argMax := sim.MustMaxDim(1, true, false)
+	argMax.MustDrop()
+	rowMax = rowMax.MustDetach(true)
+	logits := sim.MustSub(rowMax, true)
+	rowMax.MustDrop()
+
+	eye := ts.MustEye(n, dtype, device)
+	logitsMask := eye.MustRsubScalar(ts.FloatScalar(1.0), true)
+
+	expLogits := logits.MustExp(false).MustMul(logitsMask, true)
+	logDen := expLogits.MustSumDimIntlist([]int64{1}, true, dtype, true).MustLog(true)
+	logProb := logits.MustSub(logDen, true)
+	logDen.MustDrop()
+
+	l := labels.MustView([]int64{-1, 1}, false)
+	lT := labels.MustView([]int64{1, -1}, false)
+	posMask := l.MustEqTensor(lT, true).MustTotype(dtype, true).MustMul(logitsMask, true)
+	lT.MustDrop()
+	logitsMask.MustDrop()
+
+	posCount := posMask.MustSumDimIntlist([]int64{1}, false, dtype, false)
+	posLogProb := posMask.MustMul(logProb, true).MustSumDimIntlist([]int64{1}, false, dtype, true)
+	logProb.MustDrop()
+	clamped := posCount.MustClampMin(ts.FloatScalar(1.0), false)
+	loss := posLogProb.MustDiv(clamped, true).MustNeg(true)
+	clamped.MustDrop()
+
+	valid := posCount.MustGt(ts.FloatScalar(0.0), true).MustTotype(dtype, true)
+
+	out := reduceMasked(loss, valid, options.Reduction)
+	valid.MustDrop()
+
+	return out
+}
+
+// batchHard finds the hardest positive and negative for each anchor given pairwise distances.
+func batchHard(dist, labels *ts.Tensor) (posIdx, negIdx, posDist, negDist, valid *ts.Tensor) {
+	n := dist.MustSize()[0]
+	dtype := dist.DType()
+	device := dist.MustDevice()
+
+	l := labels.MustView([]int64{-1, 1}, false)
+	lT := labels.MustView([]int64{1, -1}, false)
+	same := l.MustEqTensor(lT, true)
+	lT.MustDrop()
+	notEye := ts.MustEye(n, gotch.Bool, device).MustLogicalNot(true)
+	posMask := same.MustLogicalAnd(notEye, false)
+	negMask := same.MustLogicalNot(true)
+	notEye.MustDrop()
+
+	// hardest positive: largest distance among positives. Non-positives are set to -1.
+	notPos := posMask.MustLogicalNot(false)
+	posCand := dist.MustMaskedFill(notPos, ts.FloatScalar(-1.0), false)
+	notPos.MustDrop()
+	posDist, posIdx = posCand.MustTopk(1, 1, true, true, true)
+
+	// hardest negative: smallest distance among negatives. Non-negatives are set to +inf.
+	maxDist := dist.MustMax(false).Float64Values(true)[0]
+	notNeg := negMask.MustLogicalNot(false)
+	negCand := dist.MustMaskedFill(notNeg, ts.FloatScalar(maxDist+1.0), false)
+	notNeg.MustDrop()
+	negDist, negIdx = negCand.MustTopk(1, 1, false, true, true)
+
+	hasPos := posMask.MustSumDimIntlist([]int64{1}, false, gotch.Int64, true).MustGt(ts.IntScalar(0), true)
+	hasNeg := negMask.MustSumDimIntlist([]int64{1}, false, gotch.Int64, true).MustGt(ts.IntScalar(0), true)
+	valid = hasPos.MustLogicalAnd(hasNeg, true).MustTotype(dtype, true)
+	hasNeg.MustDrop()
+
+	return posIdx.MustSqueezeDim(1, true), negIdx.MustSqueezeDim(1, true), posDist.MustSqueezeDim(1, true), negDist.MustSqueezeDim(1, true), valid
+}
+
+// BatchHardMine mines the hardest positive (farthest sample with the same label) and the hardest
+// negative (closest sample with a different label) for each anchor in the batch based on
+// Euclidean distance (https://arxiv.org/abs/1703.07737).
+//
+// It returns index tensors of shape [N] for positives and negatives (anchors are `0..N-1`) and
+// a boolean mask of shape [N] marking anchors having at least one positive and one negative.
+func BatchHardMine(embeddings, labels *ts.Tensor) (positives, negatives, valid *ts.Tensor) {
+	var posDist, negDist *ts.Tensor
+	ts.NoGrad(func() {
+		dist := ts.MustCdist(embeddings, embeddings, 2.0, nil)
+		positives, negatives, posDist, negDist, valid = batchHard(dist, labels)
+		dist.MustDrop()
+	})
+	posDist.MustDrop()
+	negDist.MustDrop()
+
+	return positives, negatives, valid.MustTotype(gotch.Bool, true)
+}
+
+// BatchHardTripletLoss calculates batch-hard triplet loss: max(0, d(a, p_hardest) - d(a, n_hardest) + margin)
+// averaged over anchors having at least one positive and one negative.
+//
+// - embeddings: embeddings of shape [N, D]
+// - labels: class labels of shape [N]
+// Options: WithLossFnMargin (default=1.0), WithLossFnReduction.
+func BatchHardTripletLoss(embeddings, labels *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Margin = 1.0
+	for _, o := range opts {
+		o(options)
+	}
+
+	dist := ts.MustCdist(embeddings, embeddings, 2.0, nil)
+	posIdx, negIdx, posDist, negDist, valid := batchHard(dist, labels)
+	posIdx.MustDrop()
+	negIdx.MustDrop()
+	dist.MustDrop()
+
+	loss := posDist.MustSub(negDist, true).MustAddScalar(ts.FloatScalar(options.Margin), true).MustRelu(true)
+	negDist.MustDrop()
+
+	out := reduceMasked(loss, valid, options.Reduction)
+	valid.MustDrop()
+
+	return out
+}
@@ -0,0 +1,110 @@
+package nn_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/nn"
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestContrastiveLosses(t *testing.T) {
+	query := ts.MustOfSlice([]float64{1, 0, 0, 1}).MustView([]int64{2, 2}, true)
+	key := ts.MustOfSlice([]float64{1, 0, 1, 1}).MustView([]int64{2, 2}, true)
+	assertLoss(t, "InfoNCELoss", nn.InfoNCELoss(query, key, nn.WithLossFnTemperature(1.0)), 0.4791096451834477)
+
+	z := ts.MustOfSlice([]float64{1, 0, 0, 1}).MustView([]int64{2, 2}, true)
+	assertLoss(t, "NTXentLoss", nn.NTXentLoss(z, z, nn.WithLossFnTemperature(1.0)), math.Log(math.E+2)-1)
+
+	// last sample has no positive and is excluded.
+	features := ts.MustOfSlice([]float64{1, 0, 1, 0, 0, 1}).MustView([]int64{3, 2}, true)
+	labels := ts.MustOfSlice([]int64{0, 0, 1})
+	assertLoss(t, "SupConLoss", nn.SupConLoss(features, labels, nn.WithLossFnTemperature(1.0)), math.Log(1+math.E)-1)
+}
+
+func TestBatchHard(t *testing.T) {
+	embeddings := ts.MustOfSlice([]float64{0, 1, 3, 4}).MustView([]int64{4, 1}, true)
+	labels := ts.MustOfSlice([]int64{0, 0, 1, 1})
+
+	positives, negatives, valid := nn.BatchHardMine(embeddings, labels)
+	wantPos := []int64{1, 0, 3, 2}
+	wantNeg := []int64{2, 2, 1, 1}
+	gotPos := positives.Int64Values()
+	gotNeg := negatives.Int64Values()
+	for i := range wantPos {
+		if gotPos[i] != wantPos[i] || gotNeg[i] != wantNeg[i] {
+			t.Errorf("Expected positives %v and negatives %v\n", wantPos, wantNeg)
+			t.Errorf("Got positives %v and negatives %v\n", gotPos, gotNeg)
+			break
+		}
+	}
+	for i, v := range valid.MustTotype(gotch.Int64, false).Int64Values() {
+		if v != 1 {
+			t.Errorf("Expected anchor %d valid\n", i)
+		}
+	}
+
+	assertLoss(t, "BatchHardTripletLoss", nn.BatchHardTripletLoss(embeddings, labels, nn.WithLossFnMargin(2.0)), 0.5)
+}
+
+func TestMarginHeads(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	path := vs.Root()
+	xs := ts.MustOfSlice([]float32{0.5, -1, 2, 1, 1, 0}).MustView([]int64{2, 3}, true)
+	labels := ts.MustOfSlice([]int64{0, 2})
+	nclasses := int64(4)
+
+	arcCfg := nn.DefaultArcFaceConfig()
+	arcCfg.Scale = 1.0
+	arcCfg.Margin = 0.1
+	cosCfg := nn.DefaultCosFaceConfig()
+	cosCfg.Scale = 1.0
+	sphereCfg := nn.DefaultSphereFaceConfig()
+	sphereCfg.Scale = 1.0
+
+	tests := []struct {
+		name string
+		head interface {
+			Forward(xs *ts.Tensor) *ts.Tensor
+			ForwardWithLabels(xs, labels *ts.Tensor) *ts.Tensor
+		}
+		phiFn func(cos float64) float64
+	}{
+		{"ArcFace", nn.NewArcFace(path.Sub("arc"), 3, nclasses, arcCfg), func(cos float64) float64 {
+			if cos > math.Cos(math.Pi-0.1) {
+				return math.Cos(math.Acos(cos) + 0.1)
+			}
+			return cos - math.Sin(math.Pi-0.1)*0.1
+		}},
+		{"CosFace", nn.NewCosFace(path.Sub("cos"), 3, nclasses, cosCfg), func(cos float64) float64 {
+			return cos - 0.35
+		}},
+		{"SphereFace", nn.NewSphereFace(path.Sub("sphere"), 3, nclasses, sphereCfg), func(cos float64) float64 {
+			theta := math.Acos(cos)
+			k := math.Floor(4 * theta / math.Pi)
+			return math.Pow(-1, k)*math.Cos(4*theta) - 2*k
+		}},
+	}
+
+	target := labels.Int64Values()
+	for _, tt := range tests {
+		cos := tt.head.Forward(xs).Float64Values()
+		got := tt.head.ForwardWithLabels(xs, labels).Float64Values()
+		if len(got) != 2*int(nclasses) {
+			t.Fatalf("%s - Expected %v logits, got %v\n", tt.name, 2*nclasses, len(got))
+		}
+		for i := 0; i < 2; i++ {
+			for j := 0; j < int(nclasses); j++ {
+				idx := i*int(nclasses) + j
+				want := cos[idx]
+				if int64(j) == target[i] {
+					want = tt.phiFn(cos[idx])
+				}
+				if math.Abs(got[idx]-want) > 1e-4 {
+					t.Errorf("%s - Expected logit[%d][%d]: %v, got %v\n", tt.name, i, j, want, got[idx])
+				}
+			}
+		}
+	}
+}
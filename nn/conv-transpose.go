@@ -3,6 +3,7 @@ package nn
 // A two dimension transposed convolution layer.
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/nullbull/gotch/ts"
@@ -55,6 +56,215 @@ func DefaultConvTranspose1DConfig() *ConvTranspose1DConfig {
 	}
 }
 
+// DefaultConvTranspose2DConfig creates a default 2D ConvTranspose config.
+func DefaultConvTranspose2DConfig() *ConvTranspose2DConfig {
+	return &ConvTranspose2DConfig{
+		Stride:        []int64{1, 1},
+		Padding:       []int64{0, 0},
+		OutputPadding: []int64{0, 0},
+		Dilation:      []int64{1, 1},
+		Groups:        1,
+		Bias:          true,
+		WsInit:        NewKaimingUniformInit(),
+		BsInit:        NewConstInit(float64(0.0)),
+	}
+}
+
+// DefaultConvTranspose3DConfig creates a default 3D ConvTranspose config.
+func DefaultConvTranspose3DConfig() *ConvTranspose3DConfig {
+	return &ConvTranspose3DConfig{
+		Stride:        []int64{1, 1, 1},
+		Padding:       []int64{0, 0, 0},
+		OutputPadding: []int64{0, 0, 0},
+		Dilation:      []int64{1, 1, 1},
+		Groups:        1,
+		Bias:          true,
+		WsInit:        NewKaimingUniformInit(),
+		BsInit:        NewConstInit(float64(0.0)),
+	}
+}
+
+// ConvTranspose1DConfigOpt is option type for ConvTranspose1DConfig.
+type ConvTranspose1DConfigOpt func(*ConvTranspose1DConfig)
+
+// WithStrideTranspose1D adds stride option.
+func WithStrideTranspose1D(val int64) ConvTranspose1DConfigOpt {
+	return func(cfg *ConvTranspose1DConfig) {
+		cfg.Stride = []int64{val}
+	}
+}
+
+// WithPaddingTranspose1D adds padding option.
+func WithPaddingTranspose1D(val int64) ConvTranspose1DConfigOpt {
+	return func(cfg *ConvTranspose1DConfig) {
+		cfg.Padding = []int64{val}
+	}
+}
+
+// WithOutputPaddingTranspose1D adds output padding option. It must be smaller than
+// either stride or dilation.
+func WithOutputPaddingTranspose1D(val int64) ConvTranspose1DConfigOpt {
+	return func(cfg *ConvTranspose1DConfig) {
+		cfg.OutputPadding = []int64{val}
+	}
+}
+
+// WithDilationTranspose1D adds dilation option.
+func WithDilationTranspose1D(val int64) ConvTranspose1DConfigOpt {
+	return func(cfg *ConvTranspose1DConfig) {
+		cfg.Dilation = []int64{val}
+	}
+}
+
+// WithGroupTranspose1D adds group option.
+func WithGroupTranspose1D(val int64) ConvTranspose1DConfigOpt {
+	return func(cfg *ConvTranspose1DConfig) {
+		cfg.Groups = val
+	}
+}
+
+// WithBiasTranspose1D adds bias option.
+func WithBiasTranspose1D(val bool) ConvTranspose1DConfigOpt {
+	return func(cfg *ConvTranspose1DConfig) {
+		cfg.Bias = val
+	}
+}
+
+// NewConvTranspose1DConfig creates ConvTranspose1DConfig.
+func NewConvTranspose1DConfig(opts ...ConvTranspose1DConfigOpt) *ConvTranspose1DConfig {
+	cfg := DefaultConvTranspose1DConfig()
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+// ConvTranspose2DConfigOpt is option type for ConvTranspose2DConfig.
+type ConvTranspose2DConfigOpt func(*ConvTranspose2DConfig)
+
+// WithStrideTranspose2D adds stride option.
+func WithStrideTranspose2D(val int64) ConvTranspose2DConfigOpt {
+	return func(cfg *ConvTranspose2DConfig) {
+		cfg.Stride = []int64{val, val}
+	}
+}
+
+// WithPaddingTranspose2D adds padding option.
+func WithPaddingTranspose2D(val int64) ConvTranspose2DConfigOpt {
+	return func(cfg *ConvTranspose2DConfig) {
+		cfg.Padding = []int64{val, val}
+	}
+}
+
+// WithOutputPaddingTranspose2D adds output padding option. It must be smaller than
+// either stride or dilation.
+func WithOutputPaddingTranspose2D(val int64) ConvTranspose2DConfigOpt {
+	return func(cfg *ConvTranspose2DConfig) {
+		cfg.OutputPadding = []int64{val, val}
+	}
+}
+
+// WithDilationTranspose2D adds dilation option.
+func WithDilationTranspose2D(val int64) ConvTranspose2DConfigOpt {
+	return func(cfg *ConvTranspose2DConfig) {
+		cfg.Dilation = []int64{val, val}
+	}
+}
+
+// WithGroupTranspose2D adds group option.
+func WithGroupTranspose2D(val int64) ConvTranspose2DConfigOpt {
+	return func(cfg *ConvTranspose2DConfig) {
+		cfg.Groups = val
+	}
+}
+
+// WithBiasTranspose2D adds bias option.
+func WithBiasTranspose2D(val bool) ConvTranspose2DConfigOpt {
+	return func(cfg *ConvTranspose2DConfig) {
+		cfg.Bias = val
+	}
+}
+
+// NewConvTranspose2DConfig creates ConvTranspose2DConfig.
+func NewConvTranspose2DConfig(opts ...ConvTranspose2DConfigOpt) *ConvTranspose2DConfig {
+	cfg := DefaultConvTranspose2DConfig()
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+// ConvTranspose3DConfigOpt is option type for ConvTranspose3DConfig.
+type ConvTranspose3DConfigOpt func(*ConvTranspose3DConfig)
+
+// WithStrideTranspose3D adds stride option.
+func WithStrideTranspose3D(val int64) ConvTranspose3DConfigOpt {
+	return func(cfg *ConvTranspose3DConfig) {
+		cfg.Stride = []int64{val, val, val}
+	}
+}
+
+// WithPaddingTranspose3D adds padding option.
+func WithPaddingTranspose3D(val int64) ConvTranspose3DConfigOpt {
+	return func(cfg *ConvTranspose3DConfig) {
+		cfg.Padding = []int64{val, val, val}
+	}
+}
+
+// WithOutputPaddingTranspose3D adds output padding option. It must be smaller than
+// either stride or dilation.
+func WithOutputPaddingTranspose3D(val int64) ConvTranspose3DConfigOpt {
+	return func(cfg *ConvTranspose3DConfig) {
+		cfg.OutputPadding = []int64{val, val, val}
+	}
+}
+
+// WithDilationTranspose3D adds dilation option.
+func WithDilationTranspose3D(val int64) ConvTranspose3DConfigOpt {
+	return func(cfg *ConvTranspose3DConfig) {
+		cfg.Dilation = []int64{val, val, val}
+	}
+}
+
+// WithGroupTranspose3D adds group option.
+func WithGroupTranspose3D(val int64) ConvTranspose3DConfigOpt {
+	return func(cfg *ConvTranspose3DConfig) {
+		cfg.Groups = val
+	}
+}
+
+// WithBiasTranspose3D adds bias option.
+func WithBiasTranspose3D(val bool) ConvTranspose3DConfigOpt {
+	return func(cfg *ConvTranspose3DConfig) {
+		cfg.Bias = val
+	}
+}
+
+// NewConvTranspose3DConfig creates ConvTranspose3DConfig.
+func NewConvTranspose3DConfig(opts ...ConvTranspose3DConfigOpt) *ConvTranspose3DConfig {
+	cfg := DefaultConvTranspose3DConfig()
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+// checkOutputPadding validates output padding as in Pytorch: it must be smaller than
+// either stride or dilation of the same dimension.
+func checkOutputPadding(outputPadding, stride, dilation []int64) error {
+	for i, p := range outputPadding {
+		if p >= stride[i] && p >= dilation[i] {
+			err := fmt.Errorf("output padding must be smaller than either stride or dilation. Got output padding %v, stride %v and dilation %v", outputPadding, stride, dilation)
+			return err
+		}
+	}
+
+	return nil
+}
+
 type ConvTranspose1D struct {
 	Ws     *ts.Tensor
 	Bs     *ts.Tensor // optional
@@ -66,12 +276,17 @@ func NewConvTranspose1D(vs *Path, inDim, outDim int64, ksizes []int64, cfg *Conv
 		log.Fatalf("NewConvTranspose1D method call: Kernel size should be 1. Got %v\n", len(ksizes))
 	}
 
+	if err := checkOutputPadding(cfg.OutputPadding, cfg.Stride, cfg.Dilation); err != nil {
+		log.Fatalf("NewConvTranspose1D method call: %v\n", err)
+	}
+
 	var (
 		ws *ts.Tensor
 		bs *ts.Tensor = ts.NewTensor()
 	)
 
-	weightSize := []int64{outDim, int64(inDim / cfg.Groups)}
+	// NOTE. weight of transposed convolution has shape [inDim, outDim/groups, kernel...]
+	weightSize := []int64{inDim, int64(outDim / cfg.Groups)}
 	weightSize = append(weightSize, ksizes...)
 	ws = vs.MustNewVar("weight", weightSize, cfg.WsInit)
 
@@ -98,6 +313,10 @@ func NewConvTranspose2D(vs *Path, inDim, outDim int64, ksizes []int64, cfg *Conv
 		log.Fatalf("NewConvTranspose2D method call: Kernel size should be 2. Got %v\n", len(ksizes))
 	}
 
+	if err := checkOutputPadding(cfg.OutputPadding, cfg.Stride, cfg.Dilation); err != nil {
+		log.Fatalf("NewConvTranspose2D method call: %v\n", err)
+	}
+
 	var (
 		ws *ts.Tensor
 		bs *ts.Tensor = ts.NewTensor()
@@ -106,7 +325,8 @@ func NewConvTranspose2D(vs *Path, inDim, outDim int64, ksizes []int64, cfg *Conv
 	if cfg.Bias {
 		bs = vs.MustNewVar("bias", []int64{outDim}, cfg.BsInit)
 	}
-	weightSize := []int64{outDim, int64(inDim / cfg.Groups)}
+	// NOTE. weight of transposed convolution has shape [inDim, outDim/groups, kernel...]
+	weightSize := []int64{inDim, int64(outDim / cfg.Groups)}
 	weightSize = append(weightSize, ksizes...)
 	ws = vs.MustNewVar("weight", weightSize, cfg.WsInit)
 
@@ -128,6 +348,10 @@ func NewConvTranspose3D(vs *Path, inDim, outDim int64, ksizes []int64, cfg *Conv
 		log.Fatalf("NewConvTranspose3D method call: Kernel size should be 3. Got %v\n", len(ksizes))
 	}
 
+	if err := checkOutputPadding(cfg.OutputPadding, cfg.Stride, cfg.Dilation); err != nil {
+		log.Fatalf("NewConvTranspose3D method call: %v\n", err)
+	}
+
 	var (
 		ws *ts.Tensor
 		bs *ts.Tensor = ts.NewTensor()
@@ -136,7 +360,8 @@ func NewConvTranspose3D(vs *Path, inDim, outDim int64, ksizes []int64, cfg *Conv
 	if cfg.Bias {
 		bs = vs.MustNewVar("bias", []int64{outDim}, cfg.BsInit)
 	}
-	weightSize := []int64{outDim, int64(inDim / cfg.Groups)}
+	// NOTE. weight of transposed convolution has shape [inDim, outDim/groups, kernel...]
+	weightSize := []int64{inDim, int64(outDim / cfg.Groups)}
 	weightSize = append(weightSize, ksizes...)
 	ws = vs.MustNewVar("weight", weightSize, cfg.WsInit)
 
@@ -147,16 +372,22 @@ func NewConvTranspose3D(vs *Path, inDim, outDim int64, ksizes []int64, cfg *Conv
 	}
 }
 
-// Implement Module for Conv1D, Conv2D, Conv3D:
-// ============================================
+// Implement Module for ConvTranspose1D, ConvTranspose2D, ConvTranspose3D:
+// ======================================================================
 
 func (c *ConvTranspose1D) Forward(xs *ts.Tensor) *ts.Tensor {
-	return ts.MustConvTranspose1d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.OutputPadding, c.Config.Groups, c.Config.Dilation)
+	xs = preForward(c, xs)
+	out := ts.MustConvTranspose1d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.OutputPadding, c.Config.Groups, c.Config.Dilation)
+	return postForward(c, xs, out)
 }
 
 func (c *ConvTranspose2D) Forward(xs *ts.Tensor) *ts.Tensor {
-	return ts.MustConvTranspose2d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.OutputPadding, c.Config.Groups, c.Config.Dilation)
+	xs = preForward(c, xs)
+	out := ts.MustConvTranspose2d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.OutputPadding, c.Config.Groups, c.Config.Dilation)
+	return postForward(c, xs, out)
 }
 func (c *ConvTranspose3D) Forward(xs *ts.Tensor) *ts.Tensor {
-	return ts.MustConvTranspose3d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.OutputPadding, c.Config.Groups, c.Config.Dilation)
+	xs = preForward(c, xs)
+	out := ts.MustConvTranspose3d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.OutputPadding, c.Config.Groups, c.Config.Dilation)
+	return postForward(c, xs, out)
 }
@@ -10,18 +10,35 @@ import (
 	"github.com/nullbull/gotch/ts"
 )
 
+// Padding types.
+const (
+	PaddingSame  = "same"  // pad input so that output has the same size as input (stride must be 1)
+	PaddingValid = "valid" // no padding
+)
+
+// Padding modes.
+const (
+	PaddingModeZeros     = "zeros"
+	PaddingModeReflect   = "reflect"
+	PaddingModeReplicate = "replicate"
+	PaddingModeCircular  = "circular"
+)
+
 // Conv1DConfig:
 // ============
 
 // Conv1DConfig is configuration struct for convolution 1D.
 type Conv1DConfig struct {
 	Stride   []int64
-	Padding  []int64
+	Padding  []int64 // per dimension padding or per side (begin, end) padding of each dimension
 	Dilation []int64
 	Groups   int64
 	Bias     bool
 	WsInit   Init
 	BsInit   Init
+
+	PaddingType string // optional "same" or "valid". If set, `Padding` is ignored.
+	PaddingMode string // "zeros" (default), "reflect", "replicate" or "circular"
 }
 
 // Conv1DConfigOpt is option for Conv1DConfig.
@@ -41,6 +58,31 @@ func WithPadding1D(val int64) Conv1DConfigOpt {
 	}
 }
 
+// WithPaddingSides1D adds asymmetric padding 1D option with 2 values
+// in order (left, right).
+func WithPaddingSides1D(vals ...int64) Conv1DConfigOpt {
+	if len(vals) != 2 {
+		panic(fmt.Sprintf("WithPaddingSides1D() failed: expected 2 values. Got %v", len(vals)))
+	}
+	return func(cfg *Conv1DConfig) {
+		cfg.Padding = vals
+	}
+}
+
+// WithPaddingType1D adds padding type 1D option. It can be either "same" or "valid".
+func WithPaddingType1D(val string) Conv1DConfigOpt {
+	return func(cfg *Conv1DConfig) {
+		cfg.PaddingType = val
+	}
+}
+
+// WithPaddingMode1D adds padding mode 1D option. It can be "zeros", "reflect", "replicate" or "circular".
+func WithPaddingMode1D(val string) Conv1DConfigOpt {
+	return func(cfg *Conv1DConfig) {
+		cfg.PaddingMode = val
+	}
+}
+
 // WithDilation1D adds dilation 1D option.
 func WithDilation1D(val int64) Conv1DConfigOpt {
 	return func(cfg *Conv1DConfig) {
@@ -86,6 +128,8 @@ func DefaultConv1DConfig() *Conv1DConfig {
 		Bias:     true,
 		WsInit:   NewKaimingUniformInit(WithKaimingNegativeSlope(negSlope)),
 		BsInit:   nil,
+
+		PaddingMode: PaddingModeZeros,
 	}
 }
 
@@ -105,12 +149,15 @@ func NewConv1DConfig(opts ...Conv1DConfigOpt) *Conv1DConfig {
 // Conv2DConfig is configuration for convolution 2D.
 type Conv2DConfig struct {
 	Stride   []int64
-	Padding  []int64
+	Padding  []int64 // per dimension padding or per side (begin, end) padding of each dimension
 	Dilation []int64
 	Groups   int64
 	Bias     bool
 	WsInit   Init
 	BsInit   Init
+
+	PaddingType string // optional "same" or "valid". If set, `Padding` is ignored.
+	PaddingMode string // "zeros" (default), "reflect", "replicate" or "circular"
 }
 
 // Conv2DConfigOpt is option type for Conv2DConfig.
@@ -130,6 +177,31 @@ func WithPadding2D(val int64) Conv2DConfigOpt {
 	}
 }
 
+// WithPaddingSides2D adds asymmetric padding 2D option with 4 values
+// in order (top, bottom), (left, right).
+func WithPaddingSides2D(vals ...int64) Conv2DConfigOpt {
+	if len(vals) != 4 {
+		panic(fmt.Sprintf("WithPaddingSides2D() failed: expected 4 values. Got %v", len(vals)))
+	}
+	return func(cfg *Conv2DConfig) {
+		cfg.Padding = vals
+	}
+}
+
+// WithPaddingType2D adds padding type 2D option. It can be either "same" or "valid".
+func WithPaddingType2D(val string) Conv2DConfigOpt {
+	return func(cfg *Conv2DConfig) {
+		cfg.PaddingType = val
+	}
+}
+
+// WithPaddingMode2D adds padding mode 2D option. It can be "zeros", "reflect", "replicate" or "circular".
+func WithPaddingMode2D(val string) Conv2DConfigOpt {
+	return func(cfg *Conv2DConfig) {
+		cfg.PaddingMode = val
+	}
+}
+
 // WithDilation2D adds dilation 2D option.
 func WithDilation2D(val int64) Conv2DConfigOpt {
 	return func(cfg *Conv2DConfig) {
@@ -176,6 +248,8 @@ func DefaultConv2DConfig() *Conv2DConfig {
 		Bias:     true,
 		WsInit:   NewKaimingUniformInit(WithKaimingNegativeSlope(negSlope)),
 		BsInit:   nil,
+
+		PaddingMode: PaddingModeZeros,
 	}
 }
 
@@ -195,12 +269,15 @@ func NewConv2DConfig(opts ...Conv2DConfigOpt) *Conv2DConfig {
 // Conv3DConfig is configuration struct for convolution 3D.
 type Conv3DConfig struct {
 	Stride   []int64
-	Padding  []int64
+	Padding  []int64 // per dimension padding or per side (begin, end) padding of each dimension
 	Dilation []int64
 	Groups   int64
 	Bias     bool
 	WsInit   Init
 	BsInit   Init
+
+	PaddingType string // optional "same" or "valid". If set, `Padding` is ignored.
+	PaddingMode string // "zeros" (default), "reflect", "replicate" or "circular"
 }
 
 // Conv3DConfigOpt is option type for Conv3DConfig.
@@ -220,6 +297,31 @@ func WithPadding3D(val int64) Conv3DConfigOpt {
 	}
 }
 
+// WithPaddingSides3D adds asymmetric padding 3D option with 6 values
+// in order (front, back), (top, bottom), (left, right).
+func WithPaddingSides3D(vals ...int64) Conv3DConfigOpt {
+	if len(vals) != 6 {
+		panic(fmt.Sprintf("WithPaddingSides3D() failed: expected 6 values. Got %v", len(vals)))
+	}
+	return func(cfg *Conv3DConfig) {
+		cfg.Padding = vals
+	}
+}
+
+// WithPaddingType3D adds padding type 3D option. It can be either "same" or "valid".
+func WithPaddingType3D(val string) Conv3DConfigOpt {
+	return func(cfg *Conv3DConfig) {
+		cfg.PaddingType = val
+	}
+}
+
+// WithPaddingMode3D adds padding mode 3D option. It can be "zeros", "reflect", "replicate" or "circular".
+func WithPaddingMode3D(val string) Conv3DConfigOpt {
+	return func(cfg *Conv3DConfig) {
+		cfg.PaddingMode = val
+	}
+}
+
 // WithDilation3D adds dilation 3D option.
 func WithDilation3D(val int64) Conv3DConfigOpt {
 	return func(cfg *Conv3DConfig) {
@@ -266,6 +368,8 @@ func DefaultConv3DConfig() *Conv3DConfig {
 		Bias:     true,
 		WsInit:   NewKaimingUniformInit(WithKaimingNegativeSlope(negSlope)),
 		BsInit:   nil,
+
+		PaddingMode: PaddingModeZeros,
 	}
 }
 
@@ -511,18 +615,122 @@ func NewConv(vs *Path, inDim, outDim int64, ksizes []int64, config interface{})
 	}
 }
 
+// convPadding resolves padding of a convolution layer given its weight of shape [out, in, kernel...].
+//
+// It returns input to be fed to convolution op (explicitly padded if padding is asymmetric or
+// padding mode is not "zeros"), padding for the convolution op, and whether input was padded,
+// in which case the caller should drop it after use.
+func convPadding(xs, ws *ts.Tensor, stride, padding, dilation []int64, paddingType, paddingMode string) (*ts.Tensor, []int64, bool) {
+	ksizes := ws.MustSize()[2:]
+	n := len(ksizes)
+
+	// per side padding: (begin, end) for each spatial dimension
+	sides := make([]int64, 2*n)
+	switch paddingType {
+	case PaddingValid:
+	case PaddingSame:
+		for i := 0; i < n; i++ {
+			if stride[i] != 1 {
+				err := fmt.Errorf("convPadding() failed: padding %q is not supported for strided convolutions. Got stride %v", PaddingSame, stride)
+				panic(err)
+			}
+			// NOTE. As in Pytorch, extra padding goes to the end side.
+			total := dilation[i] * (ksizes[i] - 1)
+			sides[2*i] = total / 2
+			sides[2*i+1] = total - total/2
+		}
+	case "":
+		switch len(padding) {
+		case n:
+			for i, p := range padding {
+				sides[2*i], sides[2*i+1] = p, p
+			}
+		case 2 * n:
+			copy(sides, padding)
+		default:
+			err := fmt.Errorf("convPadding() failed: expected padding of %v or %v values. Got %v", n, 2*n, padding)
+			panic(err)
+		}
+	default:
+		err := fmt.Errorf("convPadding() failed: unsupported padding type %q", paddingType)
+		panic(err)
+	}
+
+	symmetric := true
+	for i := 0; i < n; i++ {
+		if sides[2*i] != sides[2*i+1] {
+			symmetric = false
+			break
+		}
+	}
+
+	if paddingMode == "" {
+		paddingMode = PaddingModeZeros
+	}
+
+	convPad := make([]int64, n)
+	if paddingMode == PaddingModeZeros && symmetric {
+		for i := 0; i < n; i++ {
+			convPad[i] = sides[2*i]
+		}
+		return xs, convPad, false
+	}
+
+	var (
+		mode  string
+		value []float64
+	)
+	switch paddingMode {
+	case PaddingModeZeros:
+		mode = "constant"
+		value = []float64{0.0}
+	case PaddingModeReflect, PaddingModeReplicate, PaddingModeCircular:
+		mode = paddingMode
+	default:
+		err := fmt.Errorf("convPadding() failed: unsupported padding mode %q", paddingMode)
+		panic(err)
+	}
+
+	// NOTE. `pad` starts from the last dimension.
+	pad := make([]int64, 0, 2*n)
+	for i := n - 1; i >= 0; i-- {
+		pad = append(pad, sides[2*i], sides[2*i+1])
+	}
+
+	return xs.MustPad(pad, mode, value, false), convPad, true
+}
+
 // Implement Module for Conv1D, Conv2D, Conv3D:
 // ============================================
 
 func (c *Conv1D) Forward(xs *ts.Tensor) *ts.Tensor {
-	return ts.MustConv1d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.Dilation, c.Config.Groups)
+	xs = preForward(c, xs)
+	input, padding, padded := convPadding(xs, c.Ws, c.Config.Stride, c.Config.Padding, c.Config.Dilation, c.Config.PaddingType, c.Config.PaddingMode)
+	out := ts.MustConv1d(input, c.Ws, c.Bs, c.Config.Stride, padding, c.Config.Dilation, c.Config.Groups)
+	if padded {
+		input.MustDrop()
+	}
+	return postForward(c, xs, out)
 }
 
 func (c *Conv2D) Forward(xs *ts.Tensor) *ts.Tensor {
-	return ts.MustConv2d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.Dilation, c.Config.Groups)
+	xs = preForward(c, xs)
+	input, padding, padded := convPadding(xs, c.Ws, c.Config.Stride, c.Config.Padding, c.Config.Dilation, c.Config.PaddingType, c.Config.PaddingMode)
+	out := ts.MustConv2d(input, c.Ws, c.Bs, c.Config.Stride, padding, c.Config.Dilation, c.Config.Groups)
+	if padded {
+		input.MustDrop()
+	}
+	return postForward(c, xs, out)
 }
+
 func (c *Conv3D) Forward(xs *ts.Tensor) *ts.Tensor {
-	return ts.MustConv3d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.Dilation, c.Config.Groups)
+	xs = preForward(c, xs)
+	input, padding, padded := convPadding(xs, c.Ws, c.Config.Stride, c.Config.Padding, c.Config.Dilation, c.Config.PaddingType, c.Config.PaddingMode)
+	out := ts.MustConv3d(input, c.Ws, c.Bs, c.Config.Stride, padding, c.Config.Dilation, c.Config.Groups)
+	if padded {
+		input.MustDrop()
+	}
+	return postForward(c, xs, out)
 }
 
 // Implement ModuleT for Conv1D, Conv2D, Conv3D:
@@ -531,12 +739,13 @@ func (c *Conv3D) Forward(xs *ts.Tensor) *ts.Tensor {
 // NOTE: `train` param won't be used, will be?
 
 func (c *Conv1D) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
-	return ts.MustConv1d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.Dilation, c.Config.Groups)
+	return c.Forward(xs)
 }
 
 func (c *Conv2D) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
-	return ts.MustConv2d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.Dilation, c.Config.Groups)
+	return c.Forward(xs)
 }
+
 func (c *Conv3D) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
-	return ts.MustConv3d(xs, c.Ws, c.Bs, c.Config.Stride, c.Config.Padding, c.Config.Dilation, c.Config.Groups)
+	return c.Forward(xs)
 }
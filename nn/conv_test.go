@@ -0,0 +1,86 @@
+package nn_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/nn"
+	"github.com/nullbull/gotch/ts"
+)
+
+func assertTensorClose(t *testing.T, name string, got, want *ts.Tensor) {
+	if !reflect.DeepEqual(got.MustSize(), want.MustSize()) {
+		t.Fatalf("%s - Expected shape: %v, got %v\n", name, want.MustSize(), got.MustSize())
+	}
+	if !got.MustAllclose(want, 1e-5, 1e-6, false, false) {
+		t.Errorf("%s - Expected: %v\n", name, want.Float64Values())
+		t.Errorf("%s - Got: %v\n", name, got.Float64Values())
+	}
+}
+
+func TestConv2DPadding(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	path := vs.Root()
+	xs := ts.MustRandn([]int64{1, 2, 5, 6}, gotch.Float, gotch.CPU)
+
+	// reflect
+	conv := nn.NewConv2D(path.Sub("reflect"), 2, 3, 3, nn.NewConv2DConfig(nn.WithPadding2D(1), nn.WithPaddingMode2D(nn.PaddingModeReflect)))
+	padded := xs.MustReflectionPad2d([]int64{1, 1, 1, 1}, false)
+	want := ts.MustConv2d(padded, conv.Ws, conv.Bs, []int64{1, 1}, []int64{0, 0}, []int64{1, 1}, 1)
+	assertTensorClose(t, "Conv2D(reflect)", conv.Forward(xs), want)
+
+	// replicate
+	conv = nn.NewConv2D(path.Sub("replicate"), 2, 3, 3, nn.NewConv2DConfig(nn.WithPadding2D(1), nn.WithPaddingMode2D(nn.PaddingModeReplicate)))
+	padded = xs.MustReplicationPad2d([]int64{1, 1, 1, 1}, false)
+	want = ts.MustConv2d(padded, conv.Ws, conv.Bs, []int64{1, 1}, []int64{0, 0}, []int64{1, 1}, 1)
+	assertTensorClose(t, "Conv2D(replicate)", conv.Forward(xs), want)
+
+	// same with even kernel size needs asymmetric padding
+	conv = nn.NewConv2D(path.Sub("same"), 2, 3, 4, nn.NewConv2DConfig(nn.WithPaddingType2D(nn.PaddingSame), nn.WithDilation2D(2)))
+	want = ts.MustConv2dPadding(xs, conv.Ws, conv.Bs, []int64{1, 1}, "same", []int64{2, 2}, 1)
+	assertTensorClose(t, "Conv2D(same)", conv.Forward(xs), want)
+
+	// valid
+	conv = nn.NewConv2D(path.Sub("valid"), 2, 3, 3, nn.NewConv2DConfig(nn.WithPadding2D(2), nn.WithPaddingType2D(nn.PaddingValid)))
+	want = ts.MustConv2dPadding(xs, conv.Ws, conv.Bs, []int64{1, 1}, "valid", []int64{1, 1}, 1)
+	assertTensorClose(t, "Conv2D(valid)", conv.Forward(xs), want)
+
+	// asymmetric: (top, bottom), (left, right)
+	conv = nn.NewConv2D(path.Sub("sides"), 2, 3, 3, nn.NewConv2DConfig(nn.WithPaddingSides2D(0, 1, 2, 0)))
+	padded = xs.MustConstantPadNd([]int64{2, 0, 0, 1}, false)
+	want = ts.MustConv2d(padded, conv.Ws, conv.Bs, []int64{1, 1}, []int64{0, 0}, []int64{1, 1}, 1)
+	assertTensorClose(t, "Conv2D(sides)", conv.Forward(xs), want)
+}
+
+func TestConv1DCircularPadding(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	xs := ts.MustOfSlice([]float32{1, 2, 3, 4}).MustView([]int64{1, 1, 4}, true)
+	cfg := nn.NewConv1DConfig(nn.WithPadding1D(1), nn.WithPaddingMode1D(nn.PaddingModeCircular), nn.WithBias1D(false), nn.WithWsInit1D(nn.NewConstInit(1.0)))
+	conv := nn.NewConv1D(vs.Root(), 1, 1, 3, cfg)
+
+	// padded input: [4, 1, 2, 3, 4, 1]
+	want := []float64{7, 6, 9, 8}
+	got := conv.Forward(xs).Float64Values()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected: %v\n", want)
+		t.Errorf("Got: %v\n", got)
+	}
+}
+
+func TestConvTranspose2DOutputPadding(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	xs := ts.MustRandn([]int64{1, 3, 5, 5}, gotch.Float, gotch.CPU)
+	cfg := nn.NewConvTranspose2DConfig(nn.WithStrideTranspose2D(2), nn.WithPaddingTranspose2D(1), nn.WithOutputPaddingTranspose2D(1))
+	conv := nn.NewConvTranspose2D(vs.Root(), 3, 2, []int64{3, 3}, cfg)
+
+	// (5 - 1)*2 - 2*1 + (3 - 1) + 1 + 1 = 10
+	out := conv.Forward(xs)
+	wantSize := []int64{1, 2, 10, 10}
+	if !reflect.DeepEqual(wantSize, out.MustSize()) {
+		t.Fatalf("Expected shape: %v, got %v\n", wantSize, out.MustSize())
+	}
+
+	want := ts.MustConvTranspose2d(xs, conv.Ws, conv.Bs, []int64{2, 2}, []int64{1, 1}, []int64{1, 1}, 1, []int64{1, 1})
+	assertTensorClose(t, "ConvTranspose2D", out, want)
+}
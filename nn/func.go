@@ -6,37 +6,106 @@ import (
 	"github.com/nullbull/gotch/ts"
 )
 
+// funcID identifies a Func or FuncT as functions are not comparable.
+type funcID struct{ _ byte }
+
 type Func struct {
-	f func(*ts.Tensor) *ts.Tensor
+	f        func(*ts.Tensor) *ts.Tensor
+	id       *funcID
+	name     string
+	children []NamedModule
 }
 
 func NewFunc(fn func(*ts.Tensor) *ts.Tensor) (retVal Func) {
-	return Func{f: fn}
+	return Func{f: fn, id: new(funcID)}
+}
+
+// NewNamedFunc creates a Func with a name and the submodules used inside the closure
+// so that it can be introspected (see ModuleInfo).
+func NewNamedFunc(name string, fn func(*ts.Tensor) *ts.Tensor, children ...NamedModule) (retVal Func) {
+	return Func{f: fn, id: new(funcID), name: name, children: children}
 }
 
 // Implement Module interface for Func:
 // ====================================
 func (fn Func) Forward(xs *ts.Tensor) (retVal *ts.Tensor) {
-	return fn.f(xs)
+	xs = preForward(fn, xs)
+	out := fn.f(xs)
+	// NOTE. Func with children is traced through its children.
+	if len(fn.children) == 0 {
+		return postForward(fn, xs, out)
+	}
+
+	return runForwardHooks(fn, xs, out)
 }
 
 // ForwardT implements ModuleT for Func object as well.
 //
 // NOTE: train param will not be used.
 func (fn Func) ForwardT(xs *ts.Tensor, train bool) (retVal *ts.Tensor) {
-	return fn.f(xs)
+	return fn.Forward(xs)
 }
 
+// Implement ModuleInfo interface for Func:
+// ========================================
+
+// Name returns name of Func. Default="Func".
+func (fn Func) Name() string {
+	if fn.name == "" {
+		return "Func"
+	}
+	return fn.name
+}
+
+// Children returns submodules used inside the closure if specified.
+func (fn Func) Children() []NamedModule { return fn.children }
+
+// Parameters returns nil as Func does not own any parameters.
+func (fn Func) Parameters() []ts.NamedTensor { return nil }
+
 type FuncT struct {
-	f func(*ts.Tensor, bool) *ts.Tensor
+	f        func(*ts.Tensor, bool) *ts.Tensor
+	id       *funcID
+	name     string
+	children []NamedModule
 }
 
 func NewFuncT(fn func(*ts.Tensor, bool) *ts.Tensor) (retVal FuncT) {
-	return FuncT{f: fn}
+	return FuncT{f: fn, id: new(funcID)}
+}
+
+// NewNamedFuncT creates a FuncT with a name and the submodules used inside the closure
+// so that it can be introspected (see ModuleInfo).
+func NewNamedFuncT(name string, fn func(*ts.Tensor, bool) *ts.Tensor, children ...NamedModule) (retVal FuncT) {
+	return FuncT{f: fn, id: new(funcID), name: name, children: children}
 }
 
 // Implement Module interface for Func:
 // ====================================
 func (fn FuncT) ForwardT(xs *ts.Tensor, train bool) (retVal *ts.Tensor) {
-	return fn.f(xs, train)
+	xs = preForward(fn, xs)
+	out := fn.f(xs, train)
+	// NOTE. Func with children is traced through its children.
+	if len(fn.children) == 0 {
+		return postForward(fn, xs, out)
+	}
+
+	return runForwardHooks(fn, xs, out)
+}
+
+// Implement ModuleInfo interface for FuncT:
+// =========================================
+
+// Name returns name of FuncT. Default="FuncT".
+func (fn FuncT) Name() string {
+	if fn.name == "" {
+		return "FuncT"
+	}
+	return fn.name
 }
+
+// Children returns submodules used inside the closure if specified.
+func (fn FuncT) Children() []NamedModule { return fn.children }
+
+// Parameters returns nil as FuncT does not own any parameters.
+func (fn FuncT) Parameters() []ts.NamedTensor { return nil }
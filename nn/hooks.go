@@ -0,0 +1,302 @@
+package nn
+
+// Forward and backward hooks of modules.
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+// ForwardPreHook is called before forward pass of a module with its input.
+// It can return a new input or nil to keep it. A new input returned by a previous
+// pre-hook is dropped when replaced, the caller's input is not.
+type ForwardPreHook func(m interface{}, xs *ts.Tensor) *ts.Tensor
+
+// ForwardHook is called after forward pass of a module with its input and output.
+// It can return a new output or nil to keep it. The replaced output is dropped.
+//
+// NOTE. Containers drop intermediate outputs after forward pass. Use `ShallowClone()`
+// to keep output beyond the hook call, e.g. for feature extraction.
+type ForwardHook func(m interface{}, xs, out *ts.Tensor) *ts.Tensor
+
+// BackwardHook is called during backward pass with gradient with respect to
+// module output. It can return a new gradient or nil to keep it. A new gradient
+// returned by a previous backward hook is dropped when replaced.
+type BackwardHook func(m interface{}, gradOut *ts.Tensor) *ts.Tensor
+
+// HookHandle is a handle of a registered module hook.
+type HookHandle struct {
+	key  interface{}
+	id   uint64
+	once sync.Once
+}
+
+// Remove removes the hook. It is safe to call multiple times.
+func (h *HookHandle) Remove() {
+	h.once.Do(func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+
+		entries := hooks[h.key]
+		for i, e := range entries {
+			if e.id == h.id {
+				entries = append(entries[:i:i], entries[i+1:]...)
+				break
+			}
+		}
+		if len(entries) == 0 {
+			delete(hooks, h.key)
+		} else {
+			hooks[h.key] = entries
+		}
+		atomic.AddInt64(&numHooks, -1)
+	})
+}
+
+// hookEntry holds one of forward pre-hook, forward hook or backward hook.
+type hookEntry struct {
+	id  uint64
+	pre ForwardPreHook
+	fwd ForwardHook
+	bwd BackwardHook
+}
+
+var (
+	hooksMu  sync.RWMutex
+	hooks    = make(map[interface{}][]hookEntry)
+	nextID   uint64
+	numHooks int64 // number of registered hooks to skip lookup when there is none.
+)
+
+// moduleKey returns a key identifying a module in hook registry.
+func moduleKey(m interface{}) (interface{}, bool) {
+	switch f := m.(type) {
+	case Func:
+		return f.id, f.id != nil
+	case FuncT:
+		return f.id, f.id != nil
+	}
+
+	return m, isComparable(m)
+}
+
+// runsOwnHooks reports whether a module calls its hooks in its forward pass.
+// Hooks of other modules are called by the containing Sequential or SequentialT.
+func runsOwnHooks(m interface{}) bool {
+	switch m.(type) {
+	case *Linear, *Conv1D, *Conv2D, *Conv3D, *ConvTranspose1D, *ConvTranspose2D, *ConvTranspose3D,
+		*BatchNorm, *LayerNorm, *Embedding, *Dropout, *Identity, *MaxPool2D,
+		*ArcFace, *CosFace, *SphereFace, *Sequential, *SequentialT, Func, FuncT,
+		*Parallel, *Concat, *Residual:
+		return true
+	}
+
+	return false
+}
+
+func registerHook(m interface{}, e hookEntry) (*HookHandle, error) {
+	key, ok := moduleKey(m)
+	if !ok {
+		err := fmt.Errorf("Cannot register hook on module of type %T. Module should be a pointer, nn.Func or nn.FuncT.", m)
+		return nil, err
+	}
+
+	hooksMu.Lock()
+	nextID++
+	e.id = nextID
+	hooks[key] = append(hooks[key], e)
+	hooksMu.Unlock()
+	atomic.AddInt64(&numHooks, 1)
+
+	return &HookHandle{key: key, id: e.id}, nil
+}
+
+// RegisterForwardPreHook registers a hook called before forward pass of a module.
+//
+// Hooks are called by built-in layers, Sequential, SequentialT, Func and FuncT in their
+// forward pass, and for other modules, e.g. `vision` blocks, by the containing Sequential or SequentialT.
+func RegisterForwardPreHook(m interface{}, hook ForwardPreHook) (*HookHandle, error) {
+	return registerHook(m, hookEntry{pre: hook})
+}
+
+// MustRegisterForwardPreHook registers a forward pre-hook. It panics if error.
+func MustRegisterForwardPreHook(m interface{}, hook ForwardPreHook) *HookHandle {
+	h, err := RegisterForwardPreHook(m, hook)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return h
+}
+
+// RegisterForwardHook registers a hook called after forward pass of a module.
+// See RegisterForwardPreHook for modules the hook is called for.
+func RegisterForwardHook(m interface{}, hook ForwardHook) (*HookHandle, error) {
+	return registerHook(m, hookEntry{fwd: hook})
+}
+
+// MustRegisterForwardHook registers a forward hook. It panics if error.
+func MustRegisterForwardHook(m interface{}, hook ForwardHook) *HookHandle {
+	h, err := RegisterForwardHook(m, hook)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return h
+}
+
+// RegisterBackwardHook registers a hook called with gradient with respect to module output
+// during backward pass. The hook is attached to module output in forward pass when it
+// requires gradient.
+func RegisterBackwardHook(m interface{}, hook BackwardHook) (*HookHandle, error) {
+	return registerHook(m, hookEntry{bwd: hook})
+}
+
+// MustRegisterBackwardHook registers a backward hook. It panics if error.
+func MustRegisterBackwardHook(m interface{}, hook BackwardHook) *HookHandle {
+	h, err := RegisterBackwardHook(m, hook)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return h
+}
+
+// getHooks returns a snapshot of hooks registered on a module.
+func getHooks(m interface{}) []hookEntry {
+	if atomic.LoadInt64(&numHooks) == 0 {
+		return nil
+	}
+	key, ok := moduleKey(m)
+	if !ok {
+		return nil
+	}
+
+	hooksMu.RLock()
+	entries := hooks[key]
+	hooksMu.RUnlock()
+
+	return entries
+}
+
+// runForwardPreHooks calls forward pre-hooks of a module and returns the (new) input.
+func runForwardPreHooks(m interface{}, xs *ts.Tensor) *ts.Tensor {
+	input := xs
+	for _, e := range getHooks(m) {
+		if e.pre == nil {
+			continue
+		}
+		if ys := e.pre(m, xs); ys != nil && ys != xs {
+			if xs != input {
+				xs.MustDrop()
+			}
+			xs = ys
+		}
+	}
+
+	return xs
+}
+
+// runForwardHooks calls forward hooks of a module, attaches backward hooks
+// to the output and returns the (new) output.
+func runForwardHooks(m interface{}, xs, out *ts.Tensor) *ts.Tensor {
+	entries := getHooks(m)
+	if len(entries) == 0 || out == nil {
+		return out
+	}
+
+	hasBackward := false
+	for _, e := range entries {
+		switch {
+		case e.fwd != nil:
+			if ys := e.fwd(m, xs, out); ys != nil && ys != out {
+				// NOTE. some layers return their input as output.
+				if out != xs {
+					out.MustDrop()
+				}
+				out = ys
+			}
+		case e.bwd != nil:
+			hasBackward = true
+		}
+	}
+
+	if hasBackward && out.MustRequiresGrad() {
+		out.MustRegisterHook(func(grad *ts.Tensor) *ts.Tensor {
+			var newGrad *ts.Tensor
+			// NOTE. use hooks registered at the time of backward pass.
+			for _, e := range getHooks(m) {
+				if e.bwd == nil {
+					continue
+				}
+				g := grad
+				if newGrad != nil {
+					g = newGrad
+				}
+				if r := e.bwd(m, g); r != nil && r != g {
+					if newGrad != nil {
+						newGrad.MustDrop()
+					}
+					newGrad = r
+				}
+			}
+
+			return newGrad
+		})
+	}
+
+	return out
+}
+
+// preForward is called by layers at the start of their forward pass.
+func preForward(m interface{}, xs *ts.Tensor) *ts.Tensor {
+	return runForwardPreHooks(m, xs)
+}
+
+// postForward is called by layers at the end of their forward pass.
+func postForward(m interface{}, xs, out *ts.Tensor) *ts.Tensor {
+	traceForward(m, xs, out)
+
+	return runForwardHooks(m, xs, out)
+}
+
+// GetModule returns a submodule given its dot-separated path from the root module,
+// e.g. "features.4.conv1". Sequential and SequentialT entries are named by their indices.
+func GetModule(root interface{}, name string) (interface{}, error) {
+	m := root
+	if name == "" {
+		return m, nil
+	}
+
+	for _, part := range strings.Split(name, SEP) {
+		found := false
+		for _, c := range ModuleChildren(m) {
+			if c.Name == part {
+				m = c.Module
+				found = true
+				break
+			}
+		}
+		if !found {
+			err := fmt.Errorf("GetModule() failed: module %q not found in %s.", name, ModuleName(root))
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// MustGetModule returns a submodule given its path. It panics if error.
+func MustGetModule(root interface{}, name string) interface{} {
+	m, err := GetModule(root, name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return m
+}
@@ -0,0 +1,148 @@
+package nn_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/nn"
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestForwardHooks(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	path := vs.Root()
+	cfg := nn.DefaultLinearConfig()
+	cfg.WsInit = nn.NewConstInit(1.0)
+	cfg.BsInit = nn.NewConstInit(0.5)
+	linear := nn.NewLinear(path.Sub("fc"), 2, 1, cfg)
+
+	seq := nn.SeqT()
+	seq.Add(linear)
+	seq.AddFn(nn.NewFunc(func(xs *ts.Tensor) *ts.Tensor {
+		return xs.MustMulScalar(ts.FloatScalar(2.0), false)
+	}))
+
+	// double input of linear layer
+	pre := nn.MustRegisterForwardPreHook(linear, func(m interface{}, xs *ts.Tensor) *ts.Tensor {
+		return xs.MustMulScalar(ts.FloatScalar(2.0), false)
+	})
+
+	// capture linear output and add 1 to closure output selected by name.
+	var features *ts.Tensor
+	fwd := nn.MustRegisterForwardHook(linear, func(m interface{}, xs, out *ts.Tensor) *ts.Tensor {
+		features = out.MustShallowClone()
+		return nil
+	})
+	fn := nn.MustGetModule(seq, "1")
+	post := nn.MustRegisterForwardHook(fn, func(m interface{}, xs, out *ts.Tensor) *ts.Tensor {
+		return out.MustAddScalar(ts.FloatScalar(1.0), false)
+	})
+
+	xs := ts.MustOfSlice([]float32{1, 2}).MustView([]int64{1, 2}, true)
+	// (2*1 + 2*2 + 0.5)*2 + 1
+	got := seq.ForwardT(xs, false).Float64Values()
+	if want := []float64{14}; !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected output with hooks: %v, got %v\n", want, got)
+	}
+	if want, got := []float64{6.5}, features.Float64Values(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected captured features: %v, got %v\n", want, got)
+	}
+
+	pre.Remove()
+	fwd.Remove()
+	post.Remove()
+	got = seq.ForwardT(xs, false).Float64Values()
+	if want := []float64{7}; !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected output without hooks: %v, got %v\n", want, got)
+	}
+}
+
+func TestBackwardHook(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	cfg := nn.DefaultLinearConfig()
+	cfg.WsInit = nn.NewConstInit(1.0)
+	linear := nn.NewLinear(vs.Root(), 2, 1, cfg)
+
+	var gradOut []float64
+	h := nn.MustRegisterBackwardHook(linear, func(m interface{}, grad *ts.Tensor) *ts.Tensor {
+		gradOut = grad.Float64Values()
+		return grad.MustMulScalar(ts.FloatScalar(3.0), false)
+	})
+	defer h.Remove()
+
+	xs := ts.MustOfSlice([]float32{1, 2}).MustView([]int64{1, 2}, true)
+	loss := linear.Forward(xs).MustSum(gotch.Float, true)
+	loss.MustBackward()
+
+	if want := []float64{1}; !reflect.DeepEqual(want, gradOut) {
+		t.Errorf("Expected output gradient: %v, got %v\n", want, gradOut)
+	}
+	// d(loss)/d(ws) = 3 * xs
+	if want, got := []float64{3, 6}, linear.Ws.MustGrad(false).Float64Values(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected weight gradient: %v, got %v\n", want, got)
+	}
+}
+
+func isDropped(x *ts.Tensor) bool {
+	return !strings.Contains(ts.CheckCMemLeak(), fmt.Sprintf("%q", x.Name()))
+}
+
+func TestHooksDropReplacedTensors(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	cfg := nn.DefaultLinearConfig()
+	cfg.WsInit = nn.NewConstInit(1.0)
+	cfg.BsInit = nn.NewConstInit(0.0)
+	linear := nn.NewLinear(vs.Root(), 2, 1, cfg)
+
+	var pre1, out1, grad1 *ts.Tensor
+	for _, scale := range []float64{2.0, 1.0} {
+		scale := scale
+		h1 := nn.MustRegisterForwardPreHook(linear, func(m interface{}, xs *ts.Tensor) *ts.Tensor {
+			ys := xs.MustMulScalar(ts.FloatScalar(scale), false)
+			if pre1 == nil {
+				pre1 = ys
+			}
+			return ys
+		})
+		defer h1.Remove()
+		h2 := nn.MustRegisterForwardHook(linear, func(m interface{}, xs, out *ts.Tensor) *ts.Tensor {
+			if out1 == nil {
+				out1 = out
+			}
+			return out.MustMulScalar(ts.FloatScalar(scale), false)
+		})
+		defer h2.Remove()
+		h3 := nn.MustRegisterBackwardHook(linear, func(m interface{}, grad *ts.Tensor) *ts.Tensor {
+			g := grad.MustMulScalar(ts.FloatScalar(scale), false)
+			if grad1 == nil {
+				grad1 = g
+			}
+			return g
+		})
+		defer h3.Remove()
+	}
+
+	xs := ts.MustOfSlice([]float32{1, 2}).MustView([]int64{1, 2}, true)
+	loss := linear.Forward(xs).MustSum(gotch.Float, true)
+	loss.MustBackward()
+
+	// 2*(1 + 2)*2
+	if want, got := []float64{12}, loss.Float64Values(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected loss: %v, got %v\n", want, got)
+	}
+	// gradient 1 doubled by backward hook and forward hook
+	if want, got := []float64{8, 16}, linear.Ws.MustGrad(false).Float64Values(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected weight gradient: %v, got %v\n", want, got)
+	}
+	if isDropped(xs) {
+		t.Errorf("Expected caller's input not dropped\n")
+	}
+	for name, x := range map[string]*ts.Tensor{"input": pre1, "output": out1, "gradient": grad1} {
+		if !isDropped(x) {
+			t.Errorf("Expected replaced %s dropped\n", name)
+		}
+	}
+}
@@ -420,3 +420,395 @@ func XavierUniform_(x *ts.Tensor, gainOpt ...float64) {
 
 	src.MustDrop()
 }
+
+// setFromInit re-initializes (in-place) an existing tensor with values created by `InitTensor`.
+func setFromInit(ini Init, tensor *ts.Tensor) {
+	initTs := ini.InitTensor(tensor.MustSize(), tensor.MustDevice(), tensor.DType())
+	ts.NoGrad(func() {
+		tensor.Copy_(initTs)
+	})
+	initTs.MustDrop()
+}
+
+// kaimingNormalInit :
+// ===================
+
+type kaimingNormalInit struct {
+	NegativeSlope float64
+	Mode          string
+	NonLinearity  string
+}
+
+var _ Init = new(kaimingNormalInit)
+
+// NewKaimingNormalInit creates Kaiming (He) normal initializer.
+//
+// Values are sampled from N(0, std^2) where std = gain / sqrt(fan) and
+// fan is either fan-in (default) or fan-out depending on mode.
+// Paper: https://arxiv.org/abs/1502.01852
+func NewKaimingNormalInit(opts ...KaimingOption) *kaimingNormalInit {
+	o := NewKaimingOptions(opts...)
+
+	return &kaimingNormalInit{
+		NegativeSlope: o.NegativeSlope,
+		Mode:          o.Mode,
+		NonLinearity:  o.NonLinearity,
+	}
+}
+
+func (k *kaimingNormalInit) InitTensor(dims []int64, device gotch.Device, dtypeOpt ...gotch.DType) (retVal *ts.Tensor) {
+	dtype := gotch.DefaultDType
+	if len(dtypeOpt) > 0 {
+		dtype = dtypeOpt[0]
+	}
+
+	fanIn, fanOut, err := CalculateFans(dims)
+	if err != nil {
+		panic(err)
+	}
+	fan := fanIn
+	if k.Mode == "fanOut" {
+		fan = fanOut
+	}
+
+	gain, err := calculateGain(k.NonLinearity, k.NegativeSlope)
+	if err != nil {
+		err = fmt.Errorf("kaimingNormalInit.InitTensor() failed: %v\n", err)
+		panic(err)
+	}
+
+	std := gain / math.Sqrt(float64(fan))
+
+	return NewRandnInit(0.0, std).InitTensor(dims, device, dtype)
+}
+
+func (k *kaimingNormalInit) Set(tensor *ts.Tensor) {
+	setFromInit(k, tensor)
+}
+
+// xavierNormalInit :
+// ==================
+
+type xavierNormalInit struct {
+	gain float64
+}
+
+var _ Init = new(xavierNormalInit)
+
+// NewXavierNormalInit creates Xavier (Glorot) normal initializer.
+//
+// Values are sampled from N(0, std^2) where std = gain * sqrt(2 / (fanIn + fanOut)).
+// Default gain is 1.0.
+func NewXavierNormalInit(gainOpt ...float64) xavierNormalInit {
+	gain := 1.0
+	if len(gainOpt) > 0 {
+		gain = gainOpt[0]
+	}
+
+	return xavierNormalInit{gain}
+}
+
+func (x xavierNormalInit) InitTensor(dims []int64, device gotch.Device, dtypeOpt ...gotch.DType) (retVal *ts.Tensor) {
+	dtype := gotch.DefaultDType
+	if len(dtypeOpt) > 0 {
+		dtype = dtypeOpt[0]
+	}
+
+	fanIn, fanOut, err := CalculateFans(dims)
+	if err != nil {
+		panic(err)
+	}
+
+	std := x.gain * math.Sqrt(2.0/float64(fanIn+fanOut))
+
+	return NewRandnInit(0.0, std).InitTensor(dims, device, dtype)
+}
+
+func (x xavierNormalInit) Set(tensor *ts.Tensor) {
+	setFromInit(x, tensor)
+}
+
+// orthogonalInit :
+// ================
+
+type orthogonalInit struct {
+	gain float64
+}
+
+var _ Init = new(orthogonalInit)
+
+// NewOrthogonalInit creates (semi) orthogonal initializer.
+//
+// Tensor must have at least 2 dimensions. Trailing dimensions are flattened.
+// Default gain is 1.0.
+// Paper: https://arxiv.org/abs/1312.6120
+func NewOrthogonalInit(gainOpt ...float64) orthogonalInit {
+	gain := 1.0
+	if len(gainOpt) > 0 {
+		gain = gainOpt[0]
+	}
+
+	return orthogonalInit{gain}
+}
+
+func (o orthogonalInit) InitTensor(dims []int64, device gotch.Device, dtypeOpt ...gotch.DType) (retVal *ts.Tensor) {
+	dtype := gotch.DefaultDType
+	if len(dtypeOpt) > 0 {
+		dtype = dtypeOpt[0]
+	}
+
+	if len(dims) < 2 {
+		err := fmt.Errorf("orthogonalInit.InitTensor() failed: only tensors with 2 or more dimensions are supported. Got %v", dims)
+		panic(err)
+	}
+
+	rows := dims[0]
+	cols := product(dims) / rows
+
+	ts.NoGrad(func() {
+		flat := ts.MustRandn([]int64{rows, cols}, gotch.Double, device)
+		if rows < cols {
+			flat = flat.MustT(true)
+		}
+
+		// compute QR factorization and make Q uniform
+		q, r := ts.MustLinalgQr(flat, "reduced")
+		flat.MustDrop()
+		ph := r.MustDiag(0, true).MustSign(true)
+		q = q.MustMul(ph, true)
+		ph.MustDrop()
+
+		if rows < cols {
+			q = q.MustT(true)
+		}
+
+		retVal = q.MustMulScalar(ts.FloatScalar(o.gain), true).MustReshape(dims, true).MustTotype(dtype, true)
+	})
+
+	return retVal
+}
+
+func (o orthogonalInit) Set(tensor *ts.Tensor) {
+	setFromInit(o, tensor)
+}
+
+// truncatedNormalInit :
+// =====================
+
+type truncatedNormalInit struct {
+	mean  float64
+	stdev float64
+	lo    float64
+	up    float64
+}
+
+var _ Init = new(truncatedNormalInit)
+
+// NewTruncatedNormalInit creates initializer with values drawn from N(mean, stdev^2)
+// truncated to [lo, up] (absolute values, i.e. not in unit of stdev).
+func NewTruncatedNormalInit(mean, stdev, lo, up float64) truncatedNormalInit {
+	if lo >= up {
+		panic(fmt.Sprintf("NewTruncatedNormalInit() failed: lower bound (%v) must be smaller than upper bound (%v)", lo, up))
+	}
+
+	return truncatedNormalInit{mean, stdev, lo, up}
+}
+
+func (t truncatedNormalInit) InitTensor(dims []int64, device gotch.Device, dtypeOpt ...gotch.DType) (retVal *ts.Tensor) {
+	dtype := gotch.DefaultDType
+	if len(dtypeOpt) > 0 {
+		dtype = dtypeOpt[0]
+	}
+
+	// normal cumulative distribution function
+	normCdf := func(x float64) float64 {
+		return (1.0 + math.Erf(x/math.Sqrt2)) / 2.0
+	}
+	l := normCdf((t.lo - t.mean) / t.stdev)
+	u := normCdf((t.up - t.mean) / t.stdev)
+
+	// Sample uniformly from [2l-1, 2u-1] then use inverse CDF transform for normal distribution.
+	// Ref. https://github.com/pytorch/pytorch/blob/main/torch/nn/init.py
+	ts.NoGrad(func() {
+		x := ts.MustZeros(dims, dtype, device)
+		x.Uniform_(2*l-1, 2*u-1)
+		retVal = x.MustErfinv(true).
+			MustMulScalar(ts.FloatScalar(t.stdev*math.Sqrt2), true).
+			MustAddScalar(ts.FloatScalar(t.mean), true).
+			MustClamp(ts.FloatScalar(t.lo), ts.FloatScalar(t.up), true)
+	})
+
+	return retVal
+}
+
+func (t truncatedNormalInit) Set(tensor *ts.Tensor) {
+	setFromInit(t, tensor)
+}
+
+// sparseInit :
+// ============
+
+type sparseInit struct {
+	sparsity float64
+	stdev    float64
+}
+
+var _ Init = new(sparseInit)
+
+// NewSparseInit creates initializer for 2D tensor as a sparse matrix where
+// a fraction `sparsity` of elements in each column is set to zero and
+// the non-zero elements are drawn from N(0, stdev^2). Default stdev is 0.01.
+//
+// Paper: https://www.cs.toronto.edu/~jmartens/docs/Deep_HessianFree.pdf
+func NewSparseInit(sparsity float64, stdevOpt ...float64) sparseInit {
+	stdev := 0.01
+	if len(stdevOpt) > 0 {
+		stdev = stdevOpt[0]
+	}
+
+	return sparseInit{sparsity, stdev}
+}
+
+func (s sparseInit) InitTensor(dims []int64, device gotch.Device, dtypeOpt ...gotch.DType) (retVal *ts.Tensor) {
+	dtype := gotch.DefaultDType
+	if len(dtypeOpt) > 0 {
+		dtype = dtypeOpt[0]
+	}
+
+	if len(dims) != 2 {
+		err := fmt.Errorf("sparseInit.InitTensor() failed: only tensors with 2 dimensions are supported. Got %v", dims)
+		panic(err)
+	}
+
+	rows, cols := dims[0], dims[1]
+	numZeros := int64(math.Ceil(s.sparsity * float64(rows)))
+
+	ts.NoGrad(func() {
+		retVal = ts.MustZeros(dims, dtype, device)
+		retVal.MustNormal_(0.0, s.stdev)
+		if numZeros == 0 {
+			return
+		}
+		for col := int64(0); col < cols; col++ {
+			idx := ts.MustRandperm(rows, gotch.Int64, device).MustNarrow(0, 0, numZeros, true)
+			colTs := retVal.MustSelect(1, col, false)
+			colTs.MustIndexFill_(0, idx, ts.FloatScalar(0.0))
+			colTs.MustDrop()
+			idx.MustDrop()
+		}
+	})
+
+	return retVal
+}
+
+func (s sparseInit) Set(tensor *ts.Tensor) {
+	setFromInit(s, tensor)
+}
+
+// diracInit :
+// ===========
+
+type diracInit struct {
+	groups int64
+}
+
+var _ Init = new(diracInit)
+
+// NewDiracInit creates Dirac delta initializer for 3, 4 or 5 dimensional tensor
+// (convolution weight of shape [outChannels, inChannels/groups, kernel...]).
+//
+// It preserves identity of the inputs in convolutional layers where as many
+// input channels are preserved as possible. Default groups is 1.
+func NewDiracInit(groupsOpt ...int64) diracInit {
+	groups := int64(1)
+	if len(groupsOpt) > 0 {
+		groups = groupsOpt[0]
+	}
+
+	return diracInit{groups}
+}
+
+func (d diracInit) InitTensor(dims []int64, device gotch.Device, dtypeOpt ...gotch.DType) (retVal *ts.Tensor) {
+	dtype := gotch.DefaultDType
+	if len(dtypeOpt) > 0 {
+		dtype = dtypeOpt[0]
+	}
+
+	ndims := len(dims)
+	if ndims < 3 || ndims > 5 {
+		err := fmt.Errorf("diracInit.InitTensor() failed: only tensors with 3, 4, or 5 dimensions are supported. Got %v", dims)
+		panic(err)
+	}
+	if dims[0]%d.groups != 0 {
+		err := fmt.Errorf("diracInit.InitTensor() failed: dim 0 (%v) must be divisible by groups (%v)", dims[0], d.groups)
+		panic(err)
+	}
+
+	outChansPerGroup := dims[0] / d.groups
+	minDim := outChansPerGroup
+	if dims[1] < minDim {
+		minDim = dims[1]
+	}
+
+	// row-major strides
+	strides := make([]int64, ndims)
+	strides[ndims-1] = 1
+	for i := ndims - 2; i >= 0; i-- {
+		strides[i] = strides[i+1] * dims[i+1]
+	}
+	// offset of kernel center
+	var center int64
+	for i := 2; i < ndims; i++ {
+		center += (dims[i] / 2) * strides[i]
+	}
+
+	data := make([]float64, product(dims))
+	for g := int64(0); g < d.groups; g++ {
+		for i := int64(0); i < minDim; i++ {
+			data[(g*outChansPerGroup+i)*strides[0]+i*strides[1]+center] = 1.0
+		}
+	}
+
+	x, err := ts.NewTensorFromData(data, dims)
+	if err != nil {
+		log.Fatalf("diracInit - InitTensor method call error: %v\n", err)
+	}
+	retVal = x.MustTotype(dtype, true).MustTo(device, true)
+
+	return retVal
+}
+
+func (d diracInit) Set(tensor *ts.Tensor) {
+	setFromInit(d, tensor)
+}
+
+// eyeInit :
+// =========
+
+type eyeInit struct{}
+
+var _ Init = new(eyeInit)
+
+// NewEyeInit creates initializer for 2D tensor as identity matrix. For non-square
+// matrix, ones are on the main diagonal and the rest are zeros.
+func NewEyeInit() eyeInit {
+	return eyeInit{}
+}
+
+func (e eyeInit) InitTensor(dims []int64, device gotch.Device, dtypeOpt ...gotch.DType) (retVal *ts.Tensor) {
+	dtype := gotch.DefaultDType
+	if len(dtypeOpt) > 0 {
+		dtype = dtypeOpt[0]
+	}
+
+	if len(dims) != 2 {
+		err := fmt.Errorf("eyeInit.InitTensor() failed: only tensors with 2 dimensions are supported. Got %v", dims)
+		panic(err)
+	}
+
+	return ts.MustEyeM(dims[0], dims[1], dtype, device)
+}
+
+func (e eyeInit) Set(tensor *ts.Tensor) {
+	setFromInit(e, tensor)
+}
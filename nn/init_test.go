@@ -2,6 +2,7 @@ package nn
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -42,3 +43,81 @@ func TestInitTensor_Memcheck(t *testing.T) {
 	time.Sleep(time.Second * 10)
 	gotch.PrintMemStats("Final")
 }
+
+func TestOrthogonalInit(t *testing.T) {
+	for _, dims := range [][]int64{{3, 5}, {5, 3}} {
+		w := NewOrthogonalInit().InitTensor(dims, gotch.CPU, gotch.Double)
+		// rows (or columns if rows > columns) are orthonormal
+		var gram *ts.Tensor
+		if dims[0] <= dims[1] {
+			gram = w.MustMatmul(w.MustT(false), false)
+		} else {
+			gram = w.MustT(false).MustMatmul(w, false)
+		}
+		n := gram.MustSize()[0]
+		eye := ts.MustEye(n, gotch.Double, gotch.CPU)
+		if !gram.MustAllclose(eye, 1e-5, 1e-8, false, false) {
+			t.Errorf("Expected orthogonal matrix of shape %v, got gram matrix: %v\n", dims, gram.Float64Values())
+		}
+	}
+}
+
+func TestTruncatedNormalInit(t *testing.T) {
+	x := NewTruncatedNormalInit(0.0, 1.0, -0.5, 0.5).InitTensor([]int64{1000}, gotch.CPU, gotch.Double)
+	for _, v := range x.Float64Values() {
+		if v < -0.5 || v > 0.5 {
+			t.Fatalf("Expected values in [-0.5, 0.5], got %v\n", v)
+		}
+	}
+}
+
+func TestKaimingNormalInit(t *testing.T) {
+	x := NewKaimingNormalInit(WithKaimingNonLinearity("relu")).InitTensor([]int64{500, 200}, gotch.CPU, gotch.Double)
+	got := x.MustStd(true, false).Float64Values()[0]
+	want := math.Sqrt(2.0 / 200)
+	if math.Abs(got-want) > 0.05*want {
+		t.Errorf("Expected std: %v, got %v\n", want, got)
+	}
+}
+
+func TestSparseInit(t *testing.T) {
+	x := NewSparseInit(0.3).InitTensor([]int64{10, 4}, gotch.CPU, gotch.Double)
+	vals := x.Float64Values()
+	for col := 0; col < 4; col++ {
+		zeros := 0
+		for row := 0; row < 10; row++ {
+			if vals[row*4+col] == 0 {
+				zeros++
+			}
+		}
+		if zeros != 3 {
+			t.Errorf("Expected 3 zeros in column %v, got %v\n", col, zeros)
+		}
+	}
+}
+
+func TestDiracInit(t *testing.T) {
+	// [out=2, in=3, k=3]
+	x := NewDiracInit().InitTensor([]int64{2, 3, 3}, gotch.CPU, gotch.Double)
+	want := []float64{
+		0, 1, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 1, 0, 0, 0, 0,
+	}
+	got := x.Float64Values()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected: %v\nGot: %v\n", want, got)
+		}
+	}
+}
+
+func TestEyeInit(t *testing.T) {
+	x := NewEyeInit().InitTensor([]int64{2, 3}, gotch.CPU, gotch.Double)
+	want := []float64{1, 0, 0, 0, 1, 0}
+	got := x.Float64Values()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected: %v\nGot: %v\n", want, got)
+		}
+	}
+}
@@ -54,6 +54,7 @@ func NewLayerNorm(vs *Path, normalizedShape []int64, config *LayerNormConfig) *L
 // =========================================
 
 func (ln *LayerNorm) Forward(xs *ts.Tensor) (retVal *ts.Tensor) {
-
-	return ts.MustLayerNorm(xs, ln.NormalizedShape, ln.Ws, ln.Bs, ln.Config.Eps, ln.Config.CudnnEnable)
+	xs = preForward(ln, xs)
+	retVal = ts.MustLayerNorm(xs, ln.NormalizedShape, ln.Ws, ln.Bs, ln.Config.Eps, ln.Config.CudnnEnable)
+	return postForward(ln, xs, retVal)
 }
@@ -99,18 +99,19 @@ func NewLinear(vs *Path, inDim, outDim int64, c *LinearConfig) *Linear {
 //	  1 1 1
 //		1 1 1 ]
 func (l *Linear) Forward(xs *ts.Tensor) (retVal *ts.Tensor) {
+	xs = preForward(l, xs)
 	mul := xs.MustMatmul(l.Ws, false)
 	if l.Bs != nil {
-		return mul.MustAdd(l.Bs, true)
+		retVal = mul.MustAdd(l.Bs, true)
 	} else {
-		return mul
+		retVal = mul
 	}
+	return postForward(l, xs, retVal)
 }
 
 // ForwardT implements ModuleT interface for Linear layer.
 //
 // NOTE: train param will not be used.
 func (l *Linear) ForwardT(xs *ts.Tensor, train bool) (retVal *ts.Tensor) {
-	mul := xs.MustMatmul(l.Ws, false)
-	return mul.MustAdd(l.Bs, true)
+	return l.Forward(xs)
 }
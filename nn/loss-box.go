@@ -0,0 +1,186 @@
+package nn
+
+// IoU-based losses for bounding box regression.
+//
+// Boxes are expected in (x1, y1, x2, y2) format with 0 <= x1 < x2 and 0 <= y1 < y2
+// and of shape [..., 4]. Unreduced losses have the shape of boxes without the last dimension.
+
+import (
+	"math"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+// boxStats holds intermediate values shared by IoU-based losses.
+type boxStats struct {
+	iou   *ts.Tensor // intersection over union
+	union *ts.Tensor // area of union
+
+	// enclosing (smallest box covering both boxes) corners
+	xc1, yc1, xc2, yc2 *ts.Tensor
+
+	// coordinates of predicted and target boxes
+	x1, y1, x2, y2     *ts.Tensor
+	x1g, y1g, x2g, y2g *ts.Tensor
+}
+
+func (s *boxStats) drop() {
+	for _, x := range []*ts.Tensor{s.iou, s.union, s.xc1, s.yc1, s.xc2, s.yc2, s.x1, s.y1, s.x2, s.y2, s.x1g, s.y1g, s.x2g, s.y2g} {
+		x.MustDrop()
+	}
+}
+
+func boxCoords(boxes *ts.Tensor) (x1, y1, x2, y2 *ts.Tensor) {
+	return boxes.MustSelect(-1, 0, false), boxes.MustSelect(-1, 1, false), boxes.MustSelect(-1, 2, false), boxes.MustSelect(-1, 3, false)
+}
+
+func newBoxStats(boxes1, boxes2 *ts.Tensor, eps float64) *boxStats {
+	s := new(boxStats)
+	s.x1, s.y1, s.x2, s.y2 = boxCoords(boxes1)
+	s.x1g, s.y1g, s.x2g, s.y2g = boxCoords(boxes2)
+
+	// intersection
+	xkis1 := s.x1.MustMaximum(s.x1g, false)
+	ykis1 := s.y1.MustMaximum(s.y1g, false)
+	xkis2 := s.x2.MustMinimum(s.x2g, false)
+	ykis2 := s.y2.MustMinimum(s.y2g, false)
+	iw := xkis2.MustSub(xkis1, true).MustClampMin(ts.FloatScalar(0.0), true)
+	ih := ykis2.MustSub(ykis1, true).MustClampMin(ts.FloatScalar(0.0), true)
+	xkis1.MustDrop()
+	ykis1.MustDrop()
+	intersection := iw.MustMul(ih, true)
+	ih.MustDrop()
+
+	// union
+	h1 := s.y2.MustSub(s.y1, false)
+	h2 := s.y2g.MustSub(s.y1g, false)
+	area1 := s.x2.MustSub(s.x1, false).MustMul(h1, true)
+	area2 := s.x2g.MustSub(s.x1g, false).MustMul(h2, true)
+	h1.MustDrop()
+	h2.MustDrop()
+	s.union = area1.MustAdd(area2, true).MustSub(intersection, true).MustAddScalar(ts.FloatScalar(eps), true)
+	area2.MustDrop()
+
+	s.iou = intersection.MustDiv(s.union, true)
+
+	// enclosing box
+	s.xc1 = s.x1.MustMinimum(s.x1g, false)
+	s.yc1 = s.y1.MustMinimum(s.y1g, false)
+	s.xc2 = s.x2.MustMaximum(s.x2g, false)
+	s.yc2 = s.y2.MustMaximum(s.y2g, false)
+
+	return s
+}
+
+// diouPenalty calculates squared center distance over squared enclosing diagonal.
+func (s *boxStats) diouPenalty(eps float64) *ts.Tensor {
+	cw := s.xc2.MustSub(s.xc1, false).MustSquare(true)
+	ch := s.yc2.MustSub(s.yc1, false).MustSquare(true)
+	diag := cw.MustAdd(ch, true).MustAddScalar(ts.FloatScalar(eps), true)
+	ch.MustDrop()
+
+	// squared distance between box centers: ((x1 + x2) - (x1g + x2g)) / 2
+	dx := s.x1.MustAdd(s.x2, false).MustSub(s.x1g, true).MustSub(s.x2g, true).MustMulScalar(ts.FloatScalar(0.5), true).MustSquare(true)
+	dy := s.y1.MustAdd(s.y2, false).MustSub(s.y1g, true).MustSub(s.y2g, true).MustMulScalar(ts.FloatScalar(0.5), true).MustSquare(true)
+	dist := dx.MustAdd(dy, true)
+	dy.MustDrop()
+
+	penalty := dist.MustDiv(diag, true)
+	diag.MustDrop()
+
+	return penalty
+}
+
+// GIoULoss calculates generalized IoU loss (https://giou.stanford.edu/).
+//
+// loss = 1 - IoU + (area(C) - area(union)) / area(C) where C is the smallest enclosing box.
+// Options: WithLossFnEps (default=1e-7), WithLossFnReduction.
+func GIoULoss(boxes1, boxes2 *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Eps = 1e-7
+	for _, o := range opts {
+		o(options)
+	}
+
+	s := newBoxStats(boxes1, boxes2, options.Eps)
+	defer s.drop()
+
+	ch := s.yc2.MustSub(s.yc1, false)
+	areaC := s.xc2.MustSub(s.xc1, false).MustMul(ch, true).MustAddScalar(ts.FloatScalar(options.Eps), true)
+	ch.MustDrop()
+	penalty := areaC.MustSub(s.union, false).MustDiv(areaC, true)
+	areaC.MustDrop()
+
+	loss := s.iou.MustRsubScalar(ts.FloatScalar(1.0), false).MustAdd(penalty, true)
+	penalty.MustDrop()
+
+	return reduce(loss, options.Reduction, true)
+}
+
+// DIoULoss calculates distance IoU loss (https://arxiv.org/abs/1911.08287).
+//
+// loss = 1 - IoU + d^2/c^2 where d is distance between box centers and c is diagonal
+// length of the smallest enclosing box.
+// Options: WithLossFnEps (default=1e-7), WithLossFnReduction.
+func DIoULoss(boxes1, boxes2 *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Eps = 1e-7
+	for _, o := range opts {
+		o(options)
+	}
+
+	s := newBoxStats(boxes1, boxes2, options.Eps)
+	defer s.drop()
+
+	penalty := s.diouPenalty(options.Eps)
+	loss := s.iou.MustRsubScalar(ts.FloatScalar(1.0), false).MustAdd(penalty, true)
+	penalty.MustDrop()
+
+	return reduce(loss, options.Reduction, true)
+}
+
+// CIoULoss calculates complete IoU loss (https://arxiv.org/abs/1911.08287).
+//
+// loss = DIoU loss + alpha * v where v measures consistency of aspect ratios and
+// alpha = v / (1 - IoU + v) is a trade-off factor (excluded from gradient).
+// Options: WithLossFnEps (default=1e-7), WithLossFnReduction.
+func CIoULoss(boxes1, boxes2 *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Eps = 1e-7
+	for _, o := range opts {
+		o(options)
+	}
+
+	s := newBoxStats(boxes1, boxes2, options.Eps)
+	defer s.drop()
+
+	penalty := s.diouPenalty(options.Eps)
+	loss := s.iou.MustRsubScalar(ts.FloatScalar(1.0), false).MustAdd(penalty, true)
+	penalty.MustDrop()
+
+	// v = 4/pi^2 * (atan(w_gt/h_gt) - atan(w/h))^2
+	w := s.x2.MustSub(s.x1, false)
+	h := s.y2.MustSub(s.y1, false)
+	wg := s.x2g.MustSub(s.x1g, false)
+	hg := s.y2g.MustSub(s.y1g, false)
+	atanPred := w.MustDiv(h, true).MustAtan(true)
+	atanGt := wg.MustDiv(hg, true).MustAtan(true)
+	h.MustDrop()
+	hg.MustDrop()
+	v := atanGt.MustSub(atanPred, true).MustSquare(true).MustMulScalar(ts.FloatScalar(4/(math.Pi*math.Pi)), true)
+	atanPred.MustDrop()
+
+	var alpha *ts.Tensor
+	ts.NoGrad(func() {
+		den := s.iou.MustRsubScalar(ts.FloatScalar(1.0), false).MustAdd(v, true).MustAddScalar(ts.FloatScalar(options.Eps), true)
+		alpha = v.MustDiv(den, false).MustDetach(true)
+		den.MustDrop()
+	})
+
+	av := alpha.MustMul(v, true)
+	v.MustDrop()
+	loss = loss.MustAdd(av, true)
+	av.MustDrop()
+
+	return reduce(loss, options.Reduction, true)
+}
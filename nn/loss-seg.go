@@ -0,0 +1,333 @@
+package nn
+
+// Losses for imbalanced classification and segmentation.
+//
+// All losses in this file expect logits of shape [N, C, d1, d2, ...] (class dimension at 1) and
+// detect target type by its shape:
+// - multi-label: target has the same shape as logits and contains 0 or 1 (probabilities from sigmoid).
+// - multi-class: target has shape [N, d1, d2, ...] and contains class indices (probabilities from softmax).
+
+import (
+	"log"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+func isMultiLabel(logits, target *ts.Tensor) bool {
+	return target.Dim() == logits.Dim()
+}
+
+// segProbs returns probabilities and one-hot (multi-hot) encoded target, both of shape [N, C, d1, d2, ...].
+func segProbs(logits, target *ts.Tensor) (probs, onehot *ts.Tensor) {
+	dtype := logits.DType()
+	if isMultiLabel(logits, target) {
+		return logits.MustSigmoid(false), target.MustTotype(dtype, false)
+	}
+
+	// one-hot encoded target [N, d1, ..., C] -> [N, C, d1, ...]
+	ndims := int64(logits.Dim())
+	nclasses := logits.MustSize()[1]
+	dims := []int64{0, ndims - 1}
+	for i := int64(1); i < ndims-1; i++ {
+		dims = append(dims, i)
+	}
+	onehot = target.MustOneHot(nclasses, false).MustPermute(dims, true).MustTotype(dtype, true)
+
+	return logits.MustSoftmax(1, dtype, false), onehot
+}
+
+// segStats returns per-class true positives, sum of probabilities and sum of targets.
+// Statistics are aggregated over batch and spatial dimensions.
+func segStats(logits, target *ts.Tensor) (tp, sumProbs, sumTarget *ts.Tensor) {
+	probs, onehot := segProbs(logits, target)
+	dtype := probs.DType()
+	sumDims := []int64{0}
+	for i := int64(2); i < int64(probs.Dim()); i++ {
+		sumDims = append(sumDims, i)
+	}
+
+	tp = probs.MustMul(onehot, false).MustSumDimIntlist(sumDims, false, dtype, true)
+	sumProbs = probs.MustSumDimIntlist(sumDims, false, dtype, true)
+	sumTarget = onehot.MustSumDimIntlist(sumDims, false, dtype, true)
+
+	return tp, sumProbs, sumTarget
+}
+
+// reduceClasses applies class weights and reduction on per-class loss.
+func reduceClasses(loss *ts.Tensor, options *lossFnOptions) *ts.Tensor {
+	if len(options.ClassWeights) > 0 {
+		ws := classWeights(loss, options.ClassWeights)
+		loss = loss.MustMul(ws, true)
+		ws.MustDrop()
+	}
+
+	return reduce(loss, options.Reduction, true)
+}
+
+// FocalLoss calculates focal loss as described in "Focal Loss for Dense Object Detection"
+// (https://arxiv.org/abs/1708.02002).
+//
+// - multi-label target: sigmoid focal loss. Positive examples are weighted by `alpha` and
+// negative ones by `1 - alpha`. Negative alpha disables weighting.
+// - multi-class target: softmax focal loss. Classes are weighted by class weights. Target
+// equal to ignore index is excluded.
+// Options: WithLossFnAlpha (default=0.25), WithLossFnGamma (default=2.0), WithLossFnWeights,
+// WithLossFnIgnoreIndex, WithLossFnReduction.
+func FocalLoss(logits, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	if isMultiLabel(logits, target) {
+		return sigmoidFocalLoss(logits, target, options)
+	}
+
+	return softmaxFocalLoss(logits, target, options)
+}
+
+func sigmoidFocalLoss(logits, target *ts.Tensor, options *lossFnOptions) *ts.Tensor {
+	y := target.MustTotype(logits.DType(), false)
+	p := logits.MustSigmoid(false)
+	ce := logits.MustBinaryCrossEntropyWithLogits(y, ts.None, ts.None, ts.ReductionNone, false)
+
+	// 1 - p_t = p + y - 2*p*y
+	py := p.MustMul(y, false).MustMulScalar(ts.FloatScalar(2.0), true)
+	oneMinusPt := p.MustAdd(y, true).MustSub(py, true)
+	py.MustDrop()
+	modulator := oneMinusPt.MustPowTensorScalar(ts.FloatScalar(options.Gamma), true)
+	loss := ce.MustMul(modulator, true)
+	modulator.MustDrop()
+
+	if options.Alpha >= 0 {
+		// alpha_t = alpha*y + (1 - alpha)*(1 - y) = (2*alpha - 1)*y + 1 - alpha
+		alphaT := y.MustMulScalar(ts.FloatScalar(2*options.Alpha-1), false).MustAddScalar(ts.FloatScalar(1-options.Alpha), true)
+		loss = loss.MustMul(alphaT, true)
+		alphaT.MustDrop()
+	}
+	y.MustDrop()
+
+	return reduce(loss, options.Reduction, true)
+}
+
+// reduceMasked applies reduction on an unreduced loss tensor multiplied by a (0 or 1) mask.
+// "mean" reduction averages over non-zero mask elements only, and is zero if all elements are masked out.
+func reduceMasked(loss, mask *ts.Tensor, reduction int64) *ts.Tensor {
+	dtype := loss.DType()
+	loss = loss.MustMul(mask, true)
+
+	switch reduction {
+	case ts.ReductionNone:
+		return loss
+	case ts.ReductionSum:
+		return loss.MustSum(dtype, true)
+	default:
+		count := mask.MustSum(dtype, false).MustClampMin(ts.FloatScalar(1.0), true)
+		out := loss.MustSum(dtype, true).MustDiv(count, true)
+		count.MustDrop()
+		return out
+	}
+}
+
+func softmaxFocalLoss(logits, target *ts.Tensor, options *lossFnOptions) *ts.Tensor {
+	dtype := logits.DType()
+
+	// mask out ignored targets and use class 0 as their placeholder.
+	valid := target.MustNe(ts.IntScalar(options.IgnoreIndex), false)
+	safeTarget := target.MustMul(valid, false).MustUnsqueeze(1, true)
+	validMask := valid.MustTotype(dtype, true)
+
+	logp := logits.MustLogSoftmax(1, dtype, false)
+	logpt := logp.MustGather(1, safeTarget, false, true).MustSqueezeDim(1, true)
+	pt := logpt.MustExp(false)
+	modulator := pt.MustRsubScalar(ts.FloatScalar(1.0), true).MustPowTensorScalar(ts.FloatScalar(options.Gamma), true)
+	loss := logpt.MustMul(modulator, true).MustNeg(true)
+	modulator.MustDrop()
+
+	if len(options.ClassWeights) > 0 {
+		ws := classWeights(logits, options.ClassWeights)
+		shape := target.MustSize()
+		flatTarget := safeTarget.MustFlatten(0, -1, false)
+		alphaT := ws.MustIndexSelect(0, flatTarget, true).MustView(shape, true)
+		flatTarget.MustDrop()
+		loss = loss.MustMul(alphaT, true)
+		alphaT.MustDrop()
+	}
+	safeTarget.MustDrop()
+
+	out := reduceMasked(loss, validMask, options.Reduction)
+	validMask.MustDrop()
+
+	return out
+}
+
+// DiceLoss calculates soft Dice loss per class: 1 - (2*TP + smooth)/(sum(probs) + sum(target) + smooth).
+//
+// Statistics are aggregated over batch and spatial dimensions then per-class losses are weighted
+// by class weights and reduced. With `ReductionNone`, loss of shape [C] is returned.
+// Options: WithLossFnSmooth (default=0), WithLossFnEps (default=1e-7), WithLossFnWeights,
+// WithLossFnReduction.
+func DiceLoss(logits, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Eps = 1e-7
+	for _, o := range opts {
+		o(options)
+	}
+
+	tp, sumProbs, sumTarget := segStats(logits, target)
+	num := tp.MustMulScalar(ts.FloatScalar(2.0), true).MustAddScalar(ts.FloatScalar(options.Smooth), true)
+	den := sumProbs.MustAdd(sumTarget, true).MustAddScalar(ts.FloatScalar(options.Smooth), true).MustClampMin(ts.FloatScalar(options.Eps), true)
+	sumTarget.MustDrop()
+	score := num.MustDiv(den, true)
+	den.MustDrop()
+	loss := score.MustRsubScalar(ts.FloatScalar(1.0), true)
+
+	return reduceClasses(loss, options)
+}
+
+// TverskyLoss calculates Tversky loss per class: 1 - (TP + smooth)/(TP + alpha*FP + beta*FN + smooth).
+//
+// Alpha weights false positives and beta weights false negatives. alpha = beta = 0.5 is equivalent
+// to Dice loss. With gamma other than 1, it is the focal Tversky loss (https://arxiv.org/abs/1810.07842).
+// Options: WithLossFnAlpha (default=0.5), WithLossFnBeta (default=0.5), WithLossFnGamma (default=1.0),
+// WithLossFnSmooth (default=0), WithLossFnEps (default=1e-7), WithLossFnWeights, WithLossFnReduction.
+func TverskyLoss(logits, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Alpha = 0.5
+	options.Beta = 0.5
+	options.Gamma = 1.0
+	options.Eps = 1e-7
+	for _, o := range opts {
+		o(options)
+	}
+
+	tp, sumProbs, sumTarget := segStats(logits, target)
+	fp := sumProbs.MustSub(tp, true).MustMulScalar(ts.FloatScalar(options.Alpha), true)
+	fn := sumTarget.MustSub(tp, true).MustMulScalar(ts.FloatScalar(options.Beta), true)
+	num := tp.MustAddScalar(ts.FloatScalar(options.Smooth), false)
+	den := tp.MustAdd(fp, true).MustAdd(fn, true).MustAddScalar(ts.FloatScalar(options.Smooth), true).MustClampMin(ts.FloatScalar(options.Eps), true)
+	fp.MustDrop()
+	fn.MustDrop()
+	score := num.MustDiv(den, true)
+	den.MustDrop()
+	loss := score.MustRsubScalar(ts.FloatScalar(1.0), true)
+	if options.Gamma != 1.0 {
+		loss = loss.MustPowTensorScalar(ts.FloatScalar(options.Gamma), true)
+	}
+
+	return reduceClasses(loss, options)
+}
+
+// lovaszGrad computes gradient of the Lovasz extension w.r.t sorted errors.
+// See Alg. 1 in paper https://arxiv.org/abs/1705.08790
+func lovaszGrad(gtSorted *ts.Tensor) *ts.Tensor {
+	dtype := gtSorted.DType()
+	n := gtSorted.MustSize()[0]
+	gts := gtSorted.MustSum(dtype, false)
+	intersection := gtSorted.MustCumsum(0, dtype, false).MustNeg(true).MustAdd(gts, true)
+	union := gtSorted.MustRsubScalar(ts.FloatScalar(1.0), false).MustCumsum(0, dtype, true).MustAdd(gts, true)
+	gts.MustDrop()
+	jaccard := intersection.MustDiv(union, true).MustRsubScalar(ts.FloatScalar(1.0), true)
+	union.MustDrop()
+	if n == 1 {
+		return jaccard
+	}
+
+	// jaccard[1:] = jaccard[1:] - jaccard[:-1]
+	zero := ts.MustZeros([]int64{1}, dtype, jaccard.MustDevice())
+	head := jaccard.MustNarrow(0, 0, n-1, false)
+	prev := ts.MustCat([]*ts.Tensor{zero, head}, 0)
+	zero.MustDrop()
+	head.MustDrop()
+	grad := jaccard.MustSub(prev, true)
+	prev.MustDrop()
+
+	return grad
+}
+
+// lovaszFlat computes Lovasz loss from flattened (1D) errors and binary ground truth.
+func lovaszFlat(errors, gt *ts.Tensor) *ts.Tensor {
+	errorsSorted, perm := errors.MustSort(0, true, false)
+	gtSorted := gt.MustIndexSelect(0, perm, false)
+	perm.MustDrop()
+	grad := lovaszGrad(gtSorted)
+	gtSorted.MustDrop()
+	loss := errorsSorted.MustRelu(true).MustDot(grad, true)
+	grad.MustDrop()
+
+	return loss
+}
+
+// LovaszLoss calculates Lovasz loss, a surrogate of Jaccard (IoU) loss (https://arxiv.org/abs/1705.08790).
+//
+// - multi-label target: Lovasz hinge loss is calculated for each class.
+// - multi-class target: Lovasz-Softmax loss is calculated for each class present in target.
+// Per-class losses are weighted by class weights and reduced. Class weights, if any, must have
+// one value per class.
+// Options: WithLossFnWeights, WithLossFnReduction.
+func LovaszLoss(logits, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	dtype := logits.DType()
+	nclasses := logits.MustSize()[1]
+	if n := len(options.ClassWeights); n > 0 && int64(n) != nclasses {
+		log.Fatalf("LovaszLoss() failed: expected %v class weights, got %v\n", nclasses, n)
+	}
+	multiLabel := isMultiLabel(logits, target)
+
+	var (
+		probs   *ts.Tensor
+		losses  []*ts.Tensor
+		weights []float64
+	)
+	if !multiLabel {
+		probs = logits.MustSoftmax(1, dtype, false)
+	}
+
+	for c := int64(0); c < nclasses; c++ {
+		var errors, gt *ts.Tensor
+		if multiLabel {
+			// hinge errors: 1 - logits * signs where signs = 2*gt - 1
+			x := logits.MustSelect(1, c, false).MustFlatten(0, -1, true)
+			gt = target.MustSelect(1, c, false).MustFlatten(0, -1, true).MustTotype(dtype, true)
+			signs := gt.MustMulScalar(ts.FloatScalar(2.0), false).MustAddScalar(ts.FloatScalar(-1.0), true)
+			errors = x.MustMul(signs, true).MustRsubScalar(ts.FloatScalar(1.0), true)
+			signs.MustDrop()
+		} else {
+			gt = target.MustEq(ts.IntScalar(c), false).MustFlatten(0, -1, true).MustTotype(dtype, true)
+			if gt.MustSum(dtype, false).Float64Values(true)[0] == 0 {
+				// class not present
+				gt.MustDrop()
+				continue
+			}
+			p := probs.MustSelect(1, c, false).MustFlatten(0, -1, true)
+			errors = gt.MustSub(p, false).MustAbs(true)
+			p.MustDrop()
+		}
+
+		losses = append(losses, lovaszFlat(errors, gt))
+		errors.MustDrop()
+		gt.MustDrop()
+		if len(options.ClassWeights) > 0 {
+			weights = append(weights, options.ClassWeights[c])
+		}
+	}
+	if probs != nil {
+		probs.MustDrop()
+	}
+
+	if len(losses) == 0 {
+		return ts.MustZeros([]int64{}, dtype, logits.MustDevice())
+	}
+
+	loss := ts.MustStack(losses, 0)
+	for _, l := range losses {
+		l.MustDrop()
+	}
+	options.ClassWeights = weights
+
+	return reduceClasses(loss, options)
+}
@@ -1,14 +1,32 @@
 package nn
 
 import (
+	"math"
+
 	"github.com/nullbull/gotch/ts"
 )
 
 type lossFnOptions struct {
-	ClassWeights []float64
-	Reduction    int64 // 0: "None", 1: "mean", 2: "sum"
-	IgnoreIndex  int64
-	PosWeight    int64 // index of the weight attributed to positive class. Used in BCELoss
+	ClassWeights   []float64
+	Reduction      int64 // 0: "None", 1: "mean", 2: "sum"
+	IgnoreIndex    int64
+	PosWeight      []float64 // weight(s) of positive examples. Used in BCELoss and BCEWithLogitsLoss
+	LabelSmoothing float64   // used in CrossEntropyLoss
+	Margin         float64   // used in margin-based losses. Default value depends on loss function.
+	Beta           float64   // threshold at which SmoothL1Loss changes from L2 to L1
+	Delta          float64   // threshold at which HuberLoss changes from quadratic to linear
+	LogTarget      bool      // whether target is in log-space. Used in KLDivLoss
+	P              float64   // norm degree. Used in TripletMarginLoss
+	Eps            float64   // small value for numerical stability. Default value depends on loss function.
+	Swap           bool      // distance swap. Used in TripletMarginLoss
+	LogInput       bool      // whether input is in log-space. Used in PoissonNLLLoss
+	Full           bool      // whether to add constant (Stirling/log(2*pi)) term. Used in PoissonNLLLoss and GaussianNLLLoss
+	Blank          int64     // blank label. Used in CTCLoss
+	ZeroInfinity   bool      // whether to zero infinite losses and associated gradients. Used in CTCLoss
+	Alpha          float64   // balancing factor. Used in FocalLoss and TverskyLoss
+	Gamma          float64   // focusing parameter. Used in FocalLoss and TverskyLoss
+	Smooth         float64   // smoothing constant added to numerator and denominator. Used in DiceLoss and TverskyLoss
+	Temperature    float64   // softmax temperature. Used in contrastive losses
 }
 
 type LossFnOption func(*lossFnOptions)
@@ -29,46 +47,174 @@ func WithLossFnIgnoreIndex(val int64) LossFnOption {
 	}
 }
 
-func WithLossFnPosWeight(val int64) LossFnOption {
+// WithLossFnPosWeight sets weight(s) of positive examples. A single value is
+// broadcast to all classes, otherwise number of values should equal to number of classes.
+func WithLossFnPosWeight(vals ...float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.PosWeight = vals
+	}
+}
+
+func WithLossFnLabelSmoothing(val float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.LabelSmoothing = val
+	}
+}
+
+func WithLossFnMargin(val float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Margin = val
+	}
+}
+
+func WithLossFnBeta(val float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Beta = val
+	}
+}
+
+func WithLossFnDelta(val float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Delta = val
+	}
+}
+
+func WithLossFnLogTarget(val bool) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.LogTarget = val
+	}
+}
+
+func WithLossFnP(val float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.P = val
+	}
+}
+
+func WithLossFnEps(val float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Eps = val
+	}
+}
+
+func WithLossFnSwap(val bool) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Swap = val
+	}
+}
+
+func WithLossFnLogInput(val bool) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.LogInput = val
+	}
+}
+
+func WithLossFnFull(val bool) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Full = val
+	}
+}
+
+func WithLossFnBlank(val int64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Blank = val
+	}
+}
+
+func WithLossFnZeroInfinity(val bool) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.ZeroInfinity = val
+	}
+}
+
+func WithLossFnAlpha(val float64) LossFnOption {
 	return func(o *lossFnOptions) {
-		o.PosWeight = val
+		o.Alpha = val
+	}
+}
+
+func WithLossFnGamma(val float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Gamma = val
+	}
+}
+
+func WithLossFnSmooth(val float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Smooth = val
+	}
+}
+
+func WithLossFnTemperature(val float64) LossFnOption {
+	return func(o *lossFnOptions) {
+		o.Temperature = val
 	}
 }
 
 func defaultLossFnOptions() *lossFnOptions {
 	return &lossFnOptions{
-		ClassWeights: nil,
-		Reduction:    1, // "mean"
-		IgnoreIndex:  -100,
-		PosWeight:    -1,
+		ClassWeights:   nil,
+		Reduction:      1, // "mean"
+		IgnoreIndex:    -100,
+		PosWeight:      nil,
+		LabelSmoothing: 0.0,
+		Margin:         0.0,
+		Beta:           1.0,
+		Delta:          1.0,
+		LogTarget:      false,
+		P:              2.0,
+		Eps:            1e-6,
+		Swap:           false,
+		LogInput:       true,
+		Full:           false,
+		Blank:          0,
+		ZeroInfinity:   false,
+		Alpha:          0.25,
+		Gamma:          2.0,
+		Smooth:         0.0,
+		Temperature:    0.07,
+	}
+}
+
+// classWeights creates class weights tensor on the same device and dtype as input or
+// an undefined tensor if no class weights specified.
+func classWeights(x *ts.Tensor, vals []float64) *ts.Tensor {
+	if len(vals) == 0 {
+		return ts.NewTensor()
+	}
+
+	return ts.MustOfSlice(vals).MustTotype(x.DType(), true).MustTo(x.MustDevice(), true)
+}
+
+// reduce applies reduction (0: "none", 1: "mean", 2: "sum") on an unreduced loss tensor.
+func reduce(loss *ts.Tensor, reduction int64, del bool) *ts.Tensor {
+	switch reduction {
+	case ts.ReductionNone:
+		if del {
+			return loss
+		}
+		return loss.MustShallowClone()
+	case ts.ReductionSum:
+		return loss.MustSum(loss.DType(), del)
+	default:
+		return loss.MustMean(loss.DType(), del)
 	}
 }
 
 // CrossEntropyLoss calculates cross entropy loss.
 // Ref. https://github.com/pytorch/pytorch/blob/15be189f0de4addf4f68d18022500f67617ab05d/torch/nn/functional.py#L2012
-// - logits: tensor of shape [B, C, H, W] corresponding the raw output of the model.
-// - target: ground truth tensor of shape [B, 1, H, W]
-// - posWeight: scalar representing the weight attributed to positive class.
-// This is especially useful for an imbalanced dataset
+// - logits: tensor of shape [B, C] or [B, C, d1, d2, ...] (e.g. [B, C, H, W]) corresponding the raw output
+// of the model. Class dimension is dim 1 (dim 0 for unbatched logits of shape [C]) as in Pytorch.
+// - target: ground truth class indices of shape [B] or [B, d1, d2, ...]
+// Options: WithLossFnWeights, WithLossFnIgnoreIndex, WithLossFnLabelSmoothing, WithLossFnReduction.
 func CrossEntropyLoss(logits, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
 	options := defaultLossFnOptions()
 	for _, o := range opts {
 		o(options)
 	}
 
-	var ws *ts.Tensor
-	device := logits.MustDevice()
-	dtype := logits.DType()
-	if len(options.ClassWeights) > 0 {
-		ws = ts.MustOfSlice(options.ClassWeights).MustTotype(dtype, true).MustTo(device, true)
-	} else {
-		ws = ts.NewTensor()
-	}
-	reduction := options.Reduction
-	ignoreIndex := options.IgnoreIndex
-
-	logSm := logits.MustLogSoftmax(-1, dtype, false)
-	loss := logSm.MustNllLoss(target, ws, reduction, ignoreIndex, true)
+	ws := classWeights(logits, options.ClassWeights)
+	loss := logits.MustCrossEntropyLoss(target, ws, options.Reduction, options.IgnoreIndex, options.LabelSmoothing, false)
 	ws.MustDrop()
 
 	return loss
@@ -86,24 +232,34 @@ func BCELoss(logits, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
 		o(options)
 	}
 
-	var ws *ts.Tensor
-	device := logits.MustDevice()
-	dtype := logits.DType()
-	if len(options.ClassWeights) > 0 {
-		ws = ts.MustOfSlice(options.ClassWeights).MustTotype(dtype, true).MustTo(device, true)
-	} else {
-		ws = ts.NewTensor()
-	}
+	ws := classWeights(logits, options.ClassWeights)
+	posWeight := classWeights(logits, options.PosWeight)
 	reduction := options.Reduction
 
-	var posWeight *ts.Tensor
-	if options.PosWeight >= 0 {
-		posWeight = ts.MustOfSlice([]int64{options.PosWeight})
-	} else {
-		posWeight = ts.NewTensor()
+	loss := logits.MustSqueeze(false).MustBinaryCrossEntropyWithLogits(target, ws, posWeight, reduction, true)
+	ws.MustDrop()
+	posWeight.MustDrop()
+
+	return loss
+}
+
+// BCEWithLogitsLoss calculates binary cross entropy between raw logits and target probabilities.
+//
+// Unlike BCELoss, logits are not squeezed hence logits and target should have the same shape.
+// Options: WithLossFnWeights, WithLossFnPosWeight, WithLossFnReduction.
+func BCEWithLogitsLoss(logits, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
 	}
 
-	loss := logits.MustSqueeze(false).MustBinaryCrossEntropyWithLogits(target, ws, posWeight, reduction, true)
+	ws := classWeights(logits, options.ClassWeights)
+	posWeight := classWeights(logits, options.PosWeight)
+
+	loss := logits.MustBinaryCrossEntropyWithLogits(target, ws, posWeight, options.Reduction, false)
+	ws.MustDrop()
+	posWeight.MustDrop()
+
 	return loss
 }
 
@@ -119,3 +275,214 @@ func MSELoss(logits, labels *ts.Tensor, reductionOpt ...int64) *ts.Tensor {
 
 	return out
 }
+
+// NLLLoss calculates negative log likelihood loss.
+//
+// - logProbs: log-probabilities of shape [N, C] or [N, C, d1, d2, ...]
+// - target: class indices of shape [N] or [N, d1, d2, ...]
+// Options: WithLossFnWeights, WithLossFnIgnoreIndex, WithLossFnReduction.
+func NLLLoss(logProbs, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	ws := classWeights(logProbs, options.ClassWeights)
+	loss := logProbs.MustNllLossNd(target, ws, options.Reduction, options.IgnoreIndex, false)
+	ws.MustDrop()
+
+	return loss
+}
+
+// L1Loss calculates mean absolute error between input and target.
+func L1Loss(input, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	return input.MustL1Loss(target, options.Reduction, false)
+}
+
+// SmoothL1Loss calculates smooth L1 loss which uses a squared term if absolute
+// element-wise error falls below beta (default=1.0) and an L1 term otherwise.
+func SmoothL1Loss(input, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	return input.MustSmoothL1Loss(target, options.Reduction, options.Beta, false)
+}
+
+// HuberLoss calculates Huber loss which uses a squared term if absolute
+// element-wise error falls below delta (default=1.0) and a delta-scaled L1 term otherwise.
+func HuberLoss(input, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	return input.MustHuberLoss(target, options.Reduction, options.Delta, false)
+}
+
+// KLDivLoss calculates Kullback-Leibler divergence loss.
+//
+// - input: log-probabilities.
+// - target: probabilities or log-probabilities if `WithLossFnLogTarget(true)`.
+// NOTE. Pytorch "batchmean" reduction can be achieved with `WithLossFnReduction(ts.ReductionSum)`
+// then dividing the result by batch size.
+func KLDivLoss(input, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	return input.MustKlDiv(target, options.Reduction, options.LogTarget, false)
+}
+
+// MarginRankingLoss calculates loss given inputs x1, x2 and a label tensor y containing 1 or -1.
+//
+// loss(x1, x2, y) = max(0, -y * (x1 - x2) + margin). Default margin=0.
+func MarginRankingLoss(input1, input2, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	return ts.MustMarginRankingLoss(input1, input2, target, options.Margin, options.Reduction)
+}
+
+// HingeEmbeddingLoss calculates loss given an input tensor x and a labels tensor y containing 1 or -1.
+// Default margin=1.0.
+func HingeEmbeddingLoss(input, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Margin = 1.0
+	for _, o := range opts {
+		o(options)
+	}
+
+	return input.MustHingeEmbeddingLoss(target, options.Margin, options.Reduction, false)
+}
+
+// CosineEmbeddingLoss calculates loss given input tensors x1, x2 and a label tensor y containing 1 or -1.
+// It measures whether two inputs are similar or dissimilar using cosine similarity. Default margin=0.
+func CosineEmbeddingLoss(input1, input2, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	return ts.MustCosineEmbeddingLoss(input1, input2, target, options.Margin, options.Reduction)
+}
+
+// TripletMarginLoss calculates triplet loss given anchor, positive and negative examples.
+//
+// Options: WithLossFnMargin (default=1.0), WithLossFnP (default=2), WithLossFnEps (default=1e-6),
+// WithLossFnSwap (default=false), WithLossFnReduction.
+func TripletMarginLoss(anchor, positive, negative *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Margin = 1.0
+	for _, o := range opts {
+		o(options)
+	}
+
+	return ts.MustTripletMarginLoss(anchor, positive, negative, options.Margin, options.P, options.Eps, options.Swap, options.Reduction)
+}
+
+// MultiLabelSoftMarginLoss calculates multi-label one-versus-all loss based on max-entropy.
+//
+// - input: logits of shape [N, C]
+// - target: multi-hot labels (0 or 1) of shape [N, C]
+// Options: WithLossFnWeights (class weights), WithLossFnReduction.
+func MultiLabelSoftMarginLoss(input, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	// loss = -(y * logsigmoid(x) + (1 - y) * logsigmoid(-x))
+	posLog := input.MustLogSigmoid(false)
+	pos := target.MustMul(posLog, false)
+	posLog.MustDrop()
+	negLog := input.MustNeg(false).MustLogSigmoid(true)
+	negTarget := target.MustRsubScalar(ts.FloatScalar(1.0), false)
+	neg := negTarget.MustMul(negLog, true)
+	negLog.MustDrop()
+	loss := pos.MustAdd(neg, true).MustNeg(true)
+	neg.MustDrop()
+
+	if len(options.ClassWeights) > 0 {
+		ws := classWeights(input, options.ClassWeights)
+		loss = loss.MustMul(ws, true)
+		ws.MustDrop()
+	}
+
+	// average over classes
+	loss = loss.MustMeanDim([]int64{-1}, false, loss.DType(), true)
+
+	return reduce(loss, options.Reduction, true)
+}
+
+// PoissonNLLLoss calculates negative log likelihood loss with Poisson distribution of target.
+//
+// Options: WithLossFnLogInput (default=true), WithLossFnFull (default=false), WithLossFnEps (default=1e-8),
+// WithLossFnReduction.
+func PoissonNLLLoss(input, target *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	options.Eps = 1e-8
+	for _, o := range opts {
+		o(options)
+	}
+
+	return ts.MustPoissonNllLoss(input, target, options.LogInput, options.Full, options.Eps, options.Reduction)
+}
+
+// GaussianNLLLoss calculates Gaussian negative log likelihood loss.
+//
+// - input: expectation of the Gaussian distribution.
+// - target: sample from the Gaussian distribution.
+// - variance: variance of the Gaussian distribution. It has the same shape as input
+// or one dimension less (homoscedastic).
+// Options: WithLossFnFull (default=false), WithLossFnEps (default=1e-6), WithLossFnReduction.
+func GaussianNLLLoss(input, target, variance *ts.Tensor, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	var v *ts.Tensor
+	if variance.Dim() == input.Dim()-1 {
+		v = variance.MustUnsqueeze(-1, false).MustClampMin(ts.FloatScalar(options.Eps), true)
+	} else {
+		v = variance.MustClampMin(ts.FloatScalar(options.Eps), false)
+	}
+
+	// loss = 0.5 * (log(var) + (input - target)^2 / var)
+	logVar := v.MustLog(false)
+	diff := input.MustSub(target, false).MustSquare(true).MustDiv(v, true)
+	v.MustDrop()
+	loss := logVar.MustAdd(diff, true).MustMulScalar(ts.FloatScalar(0.5), true)
+	diff.MustDrop()
+	if options.Full {
+		loss = loss.MustAddScalar(ts.FloatScalar(0.5*math.Log(2*math.Pi)), true)
+	}
+
+	return reduce(loss, options.Reduction, true)
+}
+
+// CTCLoss calculates connectionist temporal classification loss.
+//
+// - logProbs: log-probabilities of shape [T, N, C]
+// - targets: target indices of shape [N, S] or concatenated targets of shape [sum(targetLengths)]
+// - inputLengths: lengths of inputs (each must be <= T)
+// - targetLengths: lengths of targets
+// Options: WithLossFnBlank (default=0), WithLossFnZeroInfinity (default=false), WithLossFnReduction.
+func CTCLoss(logProbs, targets *ts.Tensor, inputLengths, targetLengths []int64, opts ...LossFnOption) *ts.Tensor {
+	options := defaultLossFnOptions()
+	for _, o := range opts {
+		o(options)
+	}
+
+	return ts.MustCtcLoss(logProbs, targets, inputLengths, targetLengths, options.Blank, options.Reduction, options.ZeroInfinity)
+}
@@ -0,0 +1,141 @@
+package nn_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nullbull/gotch/nn"
+	"github.com/nullbull/gotch/ts"
+)
+
+// NOTE. Reference values are not generated by running Pytorch. Decimal values are
+// calculated in float64 from the formulas of Pytorch `torch.nn.functional` (or of the
+// referenced papers for losses Pytorch doesn't have) using the same input data.
+// Values written as expressions are derived by hand; comments give intermediate values.
+
+func assertLoss(t *testing.T, name string, loss *ts.Tensor, want float64) {
+	got := loss.Float64Values()[0]
+	if math.Abs(got-want) > 1e-5 {
+		t.Errorf("%s - Expected loss: %v\n", name, want)
+		t.Errorf("%s - Got loss: %v\n", name, got)
+	}
+}
+
+func TestCrossEntropyLoss(t *testing.T) {
+	logits := ts.MustOfSlice([]float64{1, 2, 3, 1, 0, -1}).MustView([]int64{2, 3}, true)
+	target := ts.MustOfSlice([]int64{2, 0})
+
+	assertLoss(t, "CrossEntropyLoss", nn.CrossEntropyLoss(logits, target), 0.4076059644443804)
+	assertLoss(t, "CrossEntropyLoss(labelSmoothing=0.1)", nn.CrossEntropyLoss(logits, target, nn.WithLossFnLabelSmoothing(0.1)), 0.5076059644443804)
+
+	logProbs := logits.MustLogSoftmax(-1, logits.DType(), false)
+	assertLoss(t, "NLLLoss", nn.NLLLoss(logProbs, target), 0.4076059644443804)
+
+	// Same data as [N=1, C=3, d1=2] logits with class dimension at 1.
+	ndLogits := ts.MustOfSlice([]float64{1, 1, 2, 0, 3, -1}).MustView([]int64{1, 3, 2}, true)
+	ndTarget := ts.MustOfSlice([]int64{2, 0}).MustView([]int64{1, 2}, true)
+	assertLoss(t, "CrossEntropyLoss(N-D)", nn.CrossEntropyLoss(ndLogits, ndTarget), 0.4076059644443804)
+	assertLoss(t, "CrossEntropyLoss(N-D, labelSmoothing=1e-7)", nn.CrossEntropyLoss(ndLogits, ndTarget, nn.WithLossFnLabelSmoothing(1e-7)), 0.4076059644443804)
+	assertLoss(t, "CrossEntropyLoss(N-D, labelSmoothing=0.1)", nn.CrossEntropyLoss(ndLogits, ndTarget, nn.WithLossFnLabelSmoothing(0.1)), 0.5076059644443804)
+}
+
+func TestRegressionLosses(t *testing.T) {
+	input := ts.MustOfSlice([]float64{1.0, 2.0, 3.0})
+	target := ts.MustOfSlice([]float64{1.5, 2.0, 2.0})
+
+	// hand-derived: |input - target| = [0.5, 0, 1]
+	assertLoss(t, "L1Loss", nn.L1Loss(input, target), 0.5)
+	assertLoss(t, "L1Loss(sum)", nn.L1Loss(input, target, nn.WithLossFnReduction(ts.ReductionSum)), 1.5)
+	assertLoss(t, "SmoothL1Loss", nn.SmoothL1Loss(input, target), 0.625/3)
+	assertLoss(t, "SmoothL1Loss(beta=0.5)", nn.SmoothL1Loss(input, target, nn.WithLossFnBeta(0.5)), 1.0/3)
+	assertLoss(t, "HuberLoss", nn.HuberLoss(input, target), 0.625/3)
+	assertLoss(t, "HuberLoss(delta=0.5)", nn.HuberLoss(input, target, nn.WithLossFnDelta(0.5)), 0.5/3)
+
+	variance := ts.MustOfSlice([]float64{0.5, 1.0, 2.0})
+	assertLoss(t, "GaussianNLLLoss", nn.GaussianNLLLoss(input, target, variance), 0.16666666666666666)
+	assertLoss(t, "GaussianNLLLoss(full=true)", nn.GaussianNLLLoss(input, target, variance, nn.WithLossFnFull(true)), 1.0856051998713394)
+
+	rate := ts.MustOfSlice([]float64{0.5, 1.0, 2.0})
+	count := ts.MustOfSlice([]float64{1.0, 2.0, 0.0})
+	assertLoss(t, "PoissonNLLLoss", nn.PoissonNLLLoss(rate, count), 3.0853530660299415)
+}
+
+func TestProbabilisticLosses(t *testing.T) {
+	p := ts.MustOfSlice([]float64{0.2, 0.3, 0.5})
+	q := ts.MustOfSlice([]float64{0.1, 0.6, 0.3})
+	logP := p.MustLog(false)
+	logQ := q.MustLog(false)
+	assertLoss(t, "KLDivLoss", nn.KLDivLoss(logP, q), 0.06444196771672515)
+	assertLoss(t, "KLDivLoss(logTarget=true)", nn.KLDivLoss(logP, logQ, nn.WithLossFnLogTarget(true)), 0.06444196771672515)
+
+	logits := ts.MustOfSlice([]float64{0.5, -1.0, 2.0})
+	labels := ts.MustOfSlice([]float64{1, 0, 1})
+	assertLoss(t, "BCEWithLogitsLoss(posWeight=2)", nn.BCEWithLogitsLoss(logits, labels, nn.WithLossFnPosWeight(2.0)), 0.5050905593214604)
+
+	mlLogits := ts.MustOfSlice([]float64{0.5, -1.0, 2.0, 1.0, 0.0, -2.0}).MustView([]int64{2, 3}, true)
+	mlLabels := ts.MustOfSlice([]float64{1, 0, 1, 0, 1, 0}).MustView([]int64{2, 3}, true)
+	assertLoss(t, "MultiLabelSoftMarginLoss", nn.MultiLabelSoftMarginLoss(mlLogits, mlLabels), 0.5079339269770737)
+
+	// hand-derived: T=2, N=1, C=2 with uniform probabilities. Paths decoding to [1]: (1,1), (0,1), (1,0)
+	logProbs := ts.MustOfSlice([]float64{0.5, 0.5, 0.5, 0.5}).MustView([]int64{2, 1, 2}, true).MustLog(true)
+	targets := ts.MustOfSlice([]int64{1}).MustView([]int64{1, 1}, true)
+	assertLoss(t, "CTCLoss", nn.CTCLoss(logProbs, targets, []int64{2}, []int64{1}), -math.Log(0.75))
+}
+
+func TestMarginLosses(t *testing.T) {
+	x1 := ts.MustOfSlice([]float64{1, 2, 3})
+	x2 := ts.MustOfSlice([]float64{2, 2, 2})
+	y := ts.MustOfSlice([]float64{1, -1, 1})
+	// hand-derived: -y*(x1 - x2) = [1, 0, -1]
+	assertLoss(t, "MarginRankingLoss", nn.MarginRankingLoss(x1, x2, y), 1.0/3)
+	assertLoss(t, "MarginRankingLoss(margin=1)", nn.MarginRankingLoss(x1, x2, y, nn.WithLossFnMargin(1.0)), 3.0/3)
+
+	x := ts.MustOfSlice([]float64{0.5, 2, 0.3})
+	yh := ts.MustOfSlice([]float64{1, -1, -1})
+	// hand-derived: [x, 1 - x, 1 - x] = [0.5, 0, 0.7] for y = [1, -1, -1]
+	assertLoss(t, "HingeEmbeddingLoss", nn.HingeEmbeddingLoss(x, yh), 0.4)
+
+	a := ts.MustOfSlice([]float64{1, 0, 1, 1}).MustView([]int64{2, 2}, true)
+	b := ts.MustOfSlice([]float64{0, 1, 1, 1}).MustView([]int64{2, 2}, true)
+	yc := ts.MustOfSlice([]float64{1, -1})
+	// hand-derived: cosine similarities [0, 1] for y = [1, -1]
+	assertLoss(t, "CosineEmbeddingLoss", nn.CosineEmbeddingLoss(a, b, yc), 1.0)
+
+	anchor := ts.MustOfSlice([]float64{0, 0}).MustView([]int64{1, 2}, true)
+	positive := ts.MustOfSlice([]float64{3, 4}).MustView([]int64{1, 2}, true)
+	negative := ts.MustOfSlice([]float64{0, 1}).MustView([]int64{1, 2}, true)
+	assertLoss(t, "TripletMarginLoss", nn.TripletMarginLoss(anchor, positive, negative), 4.999999599999504)
+}
+
+func TestImbalanceLosses(t *testing.T) {
+	// multi-label
+	logits := ts.MustOfSlice([]float64{0.5, -1.0, 2.0, 1.0}).MustView([]int64{4, 1}, true)
+	labels := ts.MustOfSlice([]float64{1, 0, 1, 0}).MustView([]int64{4, 1}, true)
+	assertLoss(t, "FocalLoss(multi-label)", nn.FocalLoss(logits, labels), 0.14018475850701878)
+	assertLoss(t, "LovaszLoss(multi-label)", nn.LovaszLoss(logits, labels), 0.8333333333333335)
+
+	// multi-class
+	mcLogits := ts.MustOfSlice([]float64{1, 2, 3, 1, 0, -1}).MustView([]int64{2, 3}, true)
+	mcTarget := ts.MustOfSlice([]int64{2, 0})
+	assertLoss(t, "FocalLoss(multi-class)", nn.FocalLoss(mcLogits, mcTarget), 0.04567779896788494)
+	// hand-derived: mean over no valid targets is zero rather than NaN.
+	ignored := ts.MustOfSlice([]int64{-100, -100})
+	assertLoss(t, "FocalLoss(all ignored)", nn.FocalLoss(mcLogits, ignored), 0.0)
+	assertLoss(t, "DiceLoss", nn.DiceLoss(mcLogits, mcTarget), 0.4946719488089033)
+	assertLoss(t, "TverskyLoss", nn.TverskyLoss(mcLogits, mcTarget, nn.WithLossFnAlpha(0.3), nn.WithLossFnBeta(0.7)), 0.521365373372956)
+	assertLoss(t, "LovaszLoss(multi-class)", nn.LovaszLoss(mcLogits, mcTarget), 0.3347590442251782)
+
+	// hand-derived: Tversky with alpha = beta = 0.5 is Dice
+	assertLoss(t, "TverskyLoss(alpha=beta=0.5)", nn.TverskyLoss(mcLogits, mcTarget), 0.4946719488089033)
+}
+
+func TestBoxLosses(t *testing.T) {
+	pred := ts.MustOfSlice([]float64{0, 0, 2, 2}).MustView([]int64{1, 4}, true)
+	target := ts.MustOfSlice([]float64{1, 1, 3, 3}).MustView([]int64{1, 4}, true)
+
+	// hand-derived: IoU = 1/7, enclosing area = 9, squared center distance = 2, squared diagonal = 18
+	assertLoss(t, "GIoULoss", nn.GIoULoss(pred, target), 1-1.0/7+2.0/9)
+	assertLoss(t, "DIoULoss", nn.DIoULoss(pred, target), 1-1.0/7+2.0/18)
+	// same aspect ratio, CIoU equals DIoU
+	assertLoss(t, "CIoULoss", nn.CIoULoss(pred, target), 1-1.0/7+2.0/18)
+}
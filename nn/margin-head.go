@@ -0,0 +1,212 @@
+package nn
+
+// Additive/multiplicative angular margin heads for metric learning.
+
+import (
+	"math"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+// MarginHeadConfig is a configuration for angular margin heads.
+type MarginHeadConfig struct {
+	Scale  float64 // scale (s) of output logits
+	Margin float64 // angular (ArcFace), cosine (CosFace) or multiplicative (SphereFace) margin
+	WsInit Init    // initial class-weight matrix
+}
+
+func defaultMarginHeadConfig(scale, margin float64) *MarginHeadConfig {
+	return &MarginHeadConfig{
+		Scale:  scale,
+		Margin: margin,
+		WsInit: NewKaimingUniformInit(WithKaimingNegativeSlope(math.Sqrt(5))),
+	}
+}
+
+// DefaultArcFaceConfig creates default ArcFace config with scale=64 and margin=0.5.
+func DefaultArcFaceConfig() *MarginHeadConfig {
+	return defaultMarginHeadConfig(64.0, 0.5)
+}
+
+// DefaultCosFaceConfig creates default CosFace config with scale=64 and margin=0.35.
+func DefaultCosFaceConfig() *MarginHeadConfig {
+	return defaultMarginHeadConfig(64.0, 0.35)
+}
+
+// DefaultSphereFaceConfig creates default SphereFace config with scale=30 and margin=4.
+func DefaultSphereFaceConfig() *MarginHeadConfig {
+	return defaultMarginHeadConfig(30.0, 4.0)
+}
+
+// marginHead holds class-weight matrix and computes cosine logits.
+type marginHead struct {
+	Ws     *ts.Tensor // class-weight matrix of shape [nclasses, inDim]
+	Scale  float64
+	Margin float64
+}
+
+func newMarginHead(vs *Path, inDim, nclasses int64, c *MarginHeadConfig) marginHead {
+	return marginHead{
+		Ws:     vs.MustNewVar("weight", []int64{nclasses, inDim}, c.WsInit),
+		Scale:  c.Scale,
+		Margin: c.Margin,
+	}
+}
+
+// cosine calculates cosine similarity between input features and class weights, clamped to [-1, 1].
+func (h *marginHead) cosine(xs *ts.Tensor) *ts.Tensor {
+	x := l2Normalize(xs, 1)
+	w := l2Normalize(h.Ws, 1)
+	wT := w.MustT(true)
+	cos := x.MustMatmul(wT, true).MustClamp(ts.FloatScalar(-1.0), ts.FloatScalar(1.0), true)
+	wT.MustDrop()
+
+	return cos
+}
+
+// forward calculates scaled cosine logits.
+func (h *marginHead) forward(xs *ts.Tensor) *ts.Tensor {
+	return h.cosine(xs).MustMulScalar(ts.FloatScalar(h.Scale), true)
+}
+
+// forwardWithLabels applies margin function `phiFn` to target class logits only:
+// out = s * (cos + onehot * (phi(cos) - cos)).
+func (h *marginHead) forwardWithLabels(xs, labels *ts.Tensor, phiFn func(cos *ts.Tensor) *ts.Tensor) *ts.Tensor {
+	cos := h.cosine(xs)
+	nclasses := h.Ws.MustSize()[0]
+	oneHot := labels.MustOneHot(nclasses, false).MustTotype(cos.DType(), true)
+
+	phi := phiFn(cos)
+	delta := phi.MustSub(cos, true).MustMul(oneHot, true)
+	oneHot.MustDrop()
+
+	out := cos.MustAdd(delta, true).MustMulScalar(ts.FloatScalar(h.Scale), true)
+	delta.MustDrop()
+
+	return out
+}
+
+// ArcFace is additive angular margin head (https://arxiv.org/abs/1801.07698).
+//
+// Target logit is s * cos(theta + m).
+type ArcFace struct {
+	marginHead
+}
+
+// NewArcFace creates ArcFace head registering class-weight matrix of shape [nclasses, inDim]
+// as "weight" at the given path.
+func NewArcFace(vs *Path, inDim, nclasses int64, c *MarginHeadConfig) *ArcFace {
+	return &ArcFace{newMarginHead(vs, inDim, nclasses, c)}
+}
+
+// Forward returns scaled cosine logits without margin, i.e. for inference.
+//
+// Implement ts.Module interface.
+func (h *ArcFace) Forward(xs *ts.Tensor) *ts.Tensor {
+	xs = preForward(h, xs)
+	out := h.forward(xs)
+	return postForward(h, xs, out)
+}
+
+// ForwardWithLabels returns scaled logits with angular margin applied to target classes.
+// Result can be fed to CrossEntropyLoss.
+func (h *ArcFace) ForwardWithLabels(xs, labels *ts.Tensor) *ts.Tensor {
+	cosM := math.Cos(h.Margin)
+	sinM := math.Sin(h.Margin)
+	// when theta + m > pi, cos(theta + m) is no longer monotonic. Fallback to CosFace-like penalty.
+	th := math.Cos(math.Pi - h.Margin)
+	mm := math.Sin(math.Pi-h.Margin) * h.Margin
+
+	return h.forwardWithLabels(xs, labels, func(cos *ts.Tensor) *ts.Tensor {
+		// phi = cos*cos(m) - sin*sin(m)
+		sin := cos.MustSquare(false).MustRsubScalar(ts.FloatScalar(1.0), true).MustClampMin(ts.FloatScalar(0.0), true).MustSqrt(true)
+		sinTerm := sin.MustMulScalar(ts.FloatScalar(sinM), true)
+		phi := cos.MustMulScalar(ts.FloatScalar(cosM), false).MustSub(sinTerm, true)
+		sinTerm.MustDrop()
+
+		fallback := cos.MustSubScalar(ts.FloatScalar(mm), false)
+		cond := cos.MustGt(ts.FloatScalar(th), false)
+		out := phi.MustWhereSelf(cond, fallback, true)
+		cond.MustDrop()
+		fallback.MustDrop()
+
+		return out
+	})
+}
+
+// CosFace is large margin cosine head (https://arxiv.org/abs/1801.09414).
+//
+// Target logit is s * (cos(theta) - m).
+type CosFace struct {
+	marginHead
+}
+
+// NewCosFace creates CosFace head registering class-weight matrix of shape [nclasses, inDim]
+// as "weight" at the given path.
+func NewCosFace(vs *Path, inDim, nclasses int64, c *MarginHeadConfig) *CosFace {
+	return &CosFace{newMarginHead(vs, inDim, nclasses, c)}
+}
+
+// Forward returns scaled cosine logits without margin, i.e. for inference.
+//
+// Implement ts.Module interface.
+func (h *CosFace) Forward(xs *ts.Tensor) *ts.Tensor {
+	xs = preForward(h, xs)
+	out := h.forward(xs)
+	return postForward(h, xs, out)
+}
+
+// ForwardWithLabels returns scaled logits with cosine margin applied to target classes.
+// Result can be fed to CrossEntropyLoss.
+func (h *CosFace) ForwardWithLabels(xs, labels *ts.Tensor) *ts.Tensor {
+	return h.forwardWithLabels(xs, labels, func(cos *ts.Tensor) *ts.Tensor {
+		return cos.MustSubScalar(ts.FloatScalar(h.Margin), false)
+	})
+}
+
+// SphereFace is multiplicative angular margin head (https://arxiv.org/abs/1704.08063).
+//
+// Target logit is s * psi(theta) where psi(theta) = (-1)^k * cos(m*theta) - 2k
+// and k = floor(m*theta/pi) making it monotonically decreasing in [0, pi].
+type SphereFace struct {
+	marginHead
+}
+
+// NewSphereFace creates SphereFace head registering class-weight matrix of shape [nclasses, inDim]
+// as "weight" at the given path.
+func NewSphereFace(vs *Path, inDim, nclasses int64, c *MarginHeadConfig) *SphereFace {
+	return &SphereFace{newMarginHead(vs, inDim, nclasses, c)}
+}
+
+// Forward returns scaled cosine logits without margin, i.e. for inference.
+//
+// Implement ts.Module interface.
+func (h *SphereFace) Forward(xs *ts.Tensor) *ts.Tensor {
+	xs = preForward(h, xs)
+	out := h.forward(xs)
+	return postForward(h, xs, out)
+}
+
+// ForwardWithLabels returns scaled logits with multiplicative angular margin applied to
+// target classes. Result can be fed to CrossEntropyLoss.
+func (h *SphereFace) ForwardWithLabels(xs, labels *ts.Tensor) *ts.Tensor {
+	return h.forwardWithLabels(xs, labels, func(cos *ts.Tensor) *ts.Tensor {
+		// NOTE. clamp slightly inside [-1, 1] as gradient of acos is infinite at boundaries.
+		theta := cos.MustClamp(ts.FloatScalar(-1.0+1e-7), ts.FloatScalar(1.0-1e-7), false).MustAcos(true)
+		mTheta := theta.MustMulScalar(ts.FloatScalar(h.Margin), true)
+
+		var k *ts.Tensor
+		ts.NoGrad(func() {
+			k = mTheta.MustDivScalar(ts.FloatScalar(math.Pi), false).MustFloor(true).MustDetach(true)
+		})
+		// (-1)^k = 1 - 2*(k mod 2)
+		sign := k.MustRemainder(ts.FloatScalar(2.0), false).MustMulScalar(ts.FloatScalar(-2.0), true).MustAddScalar(ts.FloatScalar(1.0), true)
+		psi := mTheta.MustCos(true).MustMul(sign, true)
+		sign.MustDrop()
+		k2 := k.MustMulScalar(ts.FloatScalar(2.0), true)
+		psi = psi.MustSub(k2, true)
+		k2.MustDrop()
+
+		return psi
+	})
+}
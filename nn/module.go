@@ -0,0 +1,297 @@
+package nn
+
+// Module introspection: names, children and parameters of a module tree.
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+// ModuleInfo is an optional interface that a module (ts.Module or ts.ModuleT)
+// can implement to expose its structure.
+//
+// Built-in layers, containers (Sequential, SequentialT, ModuleList, ModuleDict, Func, FuncT,
+// Parallel, Concat, Residual) and `vision` models implement it.
+type ModuleInfo interface {
+	// Name returns module type name, e.g. "Conv2D".
+	Name() string
+
+	// Children returns direct submodules in forward order.
+	Children() []NamedModule
+
+	// Parameters returns parameters owned directly by this module, excluding
+	// parameters of its children.
+	Parameters() []ts.NamedTensor
+}
+
+// NamedModule is a submodule with its name relative to the parent module.
+type NamedModule struct {
+	Name   string
+	Module interface{} // ts.Module or ts.ModuleT
+}
+
+// ModuleName returns name of a module. If module does not implement ModuleInfo,
+// its Go type name is used.
+func ModuleName(m interface{}) string {
+	if mi, ok := m.(ModuleInfo); ok {
+		return mi.Name()
+	}
+
+	t := reflect.TypeOf(m)
+	if t == nil {
+		return "<nil>"
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return t.String()
+	}
+
+	return t.Name()
+}
+
+// ModuleChildren returns direct submodules of a module. It returns nil if module does
+// not implement ModuleInfo.
+func ModuleChildren(m interface{}) []NamedModule {
+	if mi, ok := m.(ModuleInfo); ok {
+		return mi.Children()
+	}
+
+	return nil
+}
+
+// ModuleParameters returns parameters owned directly by a module. It returns nil if module does
+// not implement ModuleInfo.
+func ModuleParameters(m interface{}) []ts.NamedTensor {
+	if mi, ok := m.(ModuleInfo); ok {
+		return mi.Parameters()
+	}
+
+	return nil
+}
+
+// Walk traverses module tree in pre-order calling `fn` for each module with its
+// dot-separated path from the root. Root module has empty path.
+func Walk(m interface{}, fn func(path string, m interface{})) {
+	walk("", m, fn)
+}
+
+func walk(path string, m interface{}, fn func(path string, m interface{})) {
+	fn(path, m)
+	for _, c := range ModuleChildren(m) {
+		walk(joinPath(path, c.Name), c.Module, fn)
+	}
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%v%v%v", prefix, SEP, name)
+}
+
+// NamedParameters returns all parameters of a module and its submodules with
+// dot-separated names. Parameters shared between modules are returned once.
+func NamedParameters(m interface{}) []ts.NamedTensor {
+	var params []ts.NamedTensor
+	seen := make(map[*ts.Tensor]bool)
+	Walk(m, func(path string, mod interface{}) {
+		for _, p := range ModuleParameters(mod) {
+			if seen[p.Tensor] {
+				continue
+			}
+			seen[p.Tensor] = true
+			params = append(params, ts.NamedTensor{Name: joinPath(path, p.Name), Tensor: p.Tensor})
+		}
+	})
+
+	return params
+}
+
+// NumParameters returns total number of parameter elements of a module and its submodules.
+func NumParameters(m interface{}) int64 {
+	var n int64
+	for _, p := range NamedParameters(m) {
+		n += int64(p.Tensor.Numel())
+	}
+
+	return n
+}
+
+// namedParams builds named parameters list skipping nil and undefined tensors.
+func namedParams(kv ...interface{}) []ts.NamedTensor {
+	var params []ts.NamedTensor
+	for i := 0; i+1 < len(kv); i += 2 {
+		x, _ := kv[i+1].(*ts.Tensor)
+		if x == nil || !x.MustDefined() {
+			continue
+		}
+		params = append(params, ts.NamedTensor{Name: kv[i].(string), Tensor: x})
+	}
+
+	return params
+}
+
+// Implement ModuleInfo for built-in layers:
+// =========================================
+
+var (
+	_ ModuleInfo = new(Linear)
+	_ ModuleInfo = new(Conv1D)
+	_ ModuleInfo = new(Conv2D)
+	_ ModuleInfo = new(Conv3D)
+	_ ModuleInfo = new(ConvTranspose1D)
+	_ ModuleInfo = new(ConvTranspose2D)
+	_ ModuleInfo = new(ConvTranspose3D)
+	_ ModuleInfo = new(BatchNorm)
+	_ ModuleInfo = new(LayerNorm)
+	_ ModuleInfo = new(Embedding)
+	_ ModuleInfo = new(Dropout)
+	_ ModuleInfo = new(Identity)
+	_ ModuleInfo = new(MaxPool2D)
+	_ ModuleInfo = new(LSTM)
+	_ ModuleInfo = new(GRU)
+	_ ModuleInfo = new(ArcFace)
+	_ ModuleInfo = new(CosFace)
+	_ ModuleInfo = new(SphereFace)
+	_ ModuleInfo = new(Sequential)
+	_ ModuleInfo = new(SequentialT)
+	_ ModuleInfo = Func{}
+	_ ModuleInfo = FuncT{}
+	_ ModuleInfo = new(ModuleList)
+	_ ModuleInfo = new(ModuleDict)
+	_ ModuleInfo = new(Parallel)
+	_ ModuleInfo = new(Concat)
+	_ ModuleInfo = new(Residual)
+)
+
+func (l *Linear) Name() string            { return "Linear" }
+func (l *Linear) Children() []NamedModule { return nil }
+func (l *Linear) Parameters() []ts.NamedTensor {
+	return namedParams("weight", l.Ws, "bias", l.Bs)
+}
+
+func (c *Conv1D) Name() string            { return "Conv1D" }
+func (c *Conv1D) Children() []NamedModule { return nil }
+func (c *Conv1D) Parameters() []ts.NamedTensor {
+	return namedParams("weight", c.Ws, "bias", c.Bs)
+}
+
+func (c *Conv2D) Name() string            { return "Conv2D" }
+func (c *Conv2D) Children() []NamedModule { return nil }
+func (c *Conv2D) Parameters() []ts.NamedTensor {
+	return namedParams("weight", c.Ws, "bias", c.Bs)
+}
+
+func (c *Conv3D) Name() string            { return "Conv3D" }
+func (c *Conv3D) Children() []NamedModule { return nil }
+func (c *Conv3D) Parameters() []ts.NamedTensor {
+	return namedParams("weight", c.Ws, "bias", c.Bs)
+}
+
+func (c *ConvTranspose1D) Name() string            { return "ConvTranspose1D" }
+func (c *ConvTranspose1D) Children() []NamedModule { return nil }
+func (c *ConvTranspose1D) Parameters() []ts.NamedTensor {
+	return namedParams("weight", c.Ws, "bias", c.Bs)
+}
+
+func (c *ConvTranspose2D) Name() string            { return "ConvTranspose2D" }
+func (c *ConvTranspose2D) Children() []NamedModule { return nil }
+func (c *ConvTranspose2D) Parameters() []ts.NamedTensor {
+	return namedParams("weight", c.Ws, "bias", c.Bs)
+}
+
+func (c *ConvTranspose3D) Name() string            { return "ConvTranspose3D" }
+func (c *ConvTranspose3D) Children() []NamedModule { return nil }
+func (c *ConvTranspose3D) Parameters() []ts.NamedTensor {
+	return namedParams("weight", c.Ws, "bias", c.Bs)
+}
+
+func (bn *BatchNorm) Name() string            { return fmt.Sprintf("BatchNorm%dD", bn.Nd) }
+func (bn *BatchNorm) Children() []NamedModule { return nil }
+
+// Parameters returns weight and bias. Running statistics are buffers, not parameters.
+func (bn *BatchNorm) Parameters() []ts.NamedTensor {
+	return namedParams("weight", bn.Ws, "bias", bn.Bs)
+}
+
+func (ln *LayerNorm) Name() string            { return "LayerNorm" }
+func (ln *LayerNorm) Children() []NamedModule { return nil }
+func (ln *LayerNorm) Parameters() []ts.NamedTensor {
+	return namedParams(ln.Config.WsName, ln.Ws, ln.Config.BsName, ln.Bs)
+}
+
+func (e *Embedding) Name() string            { return "Embedding" }
+func (e *Embedding) Children() []NamedModule { return nil }
+func (e *Embedding) Parameters() []ts.NamedTensor {
+	return namedParams("weight", e.Ws)
+}
+
+func (d *Dropout) Name() string                 { return "Dropout" }
+func (d *Dropout) Children() []NamedModule      { return nil }
+func (d *Dropout) Parameters() []ts.NamedTensor { return nil }
+
+func (m *Identity) Name() string                 { return "Identity" }
+func (m *Identity) Children() []NamedModule      { return nil }
+func (m *Identity) Parameters() []ts.NamedTensor { return nil }
+
+func (m *MaxPool2D) Name() string                 { return "MaxPool2D" }
+func (m *MaxPool2D) Children() []NamedModule      { return nil }
+func (m *MaxPool2D) Parameters() []ts.NamedTensor { return nil }
+
+// rnnParams names flat weights of a RNN layer in Pytorch order and naming.
+func rnnParams(flatWeights []*ts.Tensor, cfg *RNNConfig) []ts.NamedTensor {
+	suffixes := []string{""}
+	if cfg.Bidirectional {
+		suffixes = append(suffixes, "_reverse")
+	}
+
+	var kv []interface{}
+	i := 0
+	for l := 0; l < int(cfg.NumLayers); l++ {
+		for _, s := range suffixes {
+			for _, base := range []string{"weight_ih", "weight_hh", "bias_ih", "bias_hh"} {
+				if i < len(flatWeights) {
+					kv = append(kv, fmt.Sprintf("%s_l%d%s", base, l, s), flatWeights[i])
+				}
+				i++
+			}
+		}
+	}
+
+	return namedParams(kv...)
+}
+
+func (l *LSTM) Name() string            { return "LSTM" }
+func (l *LSTM) Children() []NamedModule { return nil }
+func (l *LSTM) Parameters() []ts.NamedTensor {
+	return rnnParams(l.flatWeights, l.config)
+}
+
+func (g *GRU) Name() string            { return "GRU" }
+func (g *GRU) Children() []NamedModule { return nil }
+func (g *GRU) Parameters() []ts.NamedTensor {
+	return rnnParams(g.flatWeights, g.config)
+}
+
+func (h *ArcFace) Name() string            { return "ArcFace" }
+func (h *ArcFace) Children() []NamedModule { return nil }
+func (h *ArcFace) Parameters() []ts.NamedTensor {
+	return namedParams("weight", h.Ws)
+}
+
+func (h *CosFace) Name() string            { return "CosFace" }
+func (h *CosFace) Children() []NamedModule { return nil }
+func (h *CosFace) Parameters() []ts.NamedTensor {
+	return namedParams("weight", h.Ws)
+}
+
+func (h *SphereFace) Name() string            { return "SphereFace" }
+func (h *SphereFace) Children() []NamedModule { return nil }
+func (h *SphereFace) Parameters() []ts.NamedTensor {
+	return namedParams("weight", h.Ws)
+}
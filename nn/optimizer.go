@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"sort"
+	"strings"
 
 	"github.com/nullbull/gotch/ts"
 )
@@ -18,6 +20,8 @@ type Optimizer struct {
 	variablesInOptimizer map[string]struct{}
 	config               interface{}
 	stepCount            int
+	nonFinitePolicy      NonFiniteGradPolicy
+	skippedSteps         int
 }
 
 // OptimizerConfig defines Optimizer configurations. These configs can be used to build optimizer.
@@ -277,6 +281,23 @@ func (opt *Optimizer) ClipGradValue(max float64) {
 
 // Step performs an optimization step, updating the tracked tensors based on their gradients.
 func (opt *Optimizer) Step() error {
+	skip, err := opt.checkGrads()
+	if err != nil || skip {
+		return err
+	}
+
+	err = opt.opt.Step()
+	if err != nil {
+		err = fmt.Errorf("Optimizer.Step() failed: %w\n", err)
+		return err
+	}
+	opt.stepCount += 1
+
+	return nil
+}
+
+// step performs an optimization step without checking gradients.
+func (opt *Optimizer) step() error {
 	err := opt.opt.Step()
 	if err != nil {
 		err = fmt.Errorf("Optimizer.Step() failed: %w\n", err)
@@ -313,7 +334,15 @@ func (opt *Optimizer) BackwardStep(loss *ts.Tensor) error {
 		return err
 	}
 
-	loss.MustBackward()
+	err = loss.Backward()
+	if err != nil {
+		err = fmt.Errorf("Optimizer.BackwardStep() failed: %w\n", err)
+		return err
+	}
+	skip, err := opt.checkGrads()
+	if err != nil || skip {
+		return err
+	}
 	err = opt.opt.Step()
 	if err != nil {
 		err = fmt.Errorf("Optimizer.BackwardStep() failed: %w\n", err)
@@ -340,7 +369,15 @@ func (opt *Optimizer) BackwardStepClip(loss *ts.Tensor, max float64) error {
 		err = fmt.Errorf("Optimizer.BackwardStepClip() failed: %w\n", err)
 		return err
 	}
-	loss.MustBackward()
+	err = loss.Backward()
+	if err != nil {
+		err = fmt.Errorf("Optimizer.BackwardStepClip() failed: %w\n", err)
+		return err
+	}
+	skip, err := opt.checkGrads()
+	if err != nil || skip {
+		return err
+	}
 	opt.ClipGradValue(max)
 	err = opt.opt.Step()
 	if err != nil {
@@ -489,13 +526,19 @@ func (opt *Optimizer) BackwardStepClipNorm(loss *ts.Tensor, max float64, opts ..
 		return err
 	}
 
+	// NOTE. check gradients before clipping as non-finite norm spreads to all gradients.
+	skip, err := opt.checkGrads()
+	if err != nil || skip {
+		return err
+	}
+
 	err = opt.ClipGradNorm(max, opts...)
 	if err != nil {
 		err := fmt.Errorf("Optimizer.BackwardStepClipNorm() failed: %w\n", err)
 		return err
 	}
 
-	err = opt.Step()
+	err = opt.step()
 	if err != nil {
 		err := fmt.Errorf("Optimizer.BackwardStepClipNorm() failed: %w\n", err)
 		return err
@@ -514,6 +557,93 @@ func (opt *Optimizer) MustBackwardStepClipNorm(loss *ts.Tensor, max float64, opt
 	}
 }
 
+// NonFiniteGradPolicy specifies what optimizer does when gradients of variables have
+// NaN or infinite values before an optimization step.
+type NonFiniteGradPolicy int
+
+const (
+	NonFiniteGradIgnore      NonFiniteGradPolicy = iota // no check (default)
+	NonFiniteGradSkip                                   // skip the step
+	NonFiniteGradZero                                   // replace non-finite gradient values with zero then step
+	NonFiniteGradReturnError                            // return *NonFiniteGradError
+)
+
+// NonFiniteGradError is returned by optimizer step when gradients of variables have
+// NaN or infinite values.
+type NonFiniteGradError struct {
+	// Names of variables in VarStore with non-finite gradients.
+	Names []string
+}
+
+func (e *NonFiniteGradError) Error() string {
+	return fmt.Sprintf("Non-finite gradients of variables: %s", strings.Join(e.Names, ", "))
+}
+
+// SetNonFiniteGradPolicy sets what optimizer does when gradients have NaN or infinite
+// values before an optimization step in `Step()` or `BackwardStep*()`.
+//
+// NOTE. Checking gradients synchronizes with device once per variable.
+func (opt *Optimizer) SetNonFiniteGradPolicy(p NonFiniteGradPolicy) {
+	opt.nonFinitePolicy = p
+}
+
+// SkippedSteps returns number of steps skipped due to non-finite gradients.
+func (opt *Optimizer) SkippedSteps() int {
+	return opt.skippedSteps
+}
+
+// NonFiniteGrads returns names of trainable variables of the optimizer with NaN or infinite gradients.
+func (opt *Optimizer) NonFiniteGrads() []string {
+	opt.varstore.Lock()
+	defer opt.varstore.Unlock()
+
+	var names []string
+	for name := range opt.variablesInOptimizer {
+		v, ok := opt.varstore.vars[name]
+		if !ok || !v.Trainable {
+			continue
+		}
+		grad := v.Tensor.MustGrad(false)
+		if !grad.MustDefined() {
+			continue
+		}
+		finite := grad.MustIsfinite(false).MustAll(true).Int64Values(true)[0]
+		if finite == 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// checkGrads applies non-finite gradient policy and returns whether to skip the step.
+func (opt *Optimizer) checkGrads() (bool, error) {
+	if opt.nonFinitePolicy == NonFiniteGradIgnore {
+		return false, nil
+	}
+
+	names := opt.NonFiniteGrads()
+	if len(names) == 0 {
+		return false, nil
+	}
+
+	switch opt.nonFinitePolicy {
+	case NonFiniteGradSkip:
+		opt.skippedSteps++
+		return true, nil
+	case NonFiniteGradZero:
+		opt.varstore.Lock()
+		for _, name := range names {
+			opt.varstore.vars[name].Tensor.MustGrad(false).MustNanToNum_([]float64{0}, []float64{0}, []float64{0})
+		}
+		opt.varstore.Unlock()
+		return false, nil
+	default:
+		return false, &NonFiniteGradError{Names: names}
+	}
+}
+
 // SetLR sets the optimizer learning rate.
 //
 // NOTE. it sets a SINGLE value of learning rate for all parameter groups.
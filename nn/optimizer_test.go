@@ -1,7 +1,10 @@
 package nn_test
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"testing"
 
 	"github.com/nullbull/gotch"
@@ -74,3 +77,90 @@ func TestClipGradNorm(t *testing.T) {
 func TestClipGradValue(t *testing.T) {
 	// TODO
 }
+
+func TestNonFiniteGradPolicy(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	cfg := &nn.LinearConfig{
+		WsInit: nn.NewConstInit(1.0),
+		BsInit: nn.NewConstInit(1.0),
+		Bias:   true,
+	}
+	model := nn.NewLinear(vs.Root(), 1, 1, cfg)
+	opt, err := nn.DefaultSGDConfig().Build(vs, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := ts.MustOfSlice([]float32{1.0}).MustView([]int64{1, 1}, true)
+	nanLoss := func() *ts.Tensor {
+		return model.Forward(x).MustMulScalar(ts.FloatScalar(math.NaN()), true).MustSum(gotch.Float, true)
+	}
+
+	opt.SetNonFiniteGradPolicy(nn.NonFiniteGradReturnError)
+	err = opt.BackwardStep(nanLoss())
+	var gradErr *nn.NonFiniteGradError
+	if !errors.As(err, &gradErr) || !reflect.DeepEqual([]string{"bias", "weight"}, gradErr.Names) {
+		t.Errorf("Expected NonFiniteGradError naming bias and weight, got %v\n", err)
+	}
+
+	opt.SetNonFiniteGradPolicy(nn.NonFiniteGradSkip)
+	if err := opt.BackwardStep(nanLoss()); err != nil {
+		t.Fatal(err)
+	}
+	if opt.SkippedSteps() != 1 || model.Ws.Float64Values()[0] != 1.0 {
+		t.Errorf("Expected step skipped with weight unchanged, got %v skipped steps and weight %v\n", opt.SkippedSteps(), model.Ws.Float64Values()[0])
+	}
+
+	opt.SetNonFiniteGradPolicy(nn.NonFiniteGradZero)
+	if err := opt.BackwardStep(nanLoss()); err != nil {
+		t.Fatal(err)
+	}
+	if got := opt.NonFiniteGrads(); len(got) != 0 {
+		t.Errorf("Expected non-finite gradients zeroed, got %v\n", got)
+	}
+	if w := model.Ws.Float64Values()[0]; w != 1.0 {
+		t.Errorf("Expected weight unchanged by zero gradient, got %v\n", w)
+	}
+
+	// Only non-finite values are replaced with zero.
+	vs2 := nn.NewVarStore(gotch.CPU)
+	model2 := nn.NewLinear(vs2.Root(), 1, 2, cfg)
+	opt2, err := nn.DefaultSGDConfig().Build(vs2, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt2.SetNonFiniteGradPolicy(nn.NonFiniteGradZero)
+	scale := ts.MustOfSlice([]float32{float32(math.NaN()), 1.0})
+	loss := model2.Forward(x).MustMul(scale, true).MustSum(gotch.Float, true)
+	if err := opt2.BackwardStep(loss); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := model2.Ws.Float64Values(), []float64{1.0, 0.9}; math.Abs(got[0]-want[0]) > 1e-6 || math.Abs(got[1]-want[1]) > 1e-6 {
+		t.Errorf("Expected weight %v, got %v\n", want, got)
+	}
+
+	// Variables not in optimizer are not checked.
+	extra := vs2.Root().MustOnes("extra", []int64{1})
+	extra.MustMulScalar(ts.FloatScalar(math.NaN()), false).MustSum(gotch.Float, true).MustBackward()
+	if got := opt2.NonFiniteGrads(); len(got) != 0 {
+		t.Errorf("Expected no non-finite gradients of optimizer variables, got %v\n", got)
+	}
+}
+
+func TestBackwardStepAnomaly(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	w := vs.Root().MustOnes("w", []int64{1})
+	opt, err := nn.DefaultSGDConfig().Build(vs, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts.WithDetectAnomaly(func() {
+		loss := w.MustNeg(false).MustSqrt(true).MustSum(gotch.Float, true)
+		err := opt.BackwardStep(loss)
+		var anomaly *ts.AnomalyError
+		if !errors.As(err, &anomaly) {
+			t.Errorf("Expected AnomalyError, got %v\n", err)
+		}
+	})
+}
@@ -21,7 +21,9 @@ func NewDropout(p float64) *Dropout {
 
 // ForwardT implements ModuleT for Dropout layer.
 func (d *Dropout) ForwardT(input *ts.Tensor, train bool) (retVal *ts.Tensor) {
-	return ts.MustDropout(input, d.dropoutProb, train)
+	input = preForward(d, input)
+	retVal = ts.MustDropout(input, d.dropoutProb, train)
+	return postForward(d, input, retVal)
 }
 
 // Parameter:
@@ -72,7 +74,9 @@ func (m *Identity) Forward(x *ts.Tensor) *ts.Tensor {
 	if x == nil {
 		return nil
 	}
-	return x.MustShallowClone()
+	x = preForward(m, x)
+	out := x.MustShallowClone()
+	return postForward(m, x, out)
 }
 
 func NewIdentity() *Identity {
@@ -147,5 +151,7 @@ func NewMaxPool2D(kernelSize []int64, opts ...MaxPool2DOpt) *MaxPool2D {
 }
 
 func (m *MaxPool2D) Forward(x *ts.Tensor) *ts.Tensor {
-	return x.MustMaxPool2d(m.Kernel, m.Stride, m.Padding, m.Dilation, m.CeilMode, false)
+	x = preForward(m, x)
+	out := x.MustMaxPool2d(m.Kernel, m.Stride, m.Padding, m.Dilation, m.CeilMode, false)
+	return postForward(m, x, out)
 }
@@ -3,6 +3,9 @@ package nn
 // A sequential layer used to chain multiple layers and closures.
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/nullbull/gotch"
 	"github.com/nullbull/gotch/ts"
 )
@@ -10,6 +13,7 @@ import (
 // Sequential is a layer (container) that combines multiple other layers.
 type Sequential struct {
 	layers []ts.Module
+	names  []string // optional layer names. Empty if unnamed.
 }
 
 // Seq creates a new empty sequential layer
@@ -34,6 +38,126 @@ func (s *Sequential) IsEmpty() (retVal bool) {
 func (s *Sequential) Add(l ts.Module) {
 
 	s.layers = append(s.layers, l)
+	s.names = append(s.names, "")
+}
+
+// AddNamed appends a named layer after all the current layers.
+// It returns an error if name is already used or looks like a layer index.
+func (s *Sequential) AddNamed(name string, l ts.Module) error {
+	if err := checkLayerName(s.names, name); err != nil {
+		return fmt.Errorf("Sequential.AddNamed() failed: %w", err)
+	}
+
+	s.layers = append(s.layers, l)
+	s.names = append(s.names, name)
+
+	return nil
+}
+
+// Get returns layer at index.
+func (s *Sequential) Get(idx int) ts.Module {
+	return s.layers[idx]
+}
+
+// Index returns index of a layer given its name or its index as string. It returns -1 if not found.
+func (s *Sequential) Index(name string) int {
+	return layerIndex(s.names, name)
+}
+
+// Insert inserts a layer at index. Layers from index onward are shifted.
+func (s *Sequential) Insert(idx int, l ts.Module) error {
+	return s.InsertNamed(idx, "", l)
+}
+
+// InsertNamed inserts a named layer at index. Layers from index onward are shifted.
+// It returns an error if name is already used or looks like a layer index.
+func (s *Sequential) InsertNamed(idx int, name string, l ts.Module) error {
+	if idx < 0 || idx > len(s.layers) {
+		return fmt.Errorf("Sequential.Insert() failed: index %d out of range [0, %d].", idx, len(s.layers))
+	}
+	if err := checkLayerName(s.names, name); err != nil {
+		return fmt.Errorf("Sequential.InsertNamed() failed: %w", err)
+	}
+
+	s.layers = append(s.layers[:idx], append([]ts.Module{l}, s.layers[idx:]...)...)
+	s.names = append(s.names[:idx], append([]string{name}, s.names[idx:]...)...)
+
+	return nil
+}
+
+// InsertBefore inserts a layer before the layer with given name.
+func (s *Sequential) InsertBefore(name string, l ts.Module) error {
+	idx := s.Index(name)
+	if idx < 0 {
+		return fmt.Errorf("Sequential.InsertBefore() failed: layer %q not found.", name)
+	}
+
+	return s.Insert(idx, l)
+}
+
+// Replace replaces layer at index. Layer name is kept.
+func (s *Sequential) Replace(idx int, l ts.Module) error {
+	if idx < 0 || idx >= len(s.layers) {
+		return fmt.Errorf("Sequential.Replace() failed: index %d out of range [0, %d).", idx, len(s.layers))
+	}
+	s.layers[idx] = l
+
+	return nil
+}
+
+// ReplaceByName replaces layer with given name.
+func (s *Sequential) ReplaceByName(name string, l ts.Module) error {
+	idx := s.Index(name)
+	if idx < 0 {
+		return fmt.Errorf("Sequential.ReplaceByName() failed: layer %q not found.", name)
+	}
+
+	return s.Replace(idx, l)
+}
+
+// Remove removes and returns layer at index.
+func (s *Sequential) Remove(idx int) (ts.Module, error) {
+	if idx < 0 || idx >= len(s.layers) {
+		return nil, fmt.Errorf("Sequential.Remove() failed: index %d out of range [0, %d).", idx, len(s.layers))
+	}
+	l := s.layers[idx]
+	s.layers = append(s.layers[:idx], s.layers[idx+1:]...)
+	s.names = append(s.names[:idx], s.names[idx+1:]...)
+
+	return l, nil
+}
+
+// RemoveByName removes and returns layer with given name.
+func (s *Sequential) RemoveByName(name string) (ts.Module, error) {
+	idx := s.Index(name)
+	if idx < 0 {
+		return nil, fmt.Errorf("Sequential.RemoveByName() failed: layer %q not found.", name)
+	}
+
+	return s.Remove(idx)
+}
+
+// Slice returns a new Sequential with layers in range [start, end). Layers are shared, not copied.
+func (s *Sequential) Slice(start, end int) (*Sequential, error) {
+	if start < 0 || end > len(s.layers) || start > end {
+		return nil, fmt.Errorf("Sequential.Slice() failed: invalid range [%d, %d) for %d layers.", start, end, len(s.layers))
+	}
+
+	return &Sequential{
+		layers: append([]ts.Module{}, s.layers[start:end]...),
+		names:  append([]string{}, s.names[start:end]...),
+	}, nil
+}
+
+// SliceByName returns a new Sequential with layers from layer named `start` up to
+// but excluding layer named `end`. Empty `end` means to the last layer.
+func (s *Sequential) SliceByName(start, end string) (*Sequential, error) {
+	i, j, err := sliceRange(s.names, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("Sequential.SliceByName() failed: %w", err)
+	}
+
+	return s.Slice(i, j)
 }
 
 // AddFn appends a closure after all the current layers.
@@ -58,7 +182,7 @@ func (s *Sequential) ForwardAll(xs *ts.Tensor, opts ...uint8) (retVal []*ts.Tens
 	}
 
 	for i := 0; i < int(n); i++ {
-		retVal = append(retVal, s.layers[i].Forward(xs))
+		retVal = append(retVal, forwardLayer(s.layers[i], xs))
 	}
 
 	return retVal
@@ -71,29 +195,53 @@ func WithUint8(n uint8) func() uint8 {
 	}
 }
 
+// Implement ModuleInfo interface for Sequential:
+// ==============================================
+
+// Name returns module name.
+func (s *Sequential) Name() string { return "Sequential" }
+
+// Children returns sub-layers named by their names or indices if unnamed.
+func (s *Sequential) Children() []NamedModule {
+	children := make([]NamedModule, len(s.layers))
+	for i, l := range s.layers {
+		children[i] = NamedModule{Name: layerName(s.names, i), Module: l}
+	}
+
+	return children
+}
+
+// Parameters returns nil as Sequential does not own any parameters.
+func (s *Sequential) Parameters() []ts.NamedTensor { return nil }
+
 // Implement Module interface for Sequential:
 // ==========================================
 
 // Forward implements Module interface for Sequential
 func (s *Sequential) Forward(xs *ts.Tensor) (retVal *ts.Tensor) {
+	xs = preForward(s, xs)
+	return runForwardHooks(s, xs, s.forward(xs))
+}
+
+func (s *Sequential) forward(xs *ts.Tensor) (retVal *ts.Tensor) {
 	if s.IsEmpty() {
 		return xs.MustShallowClone()
 	}
 
 	if len(s.layers) == 1 {
-		return s.layers[0].Forward(xs)
+		return forwardLayer(s.layers[0], xs)
 	}
 
 	// forward sequentially
 	outs := make([]*ts.Tensor, len(s.layers))
 	for i := 0; i < len(s.layers); i++ {
 		if i == 0 {
-			outs[0] = s.layers[i].Forward(xs)
+			outs[0] = forwardLayer(s.layers[i], xs)
 			defer outs[0].MustDrop()
 		} else if i == len(s.layers)-1 {
-			return s.layers[i].Forward(outs[i-1])
+			return forwardLayer(s.layers[i], outs[i-1])
 		} else {
-			outs[i] = s.layers[i].Forward(outs[i-1])
+			outs[i] = forwardLayer(s.layers[i], outs[i-1])
 			defer outs[i].MustDrop()
 		}
 	}
@@ -104,6 +252,7 @@ func (s *Sequential) Forward(xs *ts.Tensor) (retVal *ts.Tensor) {
 // SequentialT is a sequential layer combining new layers with support for a training mode.
 type SequentialT struct {
 	layers []ts.ModuleT
+	names  []string // optional layer names. Empty if unnamed.
 }
 
 // / SeqT creates a new empty sequential layer.
@@ -126,28 +275,52 @@ func (s *SequentialT) IsEmpty() (retVal bool) {
 	return len(s.layers) == 0
 }
 
+// Implement ModuleInfo interface for SequentialT:
+// ===============================================
+
+// Name returns module name.
+func (s *SequentialT) Name() string { return "SequentialT" }
+
+// Children returns sub-layers named by their names or indices if unnamed.
+func (s *SequentialT) Children() []NamedModule {
+	children := make([]NamedModule, len(s.layers))
+	for i, l := range s.layers {
+		children[i] = NamedModule{Name: layerName(s.names, i), Module: l}
+	}
+
+	return children
+}
+
+// Parameters returns nil as SequentialT does not own any parameters.
+func (s *SequentialT) Parameters() []ts.NamedTensor { return nil }
+
 // Implement ModuleT interface for SequentialT:
 // ==========================================
 
 func (s *SequentialT) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
+	xs = preForward(s, xs)
+	return runForwardHooks(s, xs, s.forwardT(xs, train))
+}
+
+func (s *SequentialT) forwardT(xs *ts.Tensor, train bool) *ts.Tensor {
 	if s.IsEmpty() {
 		return xs.MustShallowClone()
 	}
 
 	if len(s.layers) == 1 {
-		return s.layers[0].ForwardT(xs, train)
+		return forwardLayerT(s.layers[0], xs, train)
 	}
 
 	// forward sequentially
 	outs := make([]*ts.Tensor, len(s.layers))
 	for i := 0; i < len(s.layers); i++ {
 		if i == 0 {
-			outs[0] = s.layers[i].ForwardT(xs, train)
+			outs[0] = forwardLayerT(s.layers[i], xs, train)
 			defer outs[0].MustDrop()
 		} else if i == len(s.layers)-1 {
-			return s.layers[i].ForwardT(outs[i-1], train)
+			return forwardLayerT(s.layers[i], outs[i-1], train)
 		} else {
-			outs[i] = s.layers[i].ForwardT(outs[i-1], train)
+			outs[i] = forwardLayerT(s.layers[i], outs[i-1], train)
 			defer outs[i].MustDrop()
 		}
 	}
@@ -158,6 +331,181 @@ func (s *SequentialT) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
 // Add appends a layer after all the current layers.
 func (s *SequentialT) Add(l ts.ModuleT) {
 	s.layers = append(s.layers, l)
+	s.names = append(s.names, "")
+}
+
+// AddNamed appends a named layer after all the current layers.
+// It returns an error if name is already used or looks like a layer index.
+func (s *SequentialT) AddNamed(name string, l ts.ModuleT) error {
+	if err := checkLayerName(s.names, name); err != nil {
+		return fmt.Errorf("SequentialT.AddNamed() failed: %w", err)
+	}
+
+	s.layers = append(s.layers, l)
+	s.names = append(s.names, name)
+
+	return nil
+}
+
+// Get returns layer at index.
+func (s *SequentialT) Get(idx int) ts.ModuleT {
+	return s.layers[idx]
+}
+
+// Index returns index of a layer given its name or its index as string. It returns -1 if not found.
+func (s *SequentialT) Index(name string) int {
+	return layerIndex(s.names, name)
+}
+
+// Insert inserts a layer at index. Layers from index onward are shifted.
+func (s *SequentialT) Insert(idx int, l ts.ModuleT) error {
+	return s.InsertNamed(idx, "", l)
+}
+
+// InsertNamed inserts a named layer at index. Layers from index onward are shifted.
+// It returns an error if name is already used or looks like a layer index.
+func (s *SequentialT) InsertNamed(idx int, name string, l ts.ModuleT) error {
+	if idx < 0 || idx > len(s.layers) {
+		return fmt.Errorf("SequentialT.Insert() failed: index %d out of range [0, %d].", idx, len(s.layers))
+	}
+	if err := checkLayerName(s.names, name); err != nil {
+		return fmt.Errorf("SequentialT.InsertNamed() failed: %w", err)
+	}
+
+	s.layers = append(s.layers[:idx], append([]ts.ModuleT{l}, s.layers[idx:]...)...)
+	s.names = append(s.names[:idx], append([]string{name}, s.names[idx:]...)...)
+
+	return nil
+}
+
+// InsertBefore inserts a layer before the layer with given name.
+func (s *SequentialT) InsertBefore(name string, l ts.ModuleT) error {
+	idx := s.Index(name)
+	if idx < 0 {
+		return fmt.Errorf("SequentialT.InsertBefore() failed: layer %q not found.", name)
+	}
+
+	return s.Insert(idx, l)
+}
+
+// Replace replaces layer at index. Layer name is kept.
+func (s *SequentialT) Replace(idx int, l ts.ModuleT) error {
+	if idx < 0 || idx >= len(s.layers) {
+		return fmt.Errorf("SequentialT.Replace() failed: index %d out of range [0, %d).", idx, len(s.layers))
+	}
+	s.layers[idx] = l
+
+	return nil
+}
+
+// ReplaceByName replaces layer with given name.
+func (s *SequentialT) ReplaceByName(name string, l ts.ModuleT) error {
+	idx := s.Index(name)
+	if idx < 0 {
+		return fmt.Errorf("SequentialT.ReplaceByName() failed: layer %q not found.", name)
+	}
+
+	return s.Replace(idx, l)
+}
+
+// Remove removes and returns layer at index.
+func (s *SequentialT) Remove(idx int) (ts.ModuleT, error) {
+	if idx < 0 || idx >= len(s.layers) {
+		return nil, fmt.Errorf("SequentialT.Remove() failed: index %d out of range [0, %d).", idx, len(s.layers))
+	}
+	l := s.layers[idx]
+	s.layers = append(s.layers[:idx], s.layers[idx+1:]...)
+	s.names = append(s.names[:idx], s.names[idx+1:]...)
+
+	return l, nil
+}
+
+// RemoveByName removes and returns layer with given name.
+func (s *SequentialT) RemoveByName(name string) (ts.ModuleT, error) {
+	idx := s.Index(name)
+	if idx < 0 {
+		return nil, fmt.Errorf("SequentialT.RemoveByName() failed: layer %q not found.", name)
+	}
+
+	return s.Remove(idx)
+}
+
+// Slice returns a new SequentialT with layers in range [start, end). Layers are shared, not copied.
+func (s *SequentialT) Slice(start, end int) (*SequentialT, error) {
+	if start < 0 || end > len(s.layers) || start > end {
+		return nil, fmt.Errorf("SequentialT.Slice() failed: invalid range [%d, %d) for %d layers.", start, end, len(s.layers))
+	}
+
+	return &SequentialT{
+		layers: append([]ts.ModuleT{}, s.layers[start:end]...),
+		names:  append([]string{}, s.names[start:end]...),
+	}, nil
+}
+
+// SliceByName returns a new SequentialT with layers from layer named `start` up to
+// but excluding layer named `end`. Empty `end` means to the last layer.
+func (s *SequentialT) SliceByName(start, end string) (*SequentialT, error) {
+	i, j, err := sliceRange(s.names, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("SequentialT.SliceByName() failed: %w", err)
+	}
+
+	return s.Slice(i, j)
+}
+
+// layerName returns name of layer at index. Unnamed layer is named by its index.
+func layerName(names []string, idx int) string {
+	if names[idx] != "" {
+		return names[idx]
+	}
+
+	return fmt.Sprint(idx)
+}
+
+// checkLayerName checks that a new layer name is unique and can't be confused
+// with the index names of unnamed layers. Empty name means unnamed layer.
+func checkLayerName(names []string, name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return fmt.Errorf("layer name %q looks like a layer index.", name)
+	}
+	for _, n := range names {
+		if n == name {
+			return fmt.Errorf("duplicate layer name %q.", name)
+		}
+	}
+
+	return nil
+}
+
+// layerIndex returns index of layer given its name. It returns -1 if not found.
+func layerIndex(names []string, name string) int {
+	for i := range names {
+		if layerName(names, i) == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// sliceRange resolves names of layers to range [start, end).
+func sliceRange(names []string, start, end string) (int, int, error) {
+	i := layerIndex(names, start)
+	if i < 0 {
+		return 0, 0, fmt.Errorf("layer %q not found.", start)
+	}
+	j := len(names)
+	if end != "" {
+		j = layerIndex(names, end)
+		if j < 0 {
+			return 0, 0, fmt.Errorf("layer %q not found.", end)
+		}
+	}
+
+	return i, j, nil
 }
 
 // AddFn appends a closure after all the current layers.
@@ -192,7 +540,7 @@ func (s *SequentialT) ForwardAllT(xs *ts.Tensor, train bool, opts ...uint8) (ret
 
 	currTs := xs
 	for i := 0; i < int(n); i++ {
-		res := s.layers[i].ForwardT(currTs, train)
+		res := forwardLayerT(s.layers[i], currTs, train)
 		retVal = append(retVal, res)
 		currTs = res
 	}
@@ -200,6 +548,28 @@ func (s *SequentialT) ForwardAllT(xs *ts.Tensor, train bool, opts ...uint8) (ret
 	return retVal
 }
 
+// forwardLayer runs forward pass of a Sequential entry calling its hooks if
+// the entry does not call them by itself.
+func forwardLayer(l ts.Module, xs *ts.Tensor) *ts.Tensor {
+	if runsOwnHooks(l) {
+		return l.Forward(xs)
+	}
+
+	xs = runForwardPreHooks(l, xs)
+	return runForwardHooks(l, xs, l.Forward(xs))
+}
+
+// forwardLayerT runs forward pass of a SequentialT entry calling its hooks if
+// the entry does not call them by itself.
+func forwardLayerT(l ts.ModuleT, xs *ts.Tensor, train bool) *ts.Tensor {
+	if runsOwnHooks(l) {
+		return l.ForwardT(xs, train)
+	}
+
+	xs = runForwardPreHooks(l, xs)
+	return runForwardHooks(l, xs, l.ForwardT(xs, train))
+}
+
 // ForwardWith is a handler function to implement Module interface for
 // any (anonymous) function it wraps.
 //
@@ -45,10 +45,12 @@ func NewEmbedding(vs *Path, numEmbeddings int64, embeddingDim int64, config *Emb
 
 // Forward implements Module interface for Embedding
 func (e *Embedding) Forward(xs *ts.Tensor) *ts.Tensor {
-	return ts.MustEmbedding(e.Ws, xs, e.config.PaddingIdx, e.config.ScaleGradByFreq, e.config.Sparse)
+	xs = preForward(e, xs)
+	out := ts.MustEmbedding(e.Ws, xs, e.config.PaddingIdx, e.config.ScaleGradByFreq, e.config.Sparse)
+	return postForward(e, xs, out)
 }
 
 // ForwardT implements ModuleT interface for Embedding
 func (e *Embedding) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
-	return ts.MustEmbedding(e.Ws, xs, e.config.PaddingIdx, e.config.ScaleGradByFreq, e.config.Sparse)
+	return e.Forward(xs)
 }
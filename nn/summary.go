@@ -0,0 +1,280 @@
+package nn
+
+// Shape-aware model summary.
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+// LayerSummary holds summary of a single layer collected during a forward pass.
+type LayerSummary struct {
+	Name        string  // dot-separated path of layer in module tree. Empty if unknown.
+	Type        string  // module name
+	OutputShape []int64 // shape of output tensor
+	Params      int64   // number of parameter elements owned by layer
+	MultAdds    int64   // number of multiply-add operations. Zero if not applicable.
+}
+
+// ModelSummary holds summary of a model.
+type ModelSummary struct {
+	InputShape      []int64
+	Layers          []LayerSummary
+	TotalParams     int64
+	TrainableParams int64
+	TotalMultAdds   int64
+}
+
+// forwardTracer records layers' outputs during a forward pass.
+type forwardTracer struct {
+	sync.Mutex
+	names  map[interface{}]string
+	layers []LayerSummary
+}
+
+var (
+	summaryMu    sync.Mutex // serializes Summary calls
+	activeTracer atomic.Pointer[forwardTracer]
+)
+
+// multAdder is implemented by layers for which multiply-adds can be calculated.
+type multAdder interface {
+	multAdds(xs, out *ts.Tensor) int64
+}
+
+// isComparable reports whether module can be used as a map key.
+func isComparable(m interface{}) bool {
+	t := reflect.TypeOf(m)
+	return t != nil && t.Comparable()
+}
+
+// traceForward is called by layers at the end of their forward pass. It is a no-op
+// unless Summary is running.
+func traceForward(m interface{}, xs, out *ts.Tensor) {
+	t := activeTracer.Load()
+	if t == nil || out == nil {
+		return
+	}
+
+	var name string
+	if key, ok := moduleKey(m); ok {
+		name = t.names[key]
+	}
+
+	var params int64
+	for _, p := range ModuleParameters(m) {
+		params += int64(p.Tensor.Numel())
+	}
+
+	var multAdds int64
+	if ma, ok := m.(multAdder); ok {
+		multAdds = ma.multAdds(xs, out)
+	}
+
+	t.Lock()
+	t.layers = append(t.layers, LayerSummary{
+		Name:        name,
+		Type:        ModuleName(m),
+		OutputShape: out.MustSize(),
+		Params:      params,
+		MultAdds:    multAdds,
+	})
+	t.Unlock()
+}
+
+func prod(dims []int64) int64 {
+	n := int64(1)
+	for _, d := range dims {
+		n *= d
+	}
+	return n
+}
+
+func (l *Linear) multAdds(xs, out *ts.Tensor) int64 {
+	// NOTE. l.Ws has shape [inDim, outDim]
+	return int64(out.Numel()) * l.Ws.MustSize()[0]
+}
+
+// convMultAdds calculates multiply-adds of convolution given weight of shape [out, in/groups, kernel...].
+func convMultAdds(ws, out *ts.Tensor) int64 {
+	size := ws.MustSize()
+	return int64(out.Numel()) * prod(size[1:])
+}
+
+// convTransposeMultAdds calculates multiply-adds of transposed convolution given weight of shape [in, out/groups, kernel...].
+func convTransposeMultAdds(ws, xs *ts.Tensor) int64 {
+	size := ws.MustSize()
+	return int64(xs.Numel()) * prod(size[1:])
+}
+
+func (c *Conv1D) multAdds(xs, out *ts.Tensor) int64          { return convMultAdds(c.Ws, out) }
+func (c *Conv2D) multAdds(xs, out *ts.Tensor) int64          { return convMultAdds(c.Ws, out) }
+func (c *Conv3D) multAdds(xs, out *ts.Tensor) int64          { return convMultAdds(c.Ws, out) }
+func (c *ConvTranspose1D) multAdds(xs, out *ts.Tensor) int64 { return convTransposeMultAdds(c.Ws, xs) }
+func (c *ConvTranspose2D) multAdds(xs, out *ts.Tensor) int64 { return convTransposeMultAdds(c.Ws, xs) }
+func (c *ConvTranspose3D) multAdds(xs, out *ts.Tensor) int64 { return convTransposeMultAdds(c.Ws, xs) }
+
+// SummaryOptions holds options of NewModelSummary.
+type SummaryOptions struct {
+	// dtype of dummy input. Floating point inputs are random, integer ones (e.g. indices of
+	// `Embedding`) are zeros. Default=dtype of model parameters, Float if none.
+	DType gotch.DType
+}
+
+type SummaryOpt func(*SummaryOptions)
+
+func WithSummaryDType(v gotch.DType) SummaryOpt {
+	return func(o *SummaryOptions) {
+		o.DType = v
+	}
+}
+
+// NewModelSummary runs a dummy forward pass (in evaluation mode and without gradient) of
+// a model with a dummy input of the given shape (including batch dimension) and
+// collects output shape, number of parameters and multiply-adds of each layer.
+//
+// Only layers calling into the summary tracer, i.e. built-in `nn` layers and `nn.Func`/`nn.FuncT`
+// without children, are reported. Layers are named by their paths in the module tree (see ModuleInfo).
+// NOTE. While a summary is being collected, forward passes run by other goroutines are recorded as well.
+func NewModelSummary(model interface{}, inputShape []int64, opts ...SummaryOpt) (*ModelSummary, error) {
+	device := gotch.CPU
+	o := &SummaryOptions{DType: gotch.Float}
+	if params := NamedParameters(model); len(params) > 0 {
+		device = params[0].Tensor.MustDevice()
+		o.DType = params[0].Tensor.DType()
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	tracer := &forwardTracer{names: make(map[interface{}]string)}
+	Walk(model, func(path string, m interface{}) {
+		if key, ok := moduleKey(m); ok {
+			if _, ok := tracer.names[key]; !ok {
+				tracer.names[key] = path
+			}
+		}
+	})
+
+	summaryMu.Lock()
+	defer summaryMu.Unlock()
+
+	var err error
+	activeTracer.Store(tracer)
+	ts.NoGrad(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("NewModelSummary() failed: forward pass panicked: %v", r)
+			}
+		}()
+
+		var xs *ts.Tensor
+		if gotch.IsFloatDType(o.DType) {
+			xs = ts.MustRandn(inputShape, o.DType, device)
+		} else {
+			xs = ts.MustZeros(inputShape, o.DType, device)
+		}
+		var out *ts.Tensor
+		switch m := model.(type) {
+		case ts.ModuleT:
+			out = m.ForwardT(xs, false)
+		case ts.Module:
+			out = m.Forward(xs)
+		default:
+			err = fmt.Errorf("NewModelSummary() failed: model (%T) implements neither ts.Module nor ts.ModuleT", model)
+		}
+		xs.MustDrop()
+		if out != nil {
+			out.MustDrop()
+		}
+	})
+	activeTracer.Store(nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ModelSummary{
+		InputShape: inputShape,
+		Layers:     tracer.layers,
+	}
+	for _, p := range NamedParameters(model) {
+		n := int64(p.Tensor.Numel())
+		s.TotalParams += n
+		if p.Tensor.MustRequiresGrad() {
+			s.TrainableParams += n
+		}
+	}
+	for _, l := range s.Layers {
+		s.TotalMultAdds += l.MultAdds
+	}
+
+	return s, nil
+}
+
+// String returns summary as a table.
+func (s *ModelSummary) String() string {
+	type row [4]string
+	header := row{"Layer (type)", "Output Shape", "Param #", "Mult-Adds"}
+	rows := make([]row, len(s.Layers))
+	for i, l := range s.Layers {
+		name := l.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", l.Type, i+1)
+		} else {
+			name = fmt.Sprintf("%s (%s)", name, l.Type)
+		}
+		rows[i] = row{name, fmt.Sprint(l.OutputShape), fmt.Sprint(l.Params), fmt.Sprint(l.MultAdds)}
+	}
+
+	widths := [4]int{}
+	for _, r := range append([]row{header}, rows...) {
+		for j, c := range r {
+			if len(c) > widths[j] {
+				widths[j] = len(c)
+			}
+		}
+	}
+
+	var b strings.Builder
+	lineLen := widths[0] + widths[1] + widths[2] + widths[3] + 3*3
+	writeRow := func(r row) {
+		fmt.Fprintf(&b, "%-*s   %*s   %*s   %*s\n", widths[0], r[0], widths[1], r[1], widths[2], r[2], widths[3], r[3])
+	}
+
+	b.WriteString(strings.Repeat("=", lineLen) + "\n")
+	writeRow(header)
+	b.WriteString(strings.Repeat("=", lineLen) + "\n")
+	for _, r := range rows {
+		writeRow(r)
+	}
+	b.WriteString(strings.Repeat("=", lineLen) + "\n")
+	fmt.Fprintf(&b, "Input shape: %v\n", s.InputShape)
+	fmt.Fprintf(&b, "Total params: %d\n", s.TotalParams)
+	fmt.Fprintf(&b, "Trainable params: %d\n", s.TrainableParams)
+	fmt.Fprintf(&b, "Non-trainable params: %d\n", s.TotalParams-s.TrainableParams)
+	fmt.Fprintf(&b, "Total mult-adds: %d\n", s.TotalMultAdds)
+	b.WriteString(strings.Repeat("=", lineLen) + "\n")
+
+	return b.String()
+}
+
+// Summary prints a torchsummary-style table of a model with layer names, output shapes,
+// number of parameters and multiply-adds collected by running a dummy forward pass
+// with an input of the given shape (including batch dimension). See NewModelSummary.
+func Summary(model interface{}, inputShape []int64, opts ...SummaryOpt) (*ModelSummary, error) {
+	s, err := NewModelSummary(model, inputShape, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Print(s.String())
+
+	return s, nil
+}
@@ -0,0 +1,104 @@
+package nn_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/nn"
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestModuleInfo(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	path := vs.Root()
+	seq := nn.Seq()
+	seq.Add(nn.NewLinear(path.Sub("0"), 4, 8, nn.DefaultLinearConfig()))
+	seq.AddFn(nn.NewFunc(func(xs *ts.Tensor) *ts.Tensor {
+		return xs.MustRelu(false)
+	}))
+	seq.Add(nn.NewLinear(path.Sub("2"), 8, 2, nn.DefaultLinearConfig()))
+
+	wantNames := []string{"0.weight", "0.bias", "2.weight", "2.bias"}
+	var gotNames []string
+	for _, p := range nn.NamedParameters(seq) {
+		gotNames = append(gotNames, p.Name)
+	}
+	if !reflect.DeepEqual(wantNames, gotNames) {
+		t.Errorf("Expected parameter names: %v\n", wantNames)
+		t.Errorf("Got: %v\n", gotNames)
+	}
+
+	if got := nn.NumParameters(seq); got != 4*8+8+8*2+2 {
+		t.Errorf("Expected %v parameters, got %v\n", 4*8+8+8*2+2, got)
+	}
+
+	var gotPaths []string
+	nn.Walk(seq, func(path string, m interface{}) {
+		gotPaths = append(gotPaths, path+":"+nn.ModuleName(m))
+	})
+	wantPaths := []string{":Sequential", "0:Linear", "1:Func", "2:Linear"}
+	if !reflect.DeepEqual(wantPaths, gotPaths) {
+		t.Errorf("Expected module paths: %v\n", wantPaths)
+		t.Errorf("Got: %v\n", gotPaths)
+	}
+}
+
+func TestModelSummary(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	path := vs.Root()
+	seq := nn.SeqT()
+	seq.Add(nn.NewConv2D(path.Sub("conv"), 3, 4, 3, nn.DefaultConv2DConfig()))
+	seq.AddFn(nn.NewFunc(func(xs *ts.Tensor) *ts.Tensor {
+		return xs.FlatView()
+	}))
+	seq.Add(nn.NewLinear(path.Sub("fc"), 4*2*2, 5, nn.DefaultLinearConfig()))
+
+	s, err := nn.NewModelSummary(seq, []int64{2, 3, 4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []nn.LayerSummary{
+		// 2*4*2*2 outputs, each 3*3*3 multiply-adds
+		{Name: "0", Type: "Conv2D", OutputShape: []int64{2, 4, 2, 2}, Params: 4*3*3*3 + 4, MultAdds: 32 * 27},
+		{Name: "1", Type: "Func", OutputShape: []int64{2, 16}, Params: 0, MultAdds: 0},
+		{Name: "2", Type: "Linear", OutputShape: []int64{2, 5}, Params: 16*5 + 5, MultAdds: 10 * 16},
+	}
+	if !reflect.DeepEqual(want, s.Layers) {
+		t.Errorf("Expected layers: %+v\n", want)
+		t.Errorf("Got: %+v\n", s.Layers)
+	}
+
+	if s.TotalParams != 112+85 || s.TrainableParams != s.TotalParams {
+		t.Errorf("Expected %v total and trainable params, got %v and %v\n", 112+85, s.TotalParams, s.TrainableParams)
+	}
+	if s.TotalMultAdds != 32*27+10*16 {
+		t.Errorf("Expected %v mult-adds, got %v\n", 32*27+10*16, s.TotalMultAdds)
+	}
+}
+
+func TestModelSummaryDType(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	fc := nn.NewLinear(vs.Root().Sub("fc"), 3, 2, nn.DefaultLinearConfig())
+	fc.Ws = fc.Ws.MustTotype(gotch.Double, false)
+	fc.Bs = fc.Bs.MustTotype(gotch.Double, false)
+	s, err := nn.NewModelSummary(fc, []int64{4, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Layers[0].OutputShape, []int64{4, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected output shape %v, got %v\n", want, got)
+	}
+
+	// Embedding takes integer indices.
+	seq := nn.Seq()
+	seq.Add(nn.NewEmbedding(vs.Root().Sub("emb"), 10, 3, nn.DefaultEmbeddingConfig()))
+	s, err = nn.NewModelSummary(seq, []int64{2, 5}, nn.WithSummaryDType(gotch.Int64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Layers[0].OutputShape, []int64{2, 5, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected output shape %v, got %v\n", want, got)
+	}
+}
@@ -3,6 +3,7 @@ package nn
 import (
 	"fmt"
 	"log"
+	"path"
 	"reflect"
 	"sort"
 	"strings"
@@ -420,6 +421,48 @@ func (vs *VarStore) Copy(src *VarStore) error {
 	return nil
 }
 
+// Reinit re-initializes (in-place) all variables whose names match the given pattern
+// with the specified initializer. It returns names of re-initialized variables in sorted order.
+//
+// Pattern follows `path.Match` syntax where variable names are full dot-separated
+// paths, e.g. "classifier.*" or "*.bias". Note that '*' also matches the separator.
+func (vs *VarStore) Reinit(pattern string, ini Init) ([]string, error) {
+	vs.Lock()
+	defer vs.Unlock()
+
+	var names []string
+	for name := range vs.vars {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			err = fmt.Errorf("VarStore.Reinit() failed: invalid pattern %q: %w", pattern, err)
+			return nil, err
+		}
+		if matched {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		x := vs.vars[name].Tensor
+		ts.NoGrad(func() {
+			ini.Set(x)
+		})
+	}
+
+	return names, nil
+}
+
+// MustReinit re-initializes variables matching the given pattern. It panics if error.
+func (vs *VarStore) MustReinit(pattern string, ini Init) []string {
+	names, err := vs.Reinit(pattern, ini)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return names
+}
+
 // Summary prints a simple list of all named variables with their shapes.
 func (vs *VarStore) Summary() {
 	vars := vs.vars
@@ -38,6 +38,39 @@ func TestVarStoreEntry(t *testing.T) {
 	}
 }
 
+func TestVarStoreReinit(t *testing.T) {
+	vs := nn.NewVarStore(gotch.CPU)
+	root := vs.Root()
+	nn.NewLinear(root.Sub("backbone"), 3, 4, nn.DefaultLinearConfig())
+	nn.NewLinear(root.Sub("classifier"), 4, 2, nn.DefaultLinearConfig())
+
+	names, err := vs.Reinit("classifier.*", nn.NewConstInit(0.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantNames := []string{"classifier.bias", "classifier.weight"}
+	if !reflect.DeepEqual(wantNames, names) {
+		t.Errorf("Expected re-initialized variables: %v\n", wantNames)
+		t.Errorf("Got re-initialized variables: %v\n", names)
+	}
+
+	for name, x := range vs.Variables() {
+		sum := x.MustAbs(false).MustSum(gotch.Double, true).Float64Values()[0]
+		isClassifier := name == "classifier.weight" || name == "classifier.bias"
+		if isClassifier && sum != 0 {
+			t.Errorf("Expected %q re-initialized with zeros\n", name)
+		}
+		if !isClassifier && sum == 0 {
+			t.Errorf("Expected %q unchanged\n", name)
+		}
+	}
+
+	if _, err := vs.Reinit("[", nn.NewConstInit(0.0)); err == nil {
+		t.Errorf("Expected error for invalid pattern\n")
+	}
+}
+
 // NOTE: comment out for working on Travis.
 // uncomment to test locally
 
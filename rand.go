@@ -0,0 +1,54 @@
+package gotch
+
+// Go random number generator shared by gotch packages.
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// rng is used instead of `math/rand` global functions by data samplers (dutil) and
+// image augmentations (vision, vision/aug) so that their randomness can be reproduced
+// with `SeedRand` or `ts.ManualSeed`.
+var rng = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// SeedRand seeds the Go random number generator of gotch packages.
+//
+// NOTE. `ts.ManualSeed` seeds it together with libtorch random number generators.
+func SeedRand(seed int64) {
+	rng.Lock()
+	rng.Seed(seed)
+	rng.Unlock()
+}
+
+// RandInt63 returns a non-negative pseudo-random int64, e.g. to seed a new `rand.Source`.
+func RandInt63() int64 {
+	rng.Lock()
+	defer rng.Unlock()
+	return rng.Int63()
+}
+
+// RandIntn returns a pseudo-random number in [0, n). It panics if n <= 0.
+func RandIntn(n int) int {
+	rng.Lock()
+	defer rng.Unlock()
+	return rng.Intn(n)
+}
+
+// RandFloat64 returns a pseudo-random number in [0.0, 1.0).
+func RandFloat64() float64 {
+	rng.Lock()
+	defer rng.Unlock()
+	return rng.Float64()
+}
+
+// RandPerm returns a pseudo-random permutation of integers in [0, n).
+func RandPerm(n int) []int {
+	rng.Lock()
+	defer rng.Unlock()
+	return rng.Perm(n)
+}
@@ -0,0 +1,80 @@
+package ts
+
+// Autograd anomaly detection.
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+// AnomalyError is returned by backward pass when a backward op produces NaN values
+// in anomaly detection mode.
+type AnomalyError struct {
+	Op     string // backward op, e.g. "SqrtBackward0"
+	Output int    // index of the output of backward op with NaN values
+	Err    error
+}
+
+func (e *AnomalyError) Error() string {
+	return fmt.Sprintf("Anomaly detected: backward op %q returned NaN values in its output %d: %v", e.Op, e.Output, e.Err)
+}
+
+func (e *AnomalyError) Unwrap() error {
+	return e.Err
+}
+
+// Ref. torch/csrc/autograd/engine.cpp
+var anomalyRe = regexp.MustCompile(`Function '([^']+)' returned nan values in its (\d+)th output`)
+
+// SetDetectAnomaly enables or disables anomaly detection of autograd. When enabled,
+// backward ops returning NaN values make backward pass fail with an *AnomalyError, unless
+// optional `checkNaN` is false.
+//
+// NOTE. Anomaly mode is global in libtorch and slows down backward pass. It should
+// be used for debugging only.
+func SetDetectAnomaly(enabled bool, checkNaNOpt ...bool) {
+	checkNaN := true
+	if len(checkNaNOpt) > 0 {
+		checkNaN = checkNaNOpt[0]
+	}
+
+	lib.AtSetAnomalyMode(boolToInt(enabled), boolToInt(checkNaN))
+}
+
+// IsAnomalyEnabled returns whether anomaly detection of autograd is enabled.
+func IsAnomalyEnabled() bool {
+	return lib.AtIsAnomalyEnabled() == 1
+}
+
+// WithDetectAnomaly runs a closure with anomaly detection enabled and restores
+// the previous mode on return.
+func WithDetectAnomaly(fn func()) {
+	prev := IsAnomalyEnabled()
+	SetDetectAnomaly(true)
+	defer SetDetectAnomaly(prev)
+
+	fn()
+}
+
+// anomalyErr converts error of a backward op returning NaN values to *AnomalyError.
+func anomalyErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *AnomalyError
+	if errors.As(err, &e) {
+		return err
+	}
+	m := anomalyRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	output, _ := strconv.Atoi(m[2])
+
+	return &AnomalyError{Op: m[1], Output: output, Err: err}
+}
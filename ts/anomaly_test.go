@@ -0,0 +1,39 @@
+package ts_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestDetectAnomaly(t *testing.T) {
+	x := ts.MustOfSlice([]float64{-1.0, 4.0})
+	x.MustRequiresGrad_(true)
+
+	ts.WithDetectAnomaly(func() {
+		if !ts.IsAnomalyEnabled() {
+			t.Errorf("Expected anomaly detection enabled\n")
+		}
+
+		loss := x.MustSqrt(false).MustSum(x.DType(), true)
+		err := loss.Backward()
+		var anomaly *ts.AnomalyError
+		if !errors.As(err, &anomaly) {
+			t.Fatalf("Expected AnomalyError, got %v\n", err)
+		}
+		if anomaly.Op != "SqrtBackward0" || anomaly.Output != 0 {
+			t.Errorf("Expected anomaly in output 0 of SqrtBackward0, got %q output %v\n", anomaly.Op, anomaly.Output)
+		}
+	})
+
+	if ts.IsAnomalyEnabled() {
+		t.Errorf("Expected anomaly detection restored to disabled\n")
+	}
+
+	// NaN gradients pass silently without anomaly detection.
+	x.ZeroGrad()
+	if err := x.MustSqrt(false).MustSum(x.DType(), true).Backward(); err != nil {
+		t.Errorf("Expected no error without anomaly detection, got %v\n", err)
+	}
+}
@@ -0,0 +1,397 @@
+package ts
+
+// Higher-order autograd API.
+
+import (
+	"fmt"
+	"log"
+
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+// GradOpts holds options of Grad.
+type GradOpts struct {
+	// Gradients with respect to outputs, i.e. the "vector" in vector-Jacobian product.
+	// Nil (or nil elements) means ones, which requires scalar outputs to compute usual gradients.
+	GradOutputs []*Tensor
+	// Whether to keep the graph to compute gradients again. Default to CreateGraph.
+	RetainGraph bool
+	// Whether to construct graph of the gradients to compute higher order derivatives.
+	CreateGraph bool
+	// Whether inputs not used to compute outputs are allowed. Their gradients are nil.
+	AllowUnused bool
+
+	retainSet bool
+}
+
+type GradOpt func(*GradOpts)
+
+func defaultGradOpts() *GradOpts {
+	return &GradOpts{
+		GradOutputs: nil,
+		RetainGraph: false,
+		CreateGraph: false,
+		AllowUnused: false,
+	}
+}
+
+// WithGradOutputs sets gradients with respect to outputs, one for each output.
+func WithGradOutputs(v ...*Tensor) GradOpt {
+	return func(o *GradOpts) {
+		o.GradOutputs = v
+	}
+}
+
+// WithRetainGraph sets whether to keep the graph after computing gradients.
+func WithRetainGraph(v bool) GradOpt {
+	return func(o *GradOpts) {
+		o.RetainGraph = v
+		o.retainSet = true
+	}
+}
+
+// WithCreateGraph sets whether to construct graph of the gradients.
+func WithCreateGraph(v bool) GradOpt {
+	return func(o *GradOpts) {
+		o.CreateGraph = v
+	}
+}
+
+// WithAllowUnused sets whether inputs not used to compute outputs are allowed.
+func WithAllowUnused(v bool) GradOpt {
+	return func(o *GradOpts) {
+		o.AllowUnused = v
+	}
+}
+
+// Grad computes and returns sums of gradients of outputs with respect to inputs.
+// Unlike `Backward`, gradients are not accumulated into `.grad` of inputs.
+//
+// Example: gradient penalty of WGAN-GP
+//
+//	grads := ts.MustGrad([]*ts.Tensor{critic}, []*ts.Tensor{interpolated}, ts.WithCreateGraph(true))
+//	penalty := grads[0].MustNorm(false).MustSubScalar(ts.FloatScalar(1), true).MustSquare(true)
+func Grad(outputs, inputs []*Tensor, opts ...GradOpt) ([]*Tensor, error) {
+	o := defaultGradOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !o.retainSet {
+		o.RetainGraph = o.CreateGraph
+	}
+
+	if len(outputs) == 0 || len(inputs) == 0 {
+		err := fmt.Errorf("Grad() failed: expected non-empty outputs and inputs, got %d outputs and %d inputs.", len(outputs), len(inputs))
+		return nil, err
+	}
+	if len(o.GradOutputs) > 0 && len(o.GradOutputs) != len(outputs) {
+		err := fmt.Errorf("Grad() failed: expected %d grad outputs, got %d.", len(outputs), len(o.GradOutputs))
+		return nil, err
+	}
+
+	coutputs := make([]lib.Ctensor, len(outputs))
+	cgradOutputs := make([]lib.Ctensor, len(outputs))
+	for i, x := range outputs {
+		coutputs[i] = x.ctensor
+		if len(o.GradOutputs) > 0 && o.GradOutputs[i] != nil {
+			cgradOutputs[i] = o.GradOutputs[i].ctensor
+		}
+	}
+	cinputs := make([]lib.Ctensor, len(inputs))
+	for i, x := range inputs {
+		cinputs[i] = x.ctensor
+	}
+	cresults := make([]lib.Ctensor, len(inputs))
+
+	lib.AtGrad(coutputs, cinputs, cgradOutputs, cresults, boolToInt(o.RetainGraph), boolToInt(o.CreateGraph), boolToInt(o.AllowUnused))
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Grad() failed: %w", anomalyErr(err))
+		return nil, err
+	}
+
+	grads := make([]*Tensor, len(inputs))
+	for i, cgrad := range cresults {
+		if cgrad != nil {
+			grads[i] = newTensor(cgrad)
+		}
+	}
+
+	return grads, nil
+}
+
+// MustGrad computes gradients of outputs with respect to inputs. It panics if error.
+func MustGrad(outputs, inputs []*Tensor, opts ...GradOpt) []*Tensor {
+	grads, err := Grad(outputs, inputs, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return grads
+}
+
+func boolToInt(v bool) int {
+	if v {
+		return 1
+	}
+
+	return 0
+}
+
+// Functional API:
+// ===============
+// Functions below evaluate a function `fn` at `inputs` with gradient enabled. Only `WithCreateGraph`
+// option applies: if set, results are differentiable, otherwise they are detached.
+// Derivatives with respect to inputs not used by `fn` are zeros.
+
+func gradOpts(opts []GradOpt) *GradOpts {
+	o := defaultGradOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// diffInputs returns inputs to differentiate `fn` with respect to. They are detached
+// from the graph unless it is to be created.
+func diffInputs(inputs []*Tensor, createGraph bool) []*Tensor {
+	xs := make([]*Tensor, len(inputs))
+	for i, x := range inputs {
+		if createGraph && x.MustRequiresGrad() {
+			xs[i] = x.MustViewAs(x, false)
+			continue
+		}
+		xs[i] = x.MustDetach(false)
+		xs[i].MustRequiresGrad_(true)
+	}
+
+	return xs
+}
+
+func diffOutputs(outputs []*Tensor, createGraph bool) []*Tensor {
+	if createGraph {
+		return outputs
+	}
+
+	ys := make([]*Tensor, len(outputs))
+	for i, y := range outputs {
+		ys[i] = y.MustDetach(false)
+	}
+
+	return ys
+}
+
+// gradOrZeros computes gradients filling gradients of unused inputs with zeros.
+// Outputs not requiring gradient are skipped.
+func gradOrZeros(outputs, inputs, gradOutputs []*Tensor, createGraph bool) ([]*Tensor, error) {
+	var ys, gs []*Tensor
+	for i, y := range outputs {
+		if y == nil || !y.MustRequiresGrad() {
+			continue
+		}
+		ys = append(ys, y)
+		if gradOutputs != nil {
+			gs = append(gs, gradOutputs[i])
+		}
+	}
+
+	var grads []*Tensor
+	if len(ys) > 0 {
+		var err error
+		grads, err = Grad(ys, inputs, WithGradOutputs(gs...), WithRetainGraph(true), WithCreateGraph(createGraph), WithAllowUnused(true))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		grads = make([]*Tensor, len(inputs))
+	}
+
+	for i, g := range grads {
+		if g == nil {
+			grads[i] = inputs[i].MustZerosLike(false)
+		}
+	}
+
+	return grads, nil
+}
+
+// VJP computes outputs of `fn` and vector-Jacobian products `v^T J` with respect to inputs
+// where `v` has one tensor of the shape of each output.
+func VJP(fn func([]*Tensor) []*Tensor, inputs, v []*Tensor, opts ...GradOpt) (outputs, vjp []*Tensor, err error) {
+	o := gradOpts(opts)
+	WithGradMode(true, func() {
+		xs := diffInputs(inputs, o.CreateGraph)
+		ys := fn(xs)
+		if len(v) != len(ys) {
+			err = fmt.Errorf("VJP() failed: expected %d vectors, got %d.", len(ys), len(v))
+			return
+		}
+		vjp, err = gradOrZeros(ys, xs, v, o.CreateGraph)
+		outputs = diffOutputs(ys, o.CreateGraph)
+	})
+
+	return outputs, vjp, err
+}
+
+// JVP computes outputs of `fn` and Jacobian-vector products `J v` with respect to inputs
+// where `v` has one tensor of the shape of each input.
+//
+// NOTE. It uses double backward, i.e. `fn` must be twice differentiable.
+func JVP(fn func([]*Tensor) []*Tensor, inputs, v []*Tensor, opts ...GradOpt) (outputs, jvp []*Tensor, err error) {
+	o := gradOpts(opts)
+	if len(v) != len(inputs) {
+		err = fmt.Errorf("JVP() failed: expected %d vectors, got %d.", len(inputs), len(v))
+		return nil, nil, err
+	}
+
+	WithGradMode(true, func() {
+		xs := diffInputs(inputs, o.CreateGraph)
+		ys := fn(xs)
+
+		// u -> J^T u is linear in u, its gradient with respect to u along v is J v.
+		us := make([]*Tensor, len(ys))
+		for i, y := range ys {
+			us[i] = y.MustZerosLike(false)
+			us[i].MustRequiresGrad_(true)
+		}
+		var gs []*Tensor
+		gs, err = gradOrZeros(ys, xs, us, true)
+		if err != nil {
+			return
+		}
+		jvp, err = gradOrZeros(gs, us, v, o.CreateGraph)
+		outputs = diffOutputs(ys, o.CreateGraph)
+	})
+
+	return outputs, jvp, err
+}
+
+// Jacobian computes Jacobian of `fn` at inputs. Element [i][j] is the Jacobian of output i
+// with respect to input j and has shape output i shape + input j shape.
+func Jacobian(fn func([]*Tensor) []*Tensor, inputs []*Tensor, opts ...GradOpt) ([][]*Tensor, error) {
+	o := gradOpts(opts)
+	var (
+		jac [][]*Tensor
+		err error
+	)
+	WithGradMode(true, func() {
+		xs := diffInputs(inputs, o.CreateGraph)
+		ys := fn(xs)
+		jac = make([][]*Tensor, len(ys))
+		for i, y := range ys {
+			n := int64(y.Numel())
+			eye := MustEye(n, y.DType(), y.MustDevice())
+
+			// rows[j][k] is gradient of element k of output i with respect to input j.
+			rows := make([][]*Tensor, len(xs))
+			for k := int64(0); k < n; k++ {
+				e := eye.MustSelect(0, k, false).MustViewAs(y, true)
+				var grads []*Tensor
+				grads, err = gradOrZeros([]*Tensor{y}, xs, []*Tensor{e}, o.CreateGraph)
+				if err != nil {
+					return
+				}
+				for j, g := range grads {
+					rows[j] = append(rows[j], g)
+				}
+				e.MustDrop()
+			}
+			eye.MustDrop()
+
+			jac[i] = make([]*Tensor, len(xs))
+			for j, x := range xs {
+				shape := append(append([]int64{}, y.MustSize()...), x.MustSize()...)
+				if n == 0 {
+					jac[i][j] = MustZeros(shape, x.DType(), x.MustDevice())
+					continue
+				}
+				// NOTE. stacked rows have shape [n, input shape...].
+				stacked := MustStack(rows[j], 0)
+				if y.Dim() == 0 {
+					jac[i][j] = stacked.MustSelect(0, 0, true)
+				} else {
+					jac[i][j] = stacked.MustReshape(shape, true)
+				}
+				for _, g := range rows[j] {
+					g.MustDrop()
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jac, nil
+}
+
+// scalarFn checks that `fn` returns a single scalar.
+func scalarFn(name string, fn func([]*Tensor) []*Tensor) func([]*Tensor) (*Tensor, error) {
+	return func(xs []*Tensor) (*Tensor, error) {
+		ys := fn(xs)
+		if len(ys) != 1 || ys[0].Numel() != 1 {
+			err := fmt.Errorf("%s() failed: expected function returning a single scalar, got %d outputs.", name, len(ys))
+			return nil, err
+		}
+
+		return ys[0], nil
+	}
+}
+
+// Hessian computes Hessian of `fn` returning a single scalar. Element [i][j] is the second
+// derivative with respect to inputs i and j and has shape input i shape + input j shape.
+func Hessian(fn func([]*Tensor) []*Tensor, inputs []*Tensor, opts ...GradOpt) ([][]*Tensor, error) {
+	f := scalarFn("Hessian", fn)
+	var fnErr error
+	gradFn := func(xs []*Tensor) []*Tensor {
+		y, err := f(xs)
+		if err != nil {
+			fnErr = err
+			return nil
+		}
+		grads, err := gradOrZeros([]*Tensor{y}, xs, nil, true)
+		if err != nil {
+			fnErr = err
+			return nil
+		}
+
+		return grads
+	}
+
+	hess, err := Jacobian(gradFn, inputs, opts...)
+	if fnErr != nil {
+		return nil, fnErr
+	}
+
+	return hess, err
+}
+
+// HVP computes output of `fn` returning a single scalar and Hessian-vector products `H v`
+// where `v` has one tensor of the shape of each input.
+func HVP(fn func([]*Tensor) []*Tensor, inputs, v []*Tensor, opts ...GradOpt) (output *Tensor, hvp []*Tensor, err error) {
+	o := gradOpts(opts)
+	if len(v) != len(inputs) {
+		err = fmt.Errorf("HVP() failed: expected %d vectors, got %d.", len(inputs), len(v))
+		return nil, nil, err
+	}
+
+	f := scalarFn("HVP", fn)
+	WithGradMode(true, func() {
+		xs := diffInputs(inputs, o.CreateGraph)
+		var y *Tensor
+		y, err = f(xs)
+		if err != nil {
+			return
+		}
+		var gs []*Tensor
+		gs, err = gradOrZeros([]*Tensor{y}, xs, nil, true)
+		if err != nil {
+			return
+		}
+		// H is symmetric, i.e. v^T H = (H v)^T.
+		hvp, err = gradOrZeros(gs, xs, v, o.CreateGraph)
+		output = diffOutputs([]*Tensor{y}, o.CreateGraph)[0]
+	})
+
+	return output, hvp, err
+}
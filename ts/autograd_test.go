@@ -0,0 +1,97 @@
+package ts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+func assertFloat64s(t *testing.T, name string, want []float64, x *ts.Tensor) {
+	t.Helper()
+	if got := x.Float64Values(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected %s: %v\n", name, want)
+		t.Errorf("Got: %v\n", got)
+	}
+}
+
+func cube(xs []*ts.Tensor) []*ts.Tensor {
+	x := xs[0]
+	return []*ts.Tensor{x.MustPowTensorScalar(ts.FloatScalar(3.0), false).MustSum(x.DType(), true)}
+}
+
+func square(xs []*ts.Tensor) []*ts.Tensor {
+	return []*ts.Tensor{xs[0].MustMul(xs[0], false)}
+}
+
+func TestGrad(t *testing.T) {
+	x := ts.MustOfSlice([]float64{1, 2, 3})
+	x.MustRequiresGrad_(true)
+	unused := ts.MustOfSlice([]float64{1})
+	unused.MustRequiresGrad_(true)
+
+	y := cube([]*ts.Tensor{x})[0]
+	grads := ts.MustGrad([]*ts.Tensor{y}, []*ts.Tensor{x, unused}, ts.WithCreateGraph(true), ts.WithAllowUnused(true))
+	assertFloat64s(t, "gradient", []float64{3, 12, 27}, grads[0])
+	if grads[1] != nil {
+		t.Errorf("Expected nil gradient of unused input\n")
+	}
+	if x.MustGrad(false).MustDefined() {
+		t.Errorf("Expected Grad not to accumulate into .grad\n")
+	}
+
+	// second order derivative from differentiable gradient.
+	v := ts.MustOfSlice([]float64{1, 1, 1})
+	grads2 := ts.MustGrad([]*ts.Tensor{grads[0]}, []*ts.Tensor{x}, ts.WithGradOutputs(v))
+	assertFloat64s(t, "second order gradient", []float64{6, 12, 18}, grads2[0])
+
+	if _, err := ts.Grad([]*ts.Tensor{y}, []*ts.Tensor{x}, ts.WithGradOutputs(v, v)); err == nil {
+		t.Errorf("Expected error of mismatched grad outputs\n")
+	}
+}
+
+func TestFunctionalAutograd(t *testing.T) {
+	x := ts.MustOfSlice([]float64{1, 2})
+	v := ts.MustOfSlice([]float64{1, 10})
+
+	jac, err := ts.Jacobian(square, []*ts.Tensor{x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := jac[0][0].MustSize(); !reflect.DeepEqual([]int64{2, 2}, got) {
+		t.Errorf("Expected Jacobian shape [2 2], got %v\n", got)
+	}
+	assertFloat64s(t, "Jacobian", []float64{2, 0, 0, 4}, jac[0][0])
+
+	hess, err := ts.Hessian(cube, []*ts.Tensor{x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat64s(t, "Hessian", []float64{6, 0, 0, 12}, hess[0][0])
+
+	out, vjp, err := ts.VJP(square, []*ts.Tensor{x}, []*ts.Tensor{v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat64s(t, "VJP output", []float64{1, 4}, out[0])
+	assertFloat64s(t, "VJP", []float64{2, 40}, vjp[0])
+	if vjp[0].MustRequiresGrad() {
+		t.Errorf("Expected detached VJP without create graph\n")
+	}
+
+	_, jvp, err := ts.JVP(square, []*ts.Tensor{x}, []*ts.Tensor{v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat64s(t, "JVP", []float64{2, 40}, jvp[0])
+
+	_, hvp, err := ts.HVP(cube, []*ts.Tensor{x}, []*ts.Tensor{v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat64s(t, "HVP", []float64{6, 120}, hvp[0])
+
+	if _, err := ts.Hessian(square, []*ts.Tensor{x}); err == nil {
+		t.Errorf("Expected error of Hessian of non-scalar function\n")
+	}
+}
@@ -0,0 +1,44 @@
+package ts_test
+
+import (
+	"testing"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+// go test -bench='Slice$|Values$' -run=^a ./ts
+var benchSliceData = make([]float32, 1<<20)
+
+func BenchmarkOfSlice(b *testing.B) {
+	b.SetBytes(int64(len(benchSliceData) * 4))
+	for i := 0; i < b.N; i++ {
+		x := ts.MustOfSlice(benchSliceData)
+		x.MustDrop()
+	}
+}
+
+func BenchmarkFromSlice(b *testing.B) {
+	b.SetBytes(int64(len(benchSliceData) * 4))
+	for i := 0; i < b.N; i++ {
+		x := ts.MustFromSlice(benchSliceData)
+		x.MustDrop()
+	}
+}
+
+func BenchmarkFloat64Values(b *testing.B) {
+	x := ts.MustFromSlice(make([]float64, 1<<20))
+	b.SetBytes(int64(x.Numel() * 8))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = x.Float64Values()
+	}
+}
+
+func BenchmarkToSlice(b *testing.B) {
+	x := ts.MustFromSlice(make([]float64, 1<<20))
+	b.SetBytes(int64(x.Numel() * 8))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ts.MustToSlice[float64](x)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build unix
+
+package ts
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/nullbull/gotch"
+)
+
+// FromMmap creates a CPU tensor over a file mapped into memory at byte `offset` without
+// reading it. The file is unmapped once the tensor storage is released.
+//
+// NOTE. The mapping is private: in-place ops on the tensor are not written to the file.
+func FromMmap(path string, shape []int64, dtype gotch.DType, offset int64) (*Tensor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		err = fmt.Errorf("FromMmap() failed: %w", err)
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		err = fmt.Errorf("FromMmap() failed: %w", err)
+		return nil, err
+	}
+	nbytes := int64(ElementCount(shape)) * int64(dtype.Size())
+	if offset < 0 || offset+nbytes > info.Size() {
+		err := fmt.Errorf("FromMmap() failed: %d bytes at offset %d exceed file size %d of %q", nbytes, offset, info.Size(), path)
+		return nil, err
+	}
+	if nbytes == 0 {
+		return Zeros(shape, dtype, gotch.CPU)
+	}
+
+	// NOTE. mmap offset must be page-aligned so that the file is mapped from start.
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(offset+nbytes), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE)
+	if err != nil {
+		err = fmt.Errorf("FromMmap() failed: %w", err)
+		return nil, err
+	}
+	unmap := func() {
+		if err := syscall.Munmap(data); err != nil {
+			log.Printf("WARNING: FromMmap() failed to unmap %q: %v\n", path, err)
+		}
+	}
+
+	x, err := FromBlob(unsafe.Pointer(&data[offset]), shape, dtype, WithBlobDeleter(unmap))
+	if err != nil {
+		err = fmt.Errorf("FromMmap() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustFromMmap creates a tensor over a file mapped into memory. It panics if error.
+func MustFromMmap(path string, shape []int64, dtype gotch.DType, offset int64) *Tensor {
+	x, err := FromMmap(path, shape, dtype, offset)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package ts_test
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestFromMmap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	buf := make([]byte, 16+4*4)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint32(buf[16+4*i:], math.Float32bits(float32(i)+0.5))
+	}
+	if err := os.WriteFile(path, buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	x := ts.MustFromMmap(path, []int64{2, 2}, gotch.Float, 16)
+	if got, want := ts.MustToSlice[float32](x), []float32{0.5, 1.5, 2.5, 3.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	x.MustDrop()
+
+	if _, err := ts.FromMmap(path, []int64{5}, gotch.Float, 16); err == nil {
+		t.Errorf("Expected error for data exceeding file size")
+	}
+}
@@ -0,0 +1,144 @@
+package ts
+
+// Zero-copy tensors over existing memory.
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"unsafe"
+
+	"github.com/nullbull/gotch"
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+// BlobOpts holds options of FromBlob.
+type BlobOpts struct {
+	Strides []int64      // strides in elements. Default=contiguous
+	Device  gotch.Device // device of memory. Default=CPU
+	Deleter func()       // called once the tensor storage releases memory. Default=nil
+}
+
+type BlobOpt func(*BlobOpts)
+
+func defaultBlobOpts() *BlobOpts {
+	return &BlobOpts{
+		Strides: nil,
+		Device:  gotch.CPU,
+		Deleter: nil,
+	}
+}
+
+func WithBlobStrides(v []int64) BlobOpt {
+	return func(o *BlobOpts) {
+		o.Strides = v
+	}
+}
+
+func WithBlobDevice(v gotch.Device) BlobOpt {
+	return func(o *BlobOpts) {
+		o.Device = v
+	}
+}
+
+func WithBlobDeleter(v func()) BlobOpt {
+	return func(o *BlobOpts) {
+		o.Deleter = v
+	}
+}
+
+// contiguousStrides returns strides of a contiguous tensor of shape.
+func contiguousStrides(shape []int64) []int64 {
+	strides := make([]int64, len(shape))
+	stride := int64(1)
+	for i := len(shape) - 1; i >= 0; i-- {
+		strides[i] = stride
+		stride *= shape[i]
+	}
+
+	return strides
+}
+
+// FromBlob creates a tensor over memory at `data` without copying. The memory must stay
+// valid until the deleter set with `WithBlobDeleter` is called, i.e. when the tensor and
+// all tensors sharing its storage (views, shallow clones) have been freed.
+//
+// NOTE. The deleter is called while tensors are being freed and must not create or free
+// tensors itself. In-place ops on the tensor write to the memory.
+func FromBlob(data unsafe.Pointer, shape []int64, dtype gotch.DType, opts ...BlobOpt) (*Tensor, error) {
+	o := defaultBlobOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	strides := o.Strides
+	if strides == nil {
+		strides = contiguousStrides(shape)
+	}
+	if len(strides) != len(shape) {
+		if o.Deleter != nil {
+			o.Deleter()
+		}
+		err := fmt.Errorf("FromBlob() failed: strides %v mismatched shape %v", strides, shape)
+		return nil, err
+	}
+
+	ctensor := lib.AtTensorOfBlob(data, shape, strides, int(dtype.CKind()), int(o.Device.CInt()), o.Deleter)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("FromBlob() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustFromBlob creates a tensor over memory at `data` without copying. It panics if error.
+func MustFromBlob(data unsafe.Pointer, shape []int64, dtype gotch.DType, opts ...BlobOpt) *Tensor {
+	x, err := FromBlob(data, shape, dtype, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// FromSliceNoCopy creates a tensor sharing memory with a Go slice. Default shape is 1-D
+// `[len(data)]`. The slice is pinned with `runtime.Pinner` so that it stays alive until
+// the tensor storage is released, and writes to either are visible in the other.
+func FromSliceNoCopy[T Element](data []T, shape ...int64) (*Tensor, error) {
+	if len(shape) == 0 {
+		shape = []int64{int64(len(data))}
+	}
+	if numel := int64(ElementCount(shape)); numel != int64(len(data)) {
+		err := fmt.Errorf("FromSliceNoCopy() failed: shape %v (%d elements) mismatched data length %d", shape, numel, len(data))
+		return nil, err
+	}
+	if len(data) == 0 {
+		x, err := FromSlice(data, shape...)
+		if err != nil {
+			err = fmt.Errorf("FromSliceNoCopy() failed: %w", err)
+		}
+		return x, err
+	}
+
+	var pinner runtime.Pinner
+	pinner.Pin(&data[0])
+
+	x, err := FromBlob(unsafe.Pointer(&data[0]), shape, DTypeOf[T](), WithBlobDeleter(pinner.Unpin))
+	if err != nil {
+		err = fmt.Errorf("FromSliceNoCopy() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustFromSliceNoCopy creates a tensor sharing memory with a Go slice. It panics if error.
+func MustFromSliceNoCopy[T Element](data []T, shape ...int64) *Tensor {
+	x, err := FromSliceNoCopy(data, shape...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
@@ -0,0 +1,63 @@
+package ts_test
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestFromSliceNoCopy(t *testing.T) {
+	data := []float32{1, 2, 3, 4, 5, 6}
+	x := ts.MustFromSliceNoCopy(data, 2, 3)
+	if got, want := x.MustSize(), []int64{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected shape %v, got %v", want, got)
+	}
+
+	// Writes to either are visible in the other.
+	data[0] = 10
+	if got := ts.MustAt[float32](x, 0, 0); got != 10 {
+		t.Errorf("Expected 10, got %v", got)
+	}
+	x.MustMulScalar_(ts.FloatScalar(2))
+	if got, want := data, []float32{20, 4, 6, 8, 10, 12}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	x.MustDrop()
+
+	if _, err := ts.FromSliceNoCopy(data, 4, 2); err == nil {
+		t.Errorf("Expected error for mismatched shape")
+	}
+}
+
+func TestFromBlob(t *testing.T) {
+	data := []int64{1, 2, 3, 4, 5, 6}
+	var pinner runtime.Pinner
+	pinner.Pin(&data[0])
+
+	released := 0
+	deleter := func() {
+		released++
+		pinner.Unpin()
+	}
+
+	// Transposed view over row-major 2x3 data.
+	x := ts.MustFromBlob(unsafe.Pointer(&data[0]), []int64{3, 2}, gotch.Int64, ts.WithBlobStrides([]int64{1, 3}), ts.WithBlobDeleter(deleter))
+	if got, want := ts.MustToSlice[int64](x.MustContiguous(false)), []int64{1, 4, 2, 5, 3, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Memory is released once all tensors sharing storage are freed.
+	view := x.MustT(false)
+	x.MustDrop()
+	if released != 0 {
+		t.Errorf("Expected memory alive while a view exists")
+	}
+	view.MustDrop()
+	if released != 1 {
+		t.Errorf("Expected deleter called once, got %d", released)
+	}
+}
@@ -0,0 +1,84 @@
+package ts
+
+// Zero-copy exchange of tensors with other frameworks via DLPack.
+
+import (
+	"fmt"
+	"log"
+	"unsafe"
+
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+// ToDLPack exports tensor as a DLPack `DLManagedTensor*` sharing memory with the tensor.
+//
+// Ownership of the returned DLManagedTensor is transferred to the caller, which must pass
+// it to exactly one consumer (e.g. `FromDLPack` or a C library) that calls its deleter once
+// done, or release it with `FreeDLPack`. The memory stays valid until then, even if the
+// tensor is dropped.
+func (ts *Tensor) ToDLPack() (unsafe.Pointer, error) {
+	dl := lib.AtToDLPack(ts.ctensor)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("ToDLPack() failed: %w", err)
+		return nil, err
+	}
+
+	return dl, nil
+}
+
+// MustToDLPack exports tensor as a DLPack `DLManagedTensor*`. It panics if error.
+func (ts *Tensor) MustToDLPack() unsafe.Pointer {
+	dl, err := ts.ToDLPack()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return dl
+}
+
+// FromDLPack creates a tensor sharing memory with a DLPack `DLManagedTensor*` produced by
+// another framework, without copying.
+//
+// On success the tensor takes ownership of `dl`: its deleter is called when the tensor and
+// all tensors sharing its storage have been freed, and `dl` must not be used or freed by
+// the caller anymore. On error, ownership stays with the caller.
+func FromDLPack(dl unsafe.Pointer) (*Tensor, error) {
+	if dl == nil {
+		err := fmt.Errorf("FromDLPack() failed: nil DLManagedTensor")
+		return nil, err
+	}
+
+	ctensor := lib.AtFromDLPack(dl)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("FromDLPack() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustFromDLPack creates a tensor from a DLPack `DLManagedTensor*`. It panics if error.
+func MustFromDLPack(dl unsafe.Pointer) *Tensor {
+	x, err := FromDLPack(dl)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// FreeDLPack releases a DLPack `DLManagedTensor*` which has not been consumed by calling
+// its deleter.
+func FreeDLPack(dl unsafe.Pointer) error {
+	if dl == nil {
+		return nil
+	}
+
+	lib.AtDLPackFree(dl)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("FreeDLPack() failed: %w", err)
+		return err
+	}
+
+	return nil
+}
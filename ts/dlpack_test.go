@@ -0,0 +1,75 @@
+package ts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch/ts"
+	"github.com/nullbull/gotch/ts/internal/dlpacktest"
+)
+
+func TestToDLPack(t *testing.T) {
+	x := ts.MustFromSlice([]float32{1, 2, 3, 4, 5, 6}, 2, 3)
+	dl := x.MustToDLPack()
+
+	// Memory is owned by the DLPack tensor until consumed.
+	x.MustDrop()
+	if got, want := dlpacktest.SumFloat32(dl), 21.0; got != want {
+		t.Errorf("Expected sum %v, got %v", want, got)
+	}
+
+	// Non-contiguous tensor is exported with strides.
+	y := ts.MustFromSlice([]float32{1, 2, 3, 4, 5, 6}, 2, 3)
+	yt := y.MustT(true)
+	col := yt.MustSelect(0, 0, true) // [1, 4] with stride 3
+	if got, want := dlpacktest.SumFloat32(col.MustToDLPack()), 5.0; got != want {
+		t.Errorf("Expected sum %v, got %v", want, got)
+	}
+	col.MustDrop()
+
+	// Unconsumed DLPack tensor.
+	z := ts.MustFromSlice([]float32{1})
+	if err := ts.FreeDLPack(z.MustToDLPack()); err != nil {
+		t.Error(err)
+	}
+	z.MustDrop()
+}
+
+func TestFromDLPack(t *testing.T) {
+	deleted := dlpacktest.Deleted()
+	x := ts.MustFromDLPack(dlpacktest.NewFloat32([]float32{1, 2, 3, 4}, 2, 2))
+	if got, want := x.MustSize(), []int64{2, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected shape %v, got %v", want, got)
+	}
+	if got, want := ts.MustToSlice[float32](x), []float32{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := dlpacktest.Deleted(); got != deleted {
+		t.Errorf("Expected DLPack tensor alive while tensor is alive")
+	}
+	x.MustDrop()
+	if got, want := dlpacktest.Deleted(), deleted+1; got != want {
+		t.Errorf("Expected %d deleted DLPack tensors, got %d", want, got)
+	}
+
+	if _, err := ts.FromDLPack(nil); err == nil {
+		t.Errorf("Expected error for nil DLPack tensor")
+	}
+}
+
+func TestDLPackRoundTrip(t *testing.T) {
+	x := ts.MustFromSlice([]float32{1, 2, 3}, 3)
+	y := ts.MustFromDLPack(x.MustToDLPack())
+
+	// Memory is shared.
+	x.MustFill_(ts.FloatScalar(7))
+	if got, want := ts.MustToSlice[float32](y), []float32{7, 7, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	x.MustDrop()
+	if got, want := ts.MustToSlice[float32](y), []float32{7, 7, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	y.MustDrop()
+}
@@ -0,0 +1,152 @@
+package ts
+
+// Custom autograd functions with forward and backward defined in Go.
+
+import (
+	"fmt"
+	"log"
+
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+// FunctionCtx is the context of a custom Function call shared by its forward and backward.
+type FunctionCtx struct {
+	saved          []*Tensor
+	needsInputGrad []bool
+}
+
+// SaveForBackward saves tensors, e.g. inputs or outputs, for use in backward.
+func (ctx *FunctionCtx) SaveForBackward(tensors ...*Tensor) {
+	ctx.saved = append(ctx.saved, tensors...)
+}
+
+// SavedTensors returns tensors saved in forward.
+func (ctx *FunctionCtx) SavedTensors() []*Tensor {
+	return ctx.saved
+}
+
+// NeedsInputGrad returns whether gradient with respect to input i is required.
+func (ctx *FunctionCtx) NeedsInputGrad(i int) bool {
+	return ctx.needsInputGrad[i]
+}
+
+// FunctionForward computes outputs of a custom Function from its inputs. It runs with
+// gradient disabled.
+type FunctionForward func(ctx *FunctionCtx, inputs []*Tensor) []*Tensor
+
+// FunctionBackward computes gradients with respect to inputs of a custom Function, one
+// for each input or nil for no gradient, from gradients with respect to its outputs.
+// Gradients of non-differentiable (e.g. integer) outputs are nil.
+//
+// NOTE. It is called during backward pass, possibly from a libtorch autograd thread.
+type FunctionBackward func(ctx *FunctionCtx, gradOutputs []*Tensor) []*Tensor
+
+// Function is a differentiable op with custom backward, e.g. a straight-through estimator.
+//
+// Example: gradient reversal
+//
+//	reverse := ts.NewFunction(
+//		func(ctx *ts.FunctionCtx, xs []*ts.Tensor) []*ts.Tensor {
+//			return []*ts.Tensor{xs[0].MustShallowClone()}
+//		},
+//		func(ctx *ts.FunctionCtx, grads []*ts.Tensor) []*ts.Tensor {
+//			return []*ts.Tensor{grads[0].MustNeg(false)}
+//		},
+//	)
+//	ys := reverse.MustApply(xs)[0]
+type Function struct {
+	forward  FunctionForward
+	backward FunctionBackward
+}
+
+// NewFunction creates a custom Function.
+func NewFunction(forward FunctionForward, backward FunctionBackward) *Function {
+	return &Function{forward: forward, backward: backward}
+}
+
+// Apply runs forward of the function and connects its outputs to the autograd graph
+// so that backward is called in `Backward()`, `RunBackward()` or `Grad()`.
+func (f *Function) Apply(inputs ...*Tensor) ([]*Tensor, error) {
+	gradEnabled := IsGradEnabled()
+	ctx := &FunctionCtx{needsInputGrad: make([]bool, len(inputs))}
+	cinputs := make([]lib.Ctensor, len(inputs))
+	for i, x := range inputs {
+		ctx.needsInputGrad[i] = gradEnabled && x.MustRequiresGrad()
+		cinputs[i] = x.ctensor
+	}
+
+	var (
+		outputs []*Tensor
+		err     error
+	)
+	WithGradMode(false, func() {
+		err = recoverErr(func() {
+			outputs = f.forward(ctx, inputs)
+		})
+	})
+	if err == nil && len(outputs) == 0 {
+		err = fmt.Errorf("forward returned no outputs")
+	}
+	if err != nil {
+		err = fmt.Errorf("Function.Apply() failed: %w", err)
+		return nil, err
+	}
+
+	backward := func(cgrads []lib.Ctensor) ([]lib.Ctensor, error) {
+		grads := make([]*Tensor, len(cgrads))
+		for i, cgrad := range cgrads {
+			if cgrad != nil {
+				grads[i] = newTensor(cgrad)
+			}
+		}
+
+		var gradInputs []*Tensor
+		if err := recoverErr(func() {
+			gradInputs = f.backward(ctx, grads)
+		}); err != nil {
+			return nil, err
+		}
+		if len(gradInputs) != len(inputs) {
+			err := fmt.Errorf("backward returned %d gradients, expected %d", len(gradInputs), len(inputs))
+			return nil, err
+		}
+
+		// NOTE. libtorch takes ownership of returned C tensors.
+		results := make([]lib.Ctensor, len(gradInputs))
+		for i, g := range gradInputs {
+			if g != nil {
+				results[i] = lib.AtShallowClone(g.ctensor)
+			}
+		}
+
+		return results, nil
+	}
+
+	coutputs := make([]lib.Ctensor, len(outputs))
+	for i, y := range outputs {
+		coutputs[i] = y.ctensor
+	}
+	cresults := make([]lib.Ctensor, len(outputs))
+	lib.AtFunctionApply(cinputs, coutputs, cresults, backward)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Function.Apply() failed: %w", err)
+		return nil, err
+	}
+
+	results := make([]*Tensor, len(cresults))
+	for i, cresult := range cresults {
+		results[i] = newTensor(cresult)
+	}
+
+	return results, nil
+}
+
+// MustApply runs the function. It panics if error.
+func (f *Function) MustApply(inputs ...*Tensor) []*Tensor {
+	outputs, err := f.Apply(inputs...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return outputs
+}
@@ -0,0 +1,108 @@
+package ts_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+// straight-through estimator of rounding.
+var roundSTE = ts.NewFunction(
+	func(ctx *ts.FunctionCtx, xs []*ts.Tensor) []*ts.Tensor {
+		return []*ts.Tensor{xs[0].MustRound(false)}
+	},
+	func(ctx *ts.FunctionCtx, grads []*ts.Tensor) []*ts.Tensor {
+		return []*ts.Tensor{grads[0]}
+	},
+)
+
+// square with saved input.
+var customSquare = ts.NewFunction(
+	func(ctx *ts.FunctionCtx, xs []*ts.Tensor) []*ts.Tensor {
+		ctx.SaveForBackward(xs[0])
+		return []*ts.Tensor{xs[0].MustMul(xs[0], false)}
+	},
+	func(ctx *ts.FunctionCtx, grads []*ts.Tensor) []*ts.Tensor {
+		x := ctx.SavedTensors()[0]
+		return []*ts.Tensor{grads[0].MustMul(x, false).MustMulScalar(ts.FloatScalar(2.0), true)}
+	},
+)
+
+func TestFunction(t *testing.T) {
+	x := ts.MustOfSlice([]float64{0.4, 1.6, -2.2})
+	x.MustRequiresGrad_(true)
+
+	y := roundSTE.MustApply(x)[0]
+	assertFloat64s(t, "rounded", []float64{0, 2, -2}, y)
+	y.MustMulScalar(ts.FloatScalar(3.0), false).MustSum(y.DType(), true).MustBackward()
+	assertFloat64s(t, "straight-through gradient", []float64{3, 3, 3}, x.MustGrad(false))
+
+	// gradient reversal of an output aliasing input.
+	reverse := ts.NewFunction(
+		func(ctx *ts.FunctionCtx, xs []*ts.Tensor) []*ts.Tensor {
+			return []*ts.Tensor{xs[0]}
+		},
+		func(ctx *ts.FunctionCtx, grads []*ts.Tensor) []*ts.Tensor {
+			return []*ts.Tensor{grads[0].MustNeg(false)}
+		},
+	)
+	x.ZeroGrad()
+	reverse.MustApply(x)[0].MustSum(x.DType(), true).MustBackward()
+	assertFloat64s(t, "reversed gradient", []float64{-1, -1, -1}, x.MustGrad(false))
+
+	r, err := ts.GradCheck(func(x *ts.Tensor) *ts.Tensor { return customSquare.MustApply(x)[0] }, []*ts.Tensor{x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Passed {
+		t.Errorf("Expected gradient check of custom function passed:\n%v", r)
+	}
+
+	// wrong number of gradients is reported by backward pass.
+	bad := ts.NewFunction(
+		func(ctx *ts.FunctionCtx, xs []*ts.Tensor) []*ts.Tensor {
+			return []*ts.Tensor{xs[0].MustShallowClone()}
+		},
+		func(ctx *ts.FunctionCtx, grads []*ts.Tensor) []*ts.Tensor {
+			return nil
+		},
+	)
+	if err := bad.MustApply(x)[0].MustSum(x.DType(), true).Backward(); err == nil {
+		t.Errorf("Expected error of backward returning no gradients\n")
+	}
+
+	// no graph is built without gradient.
+	ts.NoGrad(func() {
+		if y := customSquare.MustApply(x)[0]; y.MustRequiresGrad() {
+			t.Errorf("Expected output not requiring gradient in NoGrad\n")
+		}
+	})
+}
+
+func TestFunctionConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan string, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(v float64) {
+			defer wg.Done()
+			for n := 0; n < 10; n++ {
+				x := ts.MustOfSlice([]float64{v, -v})
+				x.MustRequiresGrad_(true)
+				customSquare.MustApply(x)[0].MustSum(x.DType(), true).MustBackward()
+				got := x.MustGrad(false).Float64Values()
+				if got[0] != 2*v || got[1] != -2*v {
+					errs <- "unexpected gradient"
+					return
+				}
+			}
+		}(float64(i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
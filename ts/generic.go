@@ -0,0 +1,241 @@
+package ts
+
+// Generic tensor constructors and accessors without reflection.
+
+import (
+	"fmt"
+	"log"
+	"unsafe"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/half"
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+// Element is a Go type with the same memory layout as tensor elements of a gotch.DType.
+//
+// NOTE. Go `int` and `uint` are not included as their sizes are platform-dependent.
+type Element interface {
+	bool | uint8 | int8 | int16 | int32 | int64 |
+		float32 | float64 | complex64 | complex128 |
+		half.Float16 | half.BFloat16
+}
+
+// DTypeOf returns the dtype of tensor with elements of type T.
+func DTypeOf[T Element]() gotch.DType {
+	var zero T
+	switch any(zero).(type) {
+	case bool:
+		return gotch.Bool
+	case uint8:
+		return gotch.Uint8
+	case int8:
+		return gotch.Int8
+	case int16:
+		return gotch.Int16
+	case int32:
+		return gotch.Int
+	case int64:
+		return gotch.Int64
+	case float32:
+		return gotch.Float
+	case float64:
+		return gotch.Double
+	case complex64:
+		return gotch.ComplexFloat
+	case complex128:
+		return gotch.ComplexDouble
+	case half.Float16:
+		return gotch.Half
+	case half.BFloat16:
+		return gotch.BFloat16
+	}
+
+	return gotch.Invalid
+}
+
+// FromSlice creates a tensor of dtype `DTypeOf[T]()` from a slice of data. Default shape is
+// 1-D `[len(data)]`.
+//
+// Unlike OfSlice, data is copied to the tensor with a single memcpy, without reflection
+// and intermediate buffers.
+func FromSlice[T Element](data []T, shape ...int64) (*Tensor, error) {
+	if len(shape) == 0 {
+		shape = []int64{int64(len(data))}
+	}
+	numel := int64(1)
+	for _, d := range shape {
+		if d < 0 {
+			err := fmt.Errorf("FromSlice() failed: invalid shape %v", shape)
+			return nil, err
+		}
+		numel *= d
+	}
+	if numel != int64(len(data)) {
+		err := fmt.Errorf("FromSlice() failed: shape %v (%d elements) mismatched data length %d", shape, numel, len(data))
+		return nil, err
+	}
+
+	var dataPtr unsafe.Pointer
+	if len(data) > 0 {
+		dataPtr = unsafe.Pointer(&data[0])
+	}
+
+	dtype := DTypeOf[T]()
+	ctensor := lib.AtTensorOfData(dataPtr, shape, uint(len(shape)), dtype.Size(), int(dtype.CKind()))
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("FromSlice() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustFromSlice creates a tensor from a slice of data. It panics if error.
+func MustFromSlice[T Element](data []T, shape ...int64) *Tensor {
+	x, err := FromSlice(data, shape...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// ToSlice returns values of a tensor in a flat slice of T. The tensor is converted to
+// `DTypeOf[T]()` first if its dtype differs, and copied to CPU if on other device.
+func ToSlice[T Element](x *Tensor) ([]T, error) {
+	dtype := DTypeOf[T]()
+	src := x
+	if x.DType() != dtype {
+		var err error
+		src, err = x.Totype(dtype, false)
+		if err != nil {
+			err = fmt.Errorf("ToSlice() failed: %w", err)
+			return nil, err
+		}
+		defer src.MustDrop()
+	}
+
+	numel := src.Numel()
+	data := make([]T, numel)
+	if numel == 0 {
+		return data, nil
+	}
+
+	lib.AtCopyData(src.ctensor, unsafe.Pointer(&data[0]), numel, dtype.Size())
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("ToSlice() failed: %w", err)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// MustToSlice returns values of a tensor in a flat slice of T. It panics if error.
+func MustToSlice[T Element](x *Tensor) []T {
+	data, err := ToSlice[T](x)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return data
+}
+
+// At returns the element of a tensor at indexes, one for each dimension, as a value of type T.
+func At[T Element](x *Tensor, idx ...int64) (T, error) {
+	var (
+		zero    T
+		idxPtr  unsafe.Pointer
+		integer bool
+	)
+	switch any(zero).(type) {
+	case complex64, complex128:
+		return complexAt[T](x, idx)
+	case bool, uint8, int8, int16, int32, int64:
+		integer = true
+	}
+
+	if len(idx) > 0 {
+		idxPtr = unsafe.Pointer(&idx[0])
+	}
+
+	var (
+		i int64
+		f float64
+	)
+	if integer {
+		i = lib.AtInt64ValueAtIndexes(x.ctensor, idxPtr, len(idx))
+	} else {
+		f = lib.AtDoubleValueAtIndexes(x.ctensor, idxPtr, len(idx))
+	}
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("At() failed: %w", err)
+		return zero, err
+	}
+
+	var v any
+	switch any(zero).(type) {
+	case bool:
+		v = i != 0
+	case uint8:
+		v = uint8(i)
+	case int8:
+		v = int8(i)
+	case int16:
+		v = int16(i)
+	case int32:
+		v = int32(i)
+	case int64:
+		v = i
+	case float32:
+		v = float32(f)
+	case float64:
+		v = f
+	case half.Float16:
+		v = half.Fromfloat32(float32(f))
+	case half.BFloat16:
+		v = half.BFloat16(half.Float64ToBFloat16(f))
+	}
+
+	return v.(T), nil
+}
+
+// complexAt returns a complex element by selecting it as a 1-element tensor as libtorch
+// scalar accessors only return real values.
+func complexAt[T Element](x *Tensor, idx []int64) (T, error) {
+	var zero T
+	if len(idx) != int(x.Dim()) {
+		err := fmt.Errorf("At() failed: expected %d indexes, got %d", x.Dim(), len(idx))
+		return zero, err
+	}
+
+	elem := x.MustShallowClone()
+	for _, i := range idx {
+		next, err := elem.Select(0, i, true)
+		if err != nil {
+			elem.MustDrop()
+			err = fmt.Errorf("At() failed: %w", err)
+			return zero, err
+		}
+		elem = next
+	}
+	defer elem.MustDrop()
+
+	data, err := ToSlice[T](elem)
+	if err != nil {
+		err = fmt.Errorf("At() failed: %w", err)
+		return zero, err
+	}
+
+	return data[0], nil
+}
+
+// MustAt returns the element of a tensor at indexes. It panics if error.
+func MustAt[T Element](x *Tensor, idx ...int64) T {
+	v, err := At[T](x, idx...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return v
+}
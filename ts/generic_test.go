@@ -0,0 +1,87 @@
+package ts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/half"
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestFromSlice(t *testing.T) {
+	x := ts.MustFromSlice([]float32{1, 2, 3, 4, 5, 6}, 2, 3)
+	if got, want := x.DType(), gotch.Float; got != want {
+		t.Errorf("Expected dtype %v, got %v", want, got)
+	}
+	if got, want := x.MustSize(), []int64{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected shape %v, got %v", want, got)
+	}
+	if got, want := ts.MustToSlice[float32](x), []float32{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Conversion on dtype mismatch.
+	if got, want := ts.MustToSlice[int64](x), []int64{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := ts.FromSlice([]int64{1, 2, 3}, 2, 2); err == nil {
+		t.Errorf("Expected error for mismatched shape")
+	}
+
+	empty := ts.MustFromSlice([]float64{})
+	if got := ts.MustToSlice[float64](empty); len(got) != 0 {
+		t.Errorf("Expected empty slice, got %v", got)
+	}
+}
+
+func TestFromSliceDTypes(t *testing.T) {
+	checkRoundTrip(t, []bool{true, false, true}, gotch.Bool)
+	checkRoundTrip(t, []uint8{1, 2, 255}, gotch.Uint8)
+	checkRoundTrip(t, []int8{-1, 2, 127}, gotch.Int8)
+	checkRoundTrip(t, []int16{-1, 2, 300}, gotch.Int16)
+	checkRoundTrip(t, []int32{-1, 2, 70000}, gotch.Int)
+	checkRoundTrip(t, []int64{-1, 2, 1 << 40}, gotch.Int64)
+	checkRoundTrip(t, []float64{-1.5, 2, 1e10}, gotch.Double)
+	checkRoundTrip(t, []complex64{1 + 2i, -3i}, gotch.ComplexFloat)
+	checkRoundTrip(t, []complex128{1 + 2i, -3i}, gotch.ComplexDouble)
+	checkRoundTrip(t, []half.Float16{half.Fromfloat32(1.5), half.Fromfloat32(-2)}, gotch.Half)
+	checkRoundTrip(t, []half.BFloat16{half.BFloat16(half.Float32ToBFloat16(1.5))}, gotch.BFloat16)
+}
+
+func checkRoundTrip[T ts.Element](t *testing.T, data []T, dtype gotch.DType) {
+	t.Helper()
+	x := ts.MustFromSlice(data)
+	if x.DType() != dtype {
+		t.Errorf("Expected dtype %v, got %v", dtype, x.DType())
+	}
+	if got := ts.MustToSlice[T](x); !reflect.DeepEqual(got, data) {
+		t.Errorf("Expected %v, got %v", data, got)
+	}
+	x.MustDrop()
+}
+
+func TestAt(t *testing.T) {
+	x := ts.MustFromSlice([]int64{1, 2, 3, 4, 5, 6}, 2, 3)
+	if got := ts.MustAt[int64](x, 1, 2); got != 6 {
+		t.Errorf("Expected 6, got %v", got)
+	}
+	if got := ts.MustAt[float32](x, 0, 1); got != 2 {
+		t.Errorf("Expected 2, got %v", got)
+	}
+	if got := ts.MustAt[bool](x, 0, 0); !got {
+		t.Errorf("Expected true, got %v", got)
+	}
+	if _, err := ts.At[int64](x, 2, 0); err == nil {
+		t.Errorf("Expected error for out of range index")
+	}
+
+	c := ts.MustFromSlice([]complex128{1 + 2i, 3 - 4i}, 2)
+	if got := ts.MustAt[complex128](c, 1); got != 3-4i {
+		t.Errorf("Expected (3-4i), got %v", got)
+	}
+	if _, err := ts.At[complex128](c); err == nil {
+		t.Errorf("Expected error for missing indexes")
+	}
+}
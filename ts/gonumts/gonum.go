@@ -0,0 +1,193 @@
+// Package gonumts converts tensors to and from gonum matrices and vectors.
+//
+// It is a separate package so that gonum is only a dependency of programs using it.
+package gonumts
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+	"gonum.org/v1/gonum/mat"
+)
+
+// FromDense creates a 2-D tensor of shape [rows, cols] and given dtype from a gonum matrix.
+// Values are copied. Dtype must be a real (non-complex) dtype.
+func FromDense(m mat.Matrix, dtype gotch.DType) (*ts.Tensor, error) {
+	r, c := m.Dims()
+	data := make([]float64, r*c)
+	switch m := m.(type) {
+	case mat.RawMatrixer:
+		raw := m.RawMatrix()
+		for i := 0; i < r; i++ {
+			copy(data[i*c:(i+1)*c], raw.Data[i*raw.Stride:i*raw.Stride+c])
+		}
+	default:
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				data[i*c+j] = m.At(i, j)
+			}
+		}
+	}
+
+	x, err := fromFloat64s(data, []int64{int64(r), int64(c)}, dtype)
+	if err != nil {
+		err = fmt.Errorf("FromDense() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustFromDense creates a 2-D tensor from a gonum matrix. It panics if error.
+func MustFromDense(m mat.Matrix, dtype gotch.DType) *ts.Tensor {
+	x, err := FromDense(m, dtype)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// FromVecDense creates a 1-D tensor of given dtype from a gonum vector. Values are copied.
+func FromVecDense(v mat.Vector, dtype gotch.DType) (*ts.Tensor, error) {
+	n := v.Len()
+	data := make([]float64, n)
+	switch v := v.(type) {
+	case mat.RawVectorer:
+		raw := v.RawVector()
+		for i := 0; i < n; i++ {
+			data[i] = raw.Data[i*raw.Inc]
+		}
+	default:
+		for i := 0; i < n; i++ {
+			data[i] = v.AtVec(i)
+		}
+	}
+
+	x, err := fromFloat64s(data, []int64{int64(n)}, dtype)
+	if err != nil {
+		err = fmt.Errorf("FromVecDense() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustFromVecDense creates a 1-D tensor from a gonum vector. It panics if error.
+func MustFromVecDense(v mat.Vector, dtype gotch.DType) *ts.Tensor {
+	x, err := FromVecDense(v, dtype)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// ToDense returns values of a 2-D tensor of real dtype in a new gonum matrix. Values are
+// converted to float64 and copied to CPU if on other device.
+func ToDense(x *ts.Tensor) (*mat.Dense, error) {
+	shape, err := x.Size()
+	if err != nil {
+		err = fmt.Errorf("ToDense() failed: %w", err)
+		return nil, err
+	}
+	if len(shape) != 2 {
+		err = fmt.Errorf("ToDense() failed: expected 2-D tensor, got shape %v", shape)
+		return nil, err
+	}
+
+	data, err := toFloat64s(x)
+	if err != nil {
+		err = fmt.Errorf("ToDense() failed: %w", err)
+		return nil, err
+	}
+	if len(data) == 0 {
+		// gonum does not support zero-sized matrices.
+		err = fmt.Errorf("ToDense() failed: zero-sized tensor of shape %v", shape)
+		return nil, err
+	}
+
+	return mat.NewDense(int(shape[0]), int(shape[1]), data), nil
+}
+
+// MustToDense returns values of a 2-D tensor in a new gonum matrix. It panics if error.
+func MustToDense(x *ts.Tensor) *mat.Dense {
+	m, err := ToDense(x)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return m
+}
+
+// ToVecDense returns values of a 1-D tensor of real dtype in a new gonum vector. Values are
+// converted to float64 and copied to CPU if on other device.
+func ToVecDense(x *ts.Tensor) (*mat.VecDense, error) {
+	shape, err := x.Size()
+	if err != nil {
+		err = fmt.Errorf("ToVecDense() failed: %w", err)
+		return nil, err
+	}
+	if len(shape) != 1 {
+		err = fmt.Errorf("ToVecDense() failed: expected 1-D tensor, got shape %v", shape)
+		return nil, err
+	}
+
+	data, err := toFloat64s(x)
+	if err != nil {
+		err = fmt.Errorf("ToVecDense() failed: %w", err)
+		return nil, err
+	}
+	if len(data) == 0 {
+		err = fmt.Errorf("ToVecDense() failed: zero-sized tensor of shape %v", shape)
+		return nil, err
+	}
+
+	return mat.NewVecDense(len(data), data), nil
+}
+
+// MustToVecDense returns values of a 1-D tensor in a new gonum vector. It panics if error.
+func MustToVecDense(x *ts.Tensor) *mat.VecDense {
+	v, err := ToVecDense(x)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return v
+}
+
+// fromFloat64s creates a tensor of dtype from float64 data.
+func fromFloat64s(data []float64, shape []int64, dtype gotch.DType) (*ts.Tensor, error) {
+	if isComplexDType(dtype) {
+		err := fmt.Errorf("unsupported dtype %v", dtype)
+		return nil, err
+	}
+
+	x, err := ts.FromSlice(data, shape...)
+	if err != nil || dtype == gotch.Double {
+		return x, err
+	}
+
+	return x.Totype(dtype, true)
+}
+
+// toFloat64s returns values of a real tensor as float64.
+func toFloat64s(x *ts.Tensor) ([]float64, error) {
+	if dtype := x.DType(); isComplexDType(dtype) {
+		err := fmt.Errorf("unsupported dtype %v", dtype)
+		return nil, err
+	}
+
+	return ts.ToSlice[float64](x)
+}
+
+func isComplexDType(dtype gotch.DType) bool {
+	switch dtype {
+	case gotch.ComplexHalf, gotch.ComplexFloat, gotch.ComplexDouble:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package gonumts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+	"github.com/nullbull/gotch/ts/gonumts"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestDense(t *testing.T) {
+	m := mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	x := gonumts.MustFromDense(m, gotch.Float)
+	if got, want := x.DType(), gotch.Float; got != want {
+		t.Errorf("Expected dtype %v, got %v", want, got)
+	}
+	if got, want := x.MustSize(), []int64{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected shape %v, got %v", want, got)
+	}
+	if got, want := ts.MustToSlice[float32](x), []float32{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := gonumts.MustToDense(x); !mat.Equal(got, m) {
+		t.Errorf("Expected %v, got %v", mat.Formatted(m), mat.Formatted(got))
+	}
+
+	// Strided submatrix and transposed view.
+	sub := m.Slice(0, 2, 1, 3)
+	y := gonumts.MustFromDense(sub, gotch.Double)
+	if got, want := ts.MustToSlice[float64](y), []float64{2, 3, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	z := gonumts.MustFromDense(m.T(), gotch.Int64)
+	if got, want := ts.MustToSlice[int64](z), []int64{1, 4, 2, 5, 3, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := gonumts.ToDense(ts.MustFromSlice([]float64{1, 2})); err == nil {
+		t.Errorf("Expected error for 1-D tensor")
+	}
+	if _, err := gonumts.ToDense(ts.MustFromSlice([]complex64{1, 2}, 1, 2)); err == nil {
+		t.Errorf("Expected error for complex tensor")
+	}
+}
+
+func TestVecDense(t *testing.T) {
+	v := mat.NewVecDense(3, []float64{1.5, -2, 3})
+	x := gonumts.MustFromVecDense(v, gotch.Double)
+	if got, want := x.MustSize(), []int64{3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected shape %v, got %v", want, got)
+	}
+	if got := gonumts.MustToVecDense(x); !mat.Equal(got, v) {
+		t.Errorf("Expected %v, got %v", mat.Formatted(v), mat.Formatted(got))
+	}
+
+	// Column of a matrix has a stride.
+	m := mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+	col := gonumts.MustFromVecDense(m.ColView(1), gotch.Int)
+	if got, want := ts.MustToSlice[int32](col), []int32{2, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
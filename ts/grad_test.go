@@ -0,0 +1,90 @@
+package ts_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestGradModeScopes(t *testing.T) {
+	ts.NoGrad(func() {
+		ts.NoGrad(func() {})
+		if ts.IsGradEnabled() {
+			t.Errorf("Expected grad disabled after nested NoGrad")
+		}
+
+		ts.EnableGrad(func() {
+			if !ts.IsGradEnabled() {
+				t.Errorf("Expected grad enabled in EnableGrad")
+			}
+		})
+		if ts.IsGradEnabled() {
+			t.Errorf("Expected grad disabled after EnableGrad")
+		}
+	})
+
+	// state is restored on panic
+	func() {
+		defer func() { recover() }()
+		ts.NoGrad(func() {
+			panic("panic inside NoGrad")
+		})
+	}()
+	ts.WithGradMode(true, func() {
+		ts.NoGrad(func() {
+			ts.InferenceMode(func() {
+				if !ts.IsInferenceModeEnabled() {
+					t.Errorf("Expected inference mode enabled")
+				}
+			})
+			if ts.IsInferenceModeEnabled() {
+				t.Errorf("Expected inference mode disabled after InferenceMode")
+			}
+		})
+		if !ts.IsGradEnabled() {
+			t.Errorf("Expected grad enabled after NoGrad")
+		}
+	})
+}
+
+func TestGradModeConcurrent(t *testing.T) {
+	x := ts.MustOnes([]int64{4}, gotch.Float, gotch.CPU)
+	x.MustRequiresGrad_(true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				switch i % 3 {
+				case 0: // evaluation
+					ts.NoGrad(func() {
+						y := x.MustMulScalar(ts.FloatScalar(2.0), false)
+						if y.MustRequiresGrad() {
+							t.Errorf("Expected no grad inside NoGrad")
+						}
+						y.MustDrop()
+					})
+				case 1: // inference
+					ts.InferenceMode(func() {
+						y := x.MustMulScalar(ts.FloatScalar(2.0), false)
+						if y.MustRequiresGrad() || !ts.IsInferenceModeEnabled() {
+							t.Errorf("Expected inference mode inside InferenceMode")
+						}
+						y.MustDrop()
+					})
+				default: // training
+					y := x.MustMulScalar(ts.FloatScalar(2.0), false)
+					if !y.MustRequiresGrad() {
+						t.Errorf("Expected grad in training goroutine")
+					}
+					y.MustDrop()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,272 @@
+package ts
+
+// Numerical gradient checking.
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/nullbull/gotch"
+)
+
+// GradCheckOpts holds options of GradCheck and GradGradCheck.
+type GradCheckOpts struct {
+	Eps   float64 // perturbation of finite differences. Default=1e-6
+	Atol  float64 // absolute tolerance. Default=1e-5
+	Rtol  float64 // relative tolerance. Default=1e-3
+	Train bool    // train mode of ts.ModuleT. Default=false
+}
+
+type GradCheckOpt func(*GradCheckOpts)
+
+func defaultGradCheckOpts() *GradCheckOpts {
+	return &GradCheckOpts{
+		Eps:   1e-6,
+		Atol:  1e-5,
+		Rtol:  1e-3,
+		Train: false,
+	}
+}
+
+func WithGradCheckEps(v float64) GradCheckOpt {
+	return func(o *GradCheckOpts) {
+		o.Eps = v
+	}
+}
+
+func WithGradCheckAtol(v float64) GradCheckOpt {
+	return func(o *GradCheckOpts) {
+		o.Atol = v
+	}
+}
+
+func WithGradCheckRtol(v float64) GradCheckOpt {
+	return func(o *GradCheckOpts) {
+		o.Rtol = v
+	}
+}
+
+func WithGradCheckTrain(v bool) GradCheckOpt {
+	return func(o *GradCheckOpts) {
+		o.Train = v
+	}
+}
+
+// GradCheckInput holds errors of analytical gradients with respect to an input.
+type GradCheckInput struct {
+	// Max absolute and relative errors over all outputs for each input element.
+	AbsErr []float64
+	RelErr []float64
+
+	MaxAbsErr float64
+	MaxRelErr float64
+	// Number of Jacobian elements exceeding tolerance `Atol + Rtol * |numerical|`.
+	NumFailed int
+}
+
+// GradCheckResult holds result of GradCheck or GradGradCheck.
+type GradCheckResult struct {
+	Inputs []GradCheckInput
+	Passed bool
+}
+
+// String returns a summary of errors for each input.
+func (r *GradCheckResult) String() string {
+	var msg string
+	for i, in := range r.Inputs {
+		msg += fmt.Sprintf("input %d: max abs err %.3e, max rel err %.3e, %d failed\n", i, in.MaxAbsErr, in.MaxRelErr, in.NumFailed)
+	}
+	if r.Passed {
+		msg += "gradient check passed\n"
+	} else {
+		msg += "gradient check failed\n"
+	}
+
+	return msg
+}
+
+// gradCheckFn converts `fn` to a function over tensors. `fn` can be one of
+// `func([]*Tensor) []*Tensor`, `func(*Tensor) *Tensor`, `ModuleT` or `Module`.
+func gradCheckFn(fn interface{}, ninputs int, train bool) (func([]*Tensor) []*Tensor, error) {
+	var f func(*Tensor) *Tensor
+	switch m := fn.(type) {
+	case func([]*Tensor) []*Tensor:
+		return m, nil
+	case func(*Tensor) *Tensor:
+		f = m
+	case ModuleT:
+		f = func(xs *Tensor) *Tensor { return m.ForwardT(xs, train) }
+	case Module:
+		f = m.Forward
+	default:
+		err := fmt.Errorf("Unsupported function type %T. Expected func([]*Tensor) []*Tensor, func(*Tensor) *Tensor, ts.Module or ts.ModuleT.", fn)
+		return nil, err
+	}
+
+	if ninputs != 1 {
+		err := fmt.Errorf("Expected a single input for function of type %T, got %d.", fn, ninputs)
+		return nil, err
+	}
+
+	return func(xs []*Tensor) []*Tensor { return []*Tensor{f(xs[0])} }, nil
+}
+
+// GradCheck compares gradients computed by autograd with finite differences in float64
+// with respect to all inputs. `fn` can be `func([]*Tensor) []*Tensor`, `func(*Tensor) *Tensor`,
+// `ts.Module` or `ts.ModuleT`.
+//
+// Inputs are converted to float64. Parameters of modules should be float64 too, e.g. created
+// in a `nn.VarStore` converted with `ToDouble()`.
+func GradCheck(fn interface{}, inputs []*Tensor, opts ...GradCheckOpt) (*GradCheckResult, error) {
+	o := defaultGradCheckOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	f, err := gradCheckFn(fn, len(inputs), o.Train)
+	if err != nil {
+		err = fmt.Errorf("GradCheck() failed: %w", err)
+		return nil, err
+	}
+
+	return gradCheck(f, toDouble(inputs), o)
+}
+
+// GradGradCheck checks second order gradients, i.e. gradients of vector-Jacobian products
+// of `fn` with random vectors, with finite differences. See GradCheck.
+func GradGradCheck(fn interface{}, inputs []*Tensor, opts ...GradCheckOpt) (*GradCheckResult, error) {
+	o := defaultGradCheckOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	f, err := gradCheckFn(fn, len(inputs), o.Train)
+	if err != nil {
+		err = fmt.Errorf("GradGradCheck() failed: %w", err)
+		return nil, err
+	}
+
+	xs := toDouble(inputs)
+	var vs []*Tensor
+	NoGrad(func() {
+		for _, y := range f(xs) {
+			vs = append(vs, y.MustRandnLike(false))
+		}
+	})
+
+	var vjpErr error
+	gradFn := func(xs []*Tensor) []*Tensor {
+		_, vjp, err := VJP(f, xs, vs, WithCreateGraph(true))
+		if err != nil {
+			vjpErr = err
+		}
+		return vjp
+	}
+
+	r, err := gradCheck(gradFn, xs, o)
+	if vjpErr != nil {
+		err = vjpErr
+	}
+	if err != nil {
+		err = fmt.Errorf("GradGradCheck() failed: %w", err)
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func toDouble(inputs []*Tensor) []*Tensor {
+	xs := make([]*Tensor, len(inputs))
+	for i, x := range inputs {
+		xs[i] = x.MustDetach(false).MustTotype(gotch.Double, true)
+	}
+
+	return xs
+}
+
+// numericalJacobian returns Jacobian of `f` with respect to input j by central differences.
+// Element [i][k*m+l] is derivative of element k of output i with respect to element l of input j
+// where m is number of elements of the input.
+func numericalJacobian(f func([]*Tensor) []*Tensor, xs []*Tensor, j int, eps float64) [][]float64 {
+	x := xs[j]
+	vals := x.Float64Values()
+	m := len(vals)
+
+	eval := func(l int, delta float64) [][]float64 {
+		perturbed := append([]float64{}, vals...)
+		perturbed[l] += delta
+		args := append([]*Tensor{}, xs...)
+		args[j] = MustOfSlice(perturbed).MustViewAs(x, true)
+
+		var outs [][]float64
+		for _, y := range f(args) {
+			outs = append(outs, y.Float64Values())
+		}
+		args[j].MustDrop()
+
+		return outs
+	}
+
+	var jac [][]float64
+	for l := 0; l < m; l++ {
+		plus := eval(l, eps)
+		minus := eval(l, -eps)
+		if jac == nil {
+			jac = make([][]float64, len(plus))
+			for i := range plus {
+				jac[i] = make([]float64, len(plus[i])*m)
+			}
+		}
+		for i := range plus {
+			for k := range plus[i] {
+				jac[i][k*m+l] = (plus[i][k] - minus[i][k]) / (2 * eps)
+			}
+		}
+	}
+
+	return jac
+}
+
+func gradCheck(f func([]*Tensor) []*Tensor, xs []*Tensor, o *GradCheckOpts) (*GradCheckResult, error) {
+	analytical, err := Jacobian(f, xs)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &GradCheckResult{Inputs: make([]GradCheckInput, len(xs)), Passed: true}
+	for j, x := range xs {
+		m := int(x.Numel())
+		in := GradCheckInput{AbsErr: make([]float64, m), RelErr: make([]float64, m)}
+		if m == 0 {
+			r.Inputs[j] = in
+			continue
+		}
+		numerical := numericalJacobian(f, xs, j, o.Eps)
+		for i := range analytical {
+			a := analytical[i][j].Float64Values()
+			for idx, n := range numerical[i] {
+				l := idx % m
+				absErr := math.Abs(a[idx] - n)
+				relErr := 0.0
+				if scale := math.Max(math.Abs(a[idx]), math.Abs(n)); scale > 0 {
+					relErr = absErr / scale
+				}
+				in.AbsErr[l] = math.Max(in.AbsErr[l], absErr)
+				in.RelErr[l] = math.Max(in.RelErr[l], relErr)
+				if absErr > o.Atol+o.Rtol*math.Abs(n) || math.IsNaN(absErr) {
+					in.NumFailed++
+				}
+			}
+		}
+		for l := range in.AbsErr {
+			in.MaxAbsErr = math.Max(in.MaxAbsErr, in.AbsErr[l])
+			in.MaxRelErr = math.Max(in.MaxRelErr, in.RelErr[l])
+		}
+		if in.NumFailed > 0 {
+			r.Passed = false
+		}
+		r.Inputs[j] = in
+	}
+
+	return r, nil
+}
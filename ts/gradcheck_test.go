@@ -0,0 +1,51 @@
+package ts_test
+
+import (
+	"testing"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestGradCheck(t *testing.T) {
+	x := ts.MustOfSlice([]float32{0.5, -1.0, 2.0})
+	y := ts.MustOfSlice([]float32{1.5, 0.3, -0.7})
+
+	mulAdd := func(xs []*ts.Tensor) []*ts.Tensor {
+		return []*ts.Tensor{xs[0].MustMul(xs[1], false).MustAdd(xs[0].MustMul(xs[0], false), true)}
+	}
+	r, err := ts.GradCheck(mulAdd, []*ts.Tensor{x, y})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Passed || len(r.Inputs) != 2 || len(r.Inputs[0].AbsErr) != 3 {
+		t.Errorf("Expected gradient check passed for 2 inputs of 3 elements:\n%v", r)
+	}
+
+	r, err = ts.GradGradCheck(mulAdd, []*ts.Tensor{x, y})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Passed {
+		t.Errorf("Expected second order gradient check passed:\n%v", r)
+	}
+
+	// wrong analytical gradient is detected.
+	wrong := func(x *ts.Tensor) *ts.Tensor {
+		out := x.MustMul(x, false)
+		out.MustRegisterHook(func(grad *ts.Tensor) *ts.Tensor {
+			return grad.MustMulScalar(ts.FloatScalar(2.0), false)
+		})
+		return out
+	}
+	r, err = ts.GradCheck(wrong, []*ts.Tensor{x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Passed || r.Inputs[0].NumFailed != 3 || r.Inputs[0].MaxRelErr < 0.4 {
+		t.Errorf("Expected gradient check failed for all elements:\n%v", r)
+	}
+
+	if _, err := ts.GradCheck(wrong, []*ts.Tensor{x, y}); err == nil {
+		t.Errorf("Expected error of single input function with 2 inputs\n")
+	}
+}
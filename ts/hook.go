@@ -0,0 +1,101 @@
+package ts
+
+// Tensor gradient hooks.
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+// GradHook is a function called with the gradient of a tensor during backward pass.
+//
+// It can return a new tensor to replace the gradient or nil to keep it.
+// The gradient must not be modified in place.
+type GradHook func(grad *Tensor) *Tensor
+
+// GradHookHandle is a handle of a registered gradient hook.
+type GradHookHandle struct {
+	ts   *Tensor
+	pos  int
+	once sync.Once
+}
+
+// RegisterHook registers a gradient hook on the tensor. The hook is called every time
+// gradient with respect to the tensor is computed, i.e. in `Backward` or `RunBackward`.
+//
+// The tensor must require gradient. Hook is released when it is removed or
+// the tensor and its graph are freed.
+//
+// A panic in the hook is recovered and makes the backward pass fail with an error.
+//
+// NOTE. Hook can be called from a libtorch autograd thread, e.g. for CUDA tensors.
+func (ts *Tensor) RegisterHook(hook GradHook) (*GradHookHandle, error) {
+	fn := func(cgrad lib.Ctensor) (lib.Ctensor, error) {
+		grad := newTensor(cgrad)
+		var out *Tensor
+		if err := recoverErr(func() {
+			out = hook(grad)
+		}); err != nil {
+			return nil, err
+		}
+		if out == nil {
+			return nil, nil
+		}
+
+		// NOTE. libtorch takes ownership of returned C tensor.
+		return lib.AtShallowClone(out.ctensor), nil
+	}
+
+	pos := lib.AtRegisterHook(ts.ctensor, fn)
+	if err := TorchErr(); err != nil {
+		return nil, err
+	}
+
+	return &GradHookHandle{ts: ts, pos: pos}, nil
+}
+
+// MustRegisterHook registers a gradient hook on the tensor. It panics if error.
+func (ts *Tensor) MustRegisterHook(hook GradHook) *GradHookHandle {
+	h, err := ts.RegisterHook(hook)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return h
+}
+
+// Remove removes the hook. It is a no-op if hook has been removed or tensor has been dropped.
+func (h *GradHookHandle) Remove() error {
+	var err error
+	h.once.Do(func() {
+		if h.ts.ctensor == nil {
+			return
+		}
+		lib.AtRemoveHook(h.ts.ctensor, h.pos)
+		err = TorchErr()
+	})
+
+	return err
+}
+
+// MustRemove removes the hook. It panics if error.
+func (h *GradHookHandle) MustRemove() {
+	if err := h.Remove(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// recoverErr runs fn and returns a panic in it as error as panics must not cross C frames.
+func recoverErr(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	fn()
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package ts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestRegisterHook(t *testing.T) {
+	x := ts.MustOfSlice([]float64{1, 2, 3})
+	x.MustRequiresGrad_(true)
+
+	var seen []float64
+	h := x.MustRegisterHook(func(grad *ts.Tensor) *ts.Tensor {
+		seen = grad.Float64Values()
+		return grad.MustMulScalar(ts.FloatScalar(2.0), false)
+	})
+
+	y := x.MustMulScalar(ts.FloatScalar(3.0), false).MustSum(x.DType(), true)
+	y.MustBackward()
+
+	want := []float64{3, 3, 3}
+	if !reflect.DeepEqual(want, seen) {
+		t.Errorf("Expected hook gradient: %v\n", want)
+		t.Errorf("Got: %v\n", seen)
+	}
+	want = []float64{6, 6, 6}
+	if got := x.MustGrad(false).Float64Values(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected modified gradient: %v\n", want)
+		t.Errorf("Got: %v\n", got)
+	}
+
+	// removed hook is no longer called.
+	h.MustRemove()
+	x.ZeroGrad()
+	y = x.MustMulScalar(ts.FloatScalar(3.0), false).MustSum(x.DType(), true)
+	y.MustBackward()
+	want = []float64{3, 3, 3}
+	if got := x.MustGrad(false).Float64Values(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected gradient after removing hook: %v\n", want)
+		t.Errorf("Got: %v\n", got)
+	}
+}
+
+func TestRegisterHookPanic(t *testing.T) {
+	x := ts.MustOfSlice([]float64{1, 2, 3})
+	x.MustRequiresGrad_(true)
+
+	x.MustRegisterHook(func(grad *ts.Tensor) *ts.Tensor {
+		panic("hook failed")
+	})
+
+	y := x.MustMulScalar(ts.FloatScalar(3.0), false).MustSum(x.DType(), true)
+	if err := y.Backward(); err == nil {
+		t.Errorf("Expected error from panicking hook")
+	}
+}
@@ -4,6 +4,11 @@ import "C"
 
 import (
 	// "unsafe"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"strings"
 
 	lib "github.com/nullbull/gotch/libtch"
 )
@@ -40,3 +45,203 @@ func ResizeHwc(ts *Tensor, outWidth, outHeight int64) (*Tensor, error) {
 
 	return newTensor(ctensor), nil
 }
+
+// DecodeImage reads and decodes an image (jpg, png, bmp, tga, gif, psd, hdr, pic or pnm)
+// from r. On success returns a uint8 tensor of shape [height, width, 3].
+//
+// JPEG images are rotated and/or flipped according to their EXIF orientation tag.
+func DecodeImage(r io.Reader) (*Tensor, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		err = fmt.Errorf("DecodeImage() failed: %w", err)
+		return nil, err
+	}
+
+	x, err := DecodeImageBytes(data)
+	if err != nil {
+		err = fmt.Errorf("DecodeImage() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustDecodeImage reads and decodes an image from r. It panics if error.
+func MustDecodeImage(r io.Reader) *Tensor {
+	x, err := DecodeImage(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// DecodeImageBytes decodes an image from encoded bytes as DecodeImage.
+func DecodeImageBytes(data []byte) (*Tensor, error) {
+	ctensor := lib.AtLoadImageFromMemory(data)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("DecodeImageBytes() failed: %w", err)
+		return nil, err
+	}
+	x := newTensor(ctensor)
+
+	x, err := applyExifOrientation(x, jpegOrientation(data))
+	if err != nil {
+		err = fmt.Errorf("DecodeImageBytes() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustDecodeImageBytes decodes an image from encoded bytes. It panics if error.
+func MustDecodeImageBytes(data []byte) *Tensor {
+	x, err := DecodeImageBytes(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// EncodeImage encodes a uint8 tensor of shape [height, width, channels] as an image of
+// format "jpg" (or "jpeg"), "png", "bmp" or "tga" and writes it to w. Quality in [1, 100]
+// is only used by jpg. Tensor on other devices is copied to CPU; other dtypes are rejected.
+func EncodeImage(ts *Tensor, w io.Writer, format string, quality int) error {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if format == "jpeg" {
+		format = "jpg"
+	}
+
+	data := lib.AtEncodeImage(ts.ctensor, format, quality)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("EncodeImage() failed: %w", err)
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		err = fmt.Errorf("EncodeImage() failed: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+// MustEncodeImage encodes a tensor as an image and writes it to w. It panics if error.
+func MustEncodeImage(ts *Tensor, w io.Writer, format string, quality int) {
+	if err := EncodeImage(ts, w, format, quality); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// applyExifOrientation transforms an image tensor of shape [height, width, channels] with
+// EXIF orientation (1-8) to its upright orientation. The input tensor is dropped if a new
+// tensor is returned.
+func applyExifOrientation(x *Tensor, orientation int) (*Tensor, error) {
+	var (
+		transpose bool
+		flips     []int64
+	)
+	switch orientation {
+	case 2: // mirror horizontal
+		flips = []int64{1}
+	case 3: // rotate 180
+		flips = []int64{0, 1}
+	case 4: // mirror vertical
+		flips = []int64{0}
+	case 5: // mirror horizontal and rotate 270 CW
+		transpose = true
+	case 6: // rotate 90 CW
+		transpose, flips = true, []int64{1}
+	case 7: // mirror horizontal and rotate 90 CW
+		transpose, flips = true, []int64{0, 1}
+	case 8: // rotate 270 CW
+		transpose, flips = true, []int64{0}
+	default:
+		return x, nil
+	}
+
+	var err error
+	if transpose {
+		if x, err = x.Permute([]int64{1, 0, 2}, true); err != nil {
+			return nil, err
+		}
+	}
+	if flips == nil {
+		return x.Contiguous(true)
+	}
+
+	return x.Flip(flips, true)
+}
+
+// jpegOrientation returns the EXIF orientation tag of JPEG data, 0 if data is not JPEG
+// or has no orientation tag.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 0
+	}
+
+	// Walk marker segments up to start of scan, looking for APP1 Exif segment.
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return 0
+		}
+		marker := data[i+1]
+		if marker == 0xff { // fill byte
+			i++
+			continue
+		}
+		if marker == 0xda || marker == 0xd9 { // SOS, EOI
+			return 0
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 0
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xe1 && len(seg) >= 6 && string(seg[:6]) == "Exif\x00\x00" {
+			return exifOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+
+	return 0
+}
+
+// exifOrientation returns orientation tag (0x0112) of IFD0 of TIFF-formatted EXIF data.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	n := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < n; k++ {
+		entry := ifd + 2 + 12*k
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		// Entry: tag (2), type (2), count (4), value (4). Orientation is a SHORT (3).
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			v := int(order.Uint16(tiff[entry+8:]))
+			if v < 1 || v > 8 {
+				return 0
+			}
+			return v
+		}
+	}
+
+	return 0
+}
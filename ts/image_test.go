@@ -0,0 +1,101 @@
+package ts_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+// testImage returns a uint8 image tensor of shape [2, 3, 3] with distinct pixels.
+func testImage() *ts.Tensor {
+	data := make([]uint8, 2*3*3)
+	for i := range data {
+		data[i] = uint8(i * 10)
+	}
+	return ts.MustFromSlice(data, 2, 3, 3)
+}
+
+func TestEncodeDecodeImage(t *testing.T) {
+	x := testImage()
+	for _, format := range []string{"png", "bmp", "tga"} {
+		var buf bytes.Buffer
+		ts.MustEncodeImage(x, &buf, format, 0)
+
+		y := ts.MustDecodeImage(&buf)
+		if got, want := ts.MustToSlice[uint8](y), ts.MustToSlice[uint8](x); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, got %v", format, want, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	ts.MustEncodeImage(x, &buf, "jpeg", 90)
+	y := ts.MustDecodeImageBytes(buf.Bytes())
+	if got, want := y.MustSize(), []int64{2, 3, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected shape %v, got %v", want, got)
+	}
+
+	if err := ts.EncodeImage(x, &buf, "webp", 0); err == nil {
+		t.Errorf("Expected error for unsupported format")
+	}
+	if err := ts.EncodeImage(x.MustTotype(gotch.Float, false), &buf, "png", 0); err == nil {
+		t.Errorf("Expected error for non-uint8 tensor")
+	}
+	if _, err := ts.DecodeImageBytes([]byte("not an image")); err == nil {
+		t.Errorf("Expected error for invalid data")
+	}
+}
+
+// withExifOrientation inserts an APP1 Exif segment with orientation tag after JPEG SOI marker.
+func withExifOrientation(jpg []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // header, IFD0 at offset 8
+		0, 1, // 1 entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // orientation SHORT
+		0, 0, 0, 0, // no next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	size := len(payload) + 2
+	seg := append([]byte{0xff, 0xe1, byte(size >> 8), byte(size)}, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, seg...)
+	return append(out, jpg[2:]...)
+}
+
+func TestDecodeImageExifOrientation(t *testing.T) {
+	// Image with a white left column on black, 4 rows x 8 columns.
+	data := make([]uint8, 4*8*3)
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 3; c++ {
+			data[r*8*3+c] = 255
+		}
+	}
+	x := ts.MustFromSlice(data, 4, 8, 3)
+
+	var buf bytes.Buffer
+	ts.MustEncodeImage(x, &buf, "jpg", 100)
+
+	tests := []struct {
+		orientation byte
+		shape       []int64
+		white       []int64 // index of a pixel of the white column (row/column)
+	}{
+		{1, []int64{4, 8, 3}, []int64{2, 0, 0}},
+		{2, []int64{4, 8, 3}, []int64{2, 7, 0}},
+		{6, []int64{8, 4, 3}, []int64{0, 2, 0}}, // left column becomes top row
+		{8, []int64{8, 4, 3}, []int64{7, 2, 0}}, // left column becomes bottom row
+	}
+	for _, tc := range tests {
+		y := ts.MustDecodeImageBytes(withExifOrientation(buf.Bytes(), tc.orientation))
+		if got := y.MustSize(); !reflect.DeepEqual(got, tc.shape) {
+			t.Errorf("orientation %d: expected shape %v, got %v", tc.orientation, tc.shape, got)
+			continue
+		}
+		if v := ts.MustAt[uint8](y, tc.white...); v < 200 {
+			t.Errorf("orientation %d: expected white pixel at %v, got %d", tc.orientation, tc.white, v)
+		}
+	}
+}
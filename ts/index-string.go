@@ -0,0 +1,426 @@
+package ts
+
+// NumPy-style string indexing and indexed assignment.
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/nullbull/gotch"
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+type indexKind int
+
+const (
+	indexInt indexKind = iota
+	indexSlice
+	indexEllipsis
+	indexNone
+	indexTensor
+)
+
+type indexItem struct {
+	kind              indexKind
+	index             int64
+	start, stop, step *int64
+	tensor            *Tensor
+	owned             bool // tensor created from an array literal
+}
+
+// dims returns number of tensor dimensions consumed by the item.
+func (it indexItem) dims() int {
+	switch it.kind {
+	case indexInt, indexSlice:
+		return 1
+	case indexTensor:
+		if it.tensor.DType() == gotch.Bool {
+			return int(it.tensor.Dim())
+		}
+		return 1
+	}
+
+	return 0
+}
+
+// splitIndexSpec splits spec at top-level commas.
+func splitIndexSpec(spec string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, c := range spec {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(spec[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, strings.TrimSpace(spec[start:]))
+	if len(parts) == 1 && parts[0] == "" {
+		return nil
+	}
+
+	return parts
+}
+
+func parseIndexSpec(spec string, tensors []*Tensor) ([]indexItem, error) {
+	var (
+		items    []indexItem
+		next     int
+		ellipsis bool
+	)
+	for _, part := range splitIndexSpec(spec) {
+		var it indexItem
+		switch {
+		case part == "":
+			return nil, fmt.Errorf("empty index in %q", spec)
+		case part == "...":
+			if ellipsis {
+				return nil, fmt.Errorf("multiple ellipses in %q", spec)
+			}
+			ellipsis = true
+			it.kind = indexEllipsis
+		case part == "None":
+			it.kind = indexNone
+		case part == "?":
+			if next >= len(tensors) {
+				return nil, fmt.Errorf("missing index tensor for placeholder %d in %q", next, spec)
+			}
+			it.kind = indexTensor
+			it.tensor = tensors[next]
+			next++
+		case strings.HasPrefix(part, "["):
+			x, err := parseIndexArray(part)
+			if err != nil {
+				return nil, err
+			}
+			it.kind = indexTensor
+			it.tensor = x
+			it.owned = true
+		case strings.Contains(part, ":"):
+			fields := strings.Split(part, ":")
+			if len(fields) > 3 {
+				return nil, fmt.Errorf("invalid slice %q", part)
+			}
+			vals := make([]*int64, 3)
+			for i, f := range fields {
+				f = strings.TrimSpace(f)
+				if f == "" {
+					continue
+				}
+				v, err := strconv.ParseInt(f, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid slice %q: %w", part, err)
+				}
+				vals[i] = &v
+			}
+			if vals[2] != nil && *vals[2] == 0 {
+				return nil, fmt.Errorf("slice step cannot be zero in %q", part)
+			}
+			it.kind = indexSlice
+			it.start, it.stop, it.step = vals[0], vals[1], vals[2]
+		default:
+			v, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q", part)
+			}
+			it.kind = indexInt
+			it.index = v
+		}
+		items = append(items, it)
+	}
+	if next != len(tensors) {
+		return nil, fmt.Errorf("%d index tensors given for %d placeholders in %q", len(tensors), next, spec)
+	}
+
+	return items, nil
+}
+
+// parseIndexArray parses an integer or bool array literal, e.g. `[0, -1]` or `[true, false]`.
+func parseIndexArray(part string) (*Tensor, error) {
+	if !strings.HasSuffix(part, "]") {
+		return nil, fmt.Errorf("invalid index array %q", part)
+	}
+	fields := strings.Split(strings.TrimSpace(part[1:len(part)-1]), ",")
+	if len(fields) == 1 && strings.TrimSpace(fields[0]) == "" {
+		return FromSlice([]int64{})
+	}
+
+	var (
+		ints  []int64
+		bools []bool
+	)
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		switch f {
+		case "true", "True":
+			bools = append(bools, true)
+		case "false", "False":
+			bools = append(bools, false)
+		default:
+			v, err := strconv.ParseInt(f, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index array %q", part)
+			}
+			ints = append(ints, v)
+		}
+	}
+	switch {
+	case len(bools) == 0:
+		return FromSlice(ints)
+	case len(ints) == 0:
+		return FromSlice(bools)
+	}
+
+	return nil, fmt.Errorf("mixed integer and bool index array %q", part)
+}
+
+// sliceIndices returns start, stop and step of a slice normalized to a dimension of size n
+// and number of selected elements as Python `slice.indices()`.
+func sliceIndices(it indexItem, n int64) (start, stop, step, count int64) {
+	step = 1
+	if it.step != nil {
+		step = *it.step
+	}
+	lower, upper := int64(0), n
+	if step < 0 {
+		lower, upper = -1, n-1
+	}
+	clamp := func(v *int64, def int64) int64 {
+		if v == nil {
+			return def
+		}
+		x := *v
+		if x < 0 {
+			x += n
+		}
+		if x < lower {
+			return lower
+		}
+		if x > upper {
+			return upper
+		}
+		return x
+	}
+	if step > 0 {
+		start, stop = clamp(it.start, lower), clamp(it.stop, upper)
+		if stop > start {
+			count = (stop - start + step - 1) / step
+		}
+	} else {
+		start, stop = clamp(it.start, upper), clamp(it.stop, lower)
+		if start > stop {
+			count = (start - stop - step - 1) / -step
+		}
+	}
+
+	return start, stop, step, count
+}
+
+// indexView applies basic indexes to a view of the tensor. It returns the view, dimensions
+// of the view to be flipped for negative step slices and advanced indexes for `Index`,
+// nil for non-advanced dimensions.
+func (ts *Tensor) indexView(items []indexItem) (view *Tensor, flips []int64, indices []*Tensor, err error) {
+	consumed := 0
+	for _, it := range items {
+		consumed += it.dims()
+	}
+	ndims := int(ts.Dim())
+	if consumed > ndims {
+		err = fmt.Errorf("too many indices for tensor of dimension %d", ndims)
+		return nil, nil, nil, err
+	}
+
+	view = ts.MustShallowClone()
+	var (
+		d        int64
+		advanced bool
+	)
+	for _, it := range items {
+		switch it.kind {
+		case indexEllipsis:
+			for i := 0; i < ndims-consumed; i++ {
+				indices = append(indices, nil)
+			}
+			d += int64(ndims - consumed)
+		case indexNone:
+			view, err = view.Unsqueeze(d, true)
+			indices = append(indices, nil)
+			d++
+		case indexInt:
+			view, err = view.Select(d, it.index, true)
+		case indexSlice:
+			n := view.MustSize()[d]
+			start, _, step, count := sliceIndices(it, n)
+			switch {
+			case count == 0:
+				view, err = view.Slice(d, []int64{0}, []int64{0}, 1, true)
+			case step > 0:
+				view, err = view.Slice(d, []int64{start}, []int64{start + (count-1)*step + 1}, step, true)
+			default:
+				// Slice elements in reverse order with positive step, flip later.
+				lo := start + (count-1)*step
+				view, err = view.Slice(d, []int64{lo}, []int64{start + 1}, -step, true)
+				flips = append(flips, d)
+			}
+			indices = append(indices, nil)
+			d++
+		case indexTensor:
+			indices = append(indices, it.tensor)
+			advanced = true
+			d += int64(it.dims())
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if !advanced {
+		indices = nil
+	}
+
+	return view, flips, indices, nil
+}
+
+func dropOwned(items []indexItem) {
+	for _, it := range items {
+		if it.owned {
+			it.tensor.MustDrop()
+		}
+	}
+}
+
+// I indexes tensor with a NumPy-style index spec, a comma-separated list of:
+//   - integer, e.g. `1` or `-1`, selecting an element and removing its dimension.
+//   - slice `start:stop:step`, any part optional and possibly negative, e.g. `1:5:2`, `::-1`.
+//   - `...`, expanding to as many full slices as needed to index all dimensions.
+//   - `None`, inserting a new dimension of size 1.
+//   - integer array, e.g. `[0, 2, -1]`, or bool array, e.g. `[true, false]`.
+//   - `?`, a placeholder for the next tensor of `indexes`: an integer tensor for
+//     advanced indexing or a bool tensor for mask indexing.
+//
+// Example:
+//
+//	y := x.MustI("..., 1:5:2, None")
+//	z := x.MustI("?, ::-1", mask)
+//
+// Integer arrays and tensors are broadcast together and placed as in NumPy: in place of
+// the indexed dimensions if adjacent, first otherwise. As in PyTorch, integers are
+// basic (`Select`) rather than advanced indexes.
+//
+// The result is a view sharing storage with the tensor only if spec consists of integers,
+// slices with positive step, `...` and `None`.
+func (ts *Tensor) I(spec string, indexes ...*Tensor) (*Tensor, error) {
+	items, err := parseIndexSpec(spec, indexes)
+	if err != nil {
+		err = fmt.Errorf("I() failed: %w", err)
+		return nil, err
+	}
+	defer dropOwned(items)
+
+	view, flips, indices, err := ts.indexView(items)
+	if err == nil && len(flips) > 0 {
+		view, err = view.Flip(flips, true)
+	}
+	if err == nil && indices != nil {
+		view, err = view.Index(indices, true)
+	}
+	if err != nil {
+		err = fmt.Errorf("I() failed: %w", err)
+		return nil, err
+	}
+
+	return view, nil
+}
+
+// MustI indexes tensor with a NumPy-style index spec. It panics if error.
+func (ts *Tensor) MustI(spec string, indexes ...*Tensor) *Tensor {
+	x, err := ts.I(spec, indexes...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// SetI assigns values in-place to elements of tensor indexed by a NumPy-style index
+// spec as in `I`. Values are broadcast to the shape of the indexed result.
+//
+// Example:
+//
+//	x.MustSetI(":, [0, 2]", ts.MustZeros([]int64{1}, gotch.Float, gotch.CPU))
+//	x.MustSetI("?", ys, x.MustLt(ts.FloatScalar(0), false))
+func (ts *Tensor) SetI(spec string, value *Tensor, indexes ...*Tensor) error {
+	items, err := parseIndexSpec(spec, indexes)
+	if err != nil {
+		err = fmt.Errorf("SetI() failed: %w", err)
+		return err
+	}
+	defer dropOwned(items)
+
+	view, flips, indices, err := ts.indexView(items)
+	if err != nil {
+		err = fmt.Errorf("SetI() failed: %w", err)
+		return err
+	}
+	defer view.MustDrop()
+
+	// Flipped tensors are copies: assign to a flipped copy then write it back.
+	target := view
+	if len(flips) > 0 {
+		if target, err = view.Flip(flips, false); err != nil {
+			err = fmt.Errorf("SetI() failed: %w", err)
+			return err
+		}
+		defer target.MustDrop()
+	}
+
+	if indices != nil {
+		v := value
+		if value.DType() != ts.DType() {
+			if v, err = value.Totype(ts.DType(), false); err != nil {
+				err = fmt.Errorf("SetI() failed: %w", err)
+				return err
+			}
+			defer v.MustDrop()
+		}
+		err = target.IndexPut_(indices, v, false)
+	} else {
+		err = copyInPlace(target, value)
+	}
+	if err == nil && len(flips) > 0 {
+		var flipped *Tensor
+		if flipped, err = target.Flip(flips, false); err == nil {
+			err = copyInPlace(view, flipped)
+			flipped.MustDrop()
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("SetI() failed: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+// MustSetI assigns values to elements indexed by a NumPy-style index spec. It panics if error.
+func (ts *Tensor) MustSetI(spec string, value *Tensor, indexes ...*Tensor) {
+	if err := ts.SetI(spec, value, indexes...); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// copyInPlace copies broadcast values of src to dst.
+func copyInPlace(dst, src *Tensor) error {
+	lib.AtCopy_(dst.ctensor, src.ctensor)
+	return TorchErr()
+}
@@ -57,6 +57,9 @@ package ts
 // shape mismatch error due to advanced indexing rule. Another distinction
 // is that `i` guarantees the input and result tensor shares the same
 // underlying storage, while NumPy may copy the tensor in certain scenarios.
+//
+// See `Tensor.I` for NumPy-style indexing including advanced indexing and `Tensor.SetI`
+// for indexed assignment.
 
 // NOTE: select, narrow and indexing operations (except when using a LongTensor index) return views onto the same memory.
 // https://discuss.pytorch.org/t/does-select-and-narrow-return-a-view-or-copy/289
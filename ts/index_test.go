@@ -297,3 +297,86 @@ func TestIndex3D(t *testing.T) {
 		t.Errorf("Got tensor values: %v\n", got3)
 	}
 }
+
+func TestIndexString(t *testing.T) {
+	// [[ 0  1  2  3]
+	//  [ 4  5  6  7]
+	//  [ 8  9 10 11]]
+	x := ts.MustArange(ts.IntScalar(12), gotch.Int64, gotch.CPU).MustView([]int64{3, 4}, true)
+
+	tests := []struct {
+		spec  string
+		shape []int64
+		want  []int64
+	}{
+		{"1", []int64{4}, []int64{4, 5, 6, 7}},
+		{"-1, 1:3", []int64{2}, []int64{9, 10}},
+		{"..., 1:4:2", []int64{3, 2}, []int64{1, 3, 5, 7, 9, 11}},
+		{"::-1, 0", []int64{3}, []int64{8, 4, 0}},
+		{"1, -1:0:-2", []int64{2}, []int64{7, 5}},
+		{"None, ..., None", []int64{1, 3, 4, 1}, nil},
+		{"[0, 2], 1:3", []int64{2, 2}, []int64{1, 2, 9, 10}},
+		{"[0, 2], [1, 3]", []int64{2}, []int64{1, 11}},
+		{"[true, false, true]", []int64{2, 4}, []int64{0, 1, 2, 3, 8, 9, 10, 11}},
+		{"5:1", []int64{0, 4}, []int64{}},
+	}
+	for _, tt := range tests {
+		y := x.MustI(tt.spec)
+		if got := y.MustSize(); !reflect.DeepEqual(got, tt.shape) {
+			t.Errorf("%q: expected shape %v, got %v", tt.spec, tt.shape, got)
+		}
+		if tt.want != nil {
+			if got := ts.MustToSlice[int64](y); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q: expected %v, got %v", tt.spec, tt.want, got)
+			}
+		}
+	}
+
+	// Mask and broadcast integer tensors.
+	mask := x.MustGt(ts.IntScalar(8), false)
+	if got, want := ts.MustToSlice[int64](x.MustI("?", mask)), []int64{9, 10, 11}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mask: expected %v, got %v", want, got)
+	}
+	rows := ts.MustFromSlice([]int64{0, 2}, 2, 1)
+	cols := ts.MustFromSlice([]int64{0, 3})
+	y := x.MustI("?, ?", rows, cols)
+	if got, want := y.MustSize(), []int64{2, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("broadcast: expected shape %v, got %v", want, got)
+	}
+	if got, want := ts.MustToSlice[int64](y), []int64{0, 3, 8, 11}; !reflect.DeepEqual(got, want) {
+		t.Errorf("broadcast: expected %v, got %v", want, got)
+	}
+
+	for _, spec := range []string{"0, 0, 0", "..., ...", "::0", "1:2:3:4", "?", "[1, true]", "a"} {
+		if _, err := x.I(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestSetIndexString(t *testing.T) {
+	x := ts.MustZeros([]int64{3, 4}, gotch.Int64, gotch.CPU)
+
+	x.MustSetI("0", ts.MustFromSlice([]int64{1, 2, 3, 4}))
+	x.MustSetI("1:, ::-2", ts.MustFromSlice([]int64{5, 6}))
+	x.MustSetI("[2], [0]", ts.MustFromSlice([]int64{7}))
+	want := []int64{
+		1, 2, 3, 4,
+		0, 6, 0, 5,
+		7, 6, 0, 5,
+	}
+	if got := ts.MustToSlice[int64](x); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Mask assignment with a float value converted to tensor dtype.
+	x.MustSetI("?", ts.MustFromSlice([]float32{-1}), x.MustGe(ts.IntScalar(5), false))
+	want = []int64{
+		1, 2, 3, 4,
+		0, -1, 0, -1,
+		-1, -1, 0, -1,
+	}
+	if got := ts.MustToSlice[int64](x); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,117 @@
+// Package dlpacktest provides a small C producer and consumer of DLPack tensors to test
+// DLPack exchange. cgo can not be used in test files, hence this package.
+package dlpacktest
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+
+// DLPack ABI (https://github.com/dmlc/dlpack), version 0.x layout.
+typedef struct { int32_t device_type; int32_t device_id; } DLDevice;
+typedef struct { uint8_t code; uint8_t bits; uint16_t lanes; } DLDataType;
+typedef struct {
+	void *data;
+	DLDevice device;
+	int32_t ndim;
+	DLDataType dtype;
+	int64_t *shape;
+	int64_t *strides;
+	uint64_t byte_offset;
+} DLTensor;
+typedef struct DLManagedTensor {
+	DLTensor dl_tensor;
+	void *manager_ctx;
+	void (*deleter)(struct DLManagedTensor *self);
+} DLManagedTensor;
+
+enum { kDLCPU = 1, kDLFloat = 2 };
+
+static int64_t deleted = 0;
+
+static void managed_free(DLManagedTensor *self) {
+	free(self->dl_tensor.data);
+	free(self->dl_tensor.shape);
+	free(self);
+	deleted++;
+}
+
+// new_float32 produces a contiguous float32 CPU tensor owning copies of data and shape.
+static DLManagedTensor *new_float32(float *data, int64_t *shape, int32_t ndim) {
+	int64_t numel = 1;
+	for (int i = 0; i < ndim; i++) numel *= shape[i];
+
+	DLManagedTensor *t = calloc(1, sizeof(DLManagedTensor));
+	t->dl_tensor.data = malloc(numel * sizeof(float));
+	memcpy(t->dl_tensor.data, data, numel * sizeof(float));
+	t->dl_tensor.shape = malloc(ndim * sizeof(int64_t));
+	memcpy(t->dl_tensor.shape, shape, ndim * sizeof(int64_t));
+	t->dl_tensor.ndim = ndim;
+	t->dl_tensor.device.device_type = kDLCPU;
+	t->dl_tensor.dtype.code = kDLFloat;
+	t->dl_tensor.dtype.bits = 32;
+	t->dl_tensor.dtype.lanes = 1;
+	t->deleter = managed_free;
+	return t;
+}
+
+// sum_float32 consumes a float32 CPU tensor: it returns the sum of its elements, following
+// strides if any, then calls the deleter. It returns -1 for other tensors without consuming them.
+static double sum_float32(DLManagedTensor *t) {
+	DLTensor *x = &t->dl_tensor;
+	if (x->device.device_type != kDLCPU || x->dtype.code != kDLFloat || x->dtype.bits != 32) return -1;
+
+	int64_t numel = 1;
+	for (int i = 0; i < x->ndim; i++) numel *= x->shape[i];
+
+	double sum = 0;
+	float *data = (float *)((char *)x->data + x->byte_offset);
+	for (int64_t n = 0; n < numel; n++) {
+		int64_t offset = 0, rem = n;
+		for (int i = x->ndim - 1; i >= 0; i--) {
+			int64_t stride = 1;
+			if (x->strides != NULL) {
+				stride = x->strides[i];
+			} else {
+				for (int j = i + 1; j < x->ndim; j++) stride *= x->shape[j];
+			}
+			offset += (rem % x->shape[i]) * stride;
+			rem /= x->shape[i];
+		}
+		sum += data[offset];
+	}
+
+	if (t->deleter != NULL) t->deleter(t);
+	return sum;
+}
+
+static int64_t get_deleted() { return deleted; }
+*/
+import "C"
+
+import "unsafe"
+
+// NewFloat32 returns a `DLManagedTensor*` of a float32 CPU tensor with a copy of data. Its
+// deleter frees memory allocated in C and increments `Deleted()`.
+func NewFloat32(data []float32, shape ...int64) unsafe.Pointer {
+	cdata := C.malloc(C.size_t(len(data)+1) * 4)
+	defer C.free(cdata)
+	copy(unsafe.Slice((*float32)(cdata), len(data)), data)
+
+	cshape := C.malloc(C.size_t(len(shape)+1) * 8)
+	defer C.free(cshape)
+	copy(unsafe.Slice((*int64)(cshape), len(shape)), shape)
+
+	return unsafe.Pointer(C.new_float32((*C.float)(cdata), (*C.int64_t)(cshape), C.int32_t(len(shape))))
+}
+
+// SumFloat32 consumes a `DLManagedTensor*` of a float32 CPU tensor: it returns the sum of
+// its elements and calls its deleter.
+func SumFloat32(dl unsafe.Pointer) float64 {
+	return float64(C.sum_float32((*C.DLManagedTensor)(dl)))
+}
+
+// Deleted returns the number of tensors created with NewFloat32 that have been deleted.
+func Deleted() int64 {
+	return int64(C.get_deleted())
+}
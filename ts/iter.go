@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/half"
 )
 
 type Iterator interface {
@@ -26,28 +27,33 @@ func (it *Iterable) Next() (item interface{}, ok bool) {
 	}
 
 	var err error
-	switch it.ItemKind.GoKind().String() {
-	case "int64":
-		item, err = it.Content.Int64Value([]int64{it.Index})
-		if err != nil {
-			log.Fatal(err)
-		}
-		it.Index += 1
-	case "float64":
-		item, err = it.Content.Float64Value([]int64{it.Index})
-		if err != nil {
-			log.Fatal(err)
-		}
-		it.Index += 1
+	idx := it.Index
+	switch it.ItemKind {
+	case gotch.Int64:
+		item, err = it.Content.Int64Value([]int64{idx})
+	case gotch.Double:
+		item, err = it.Content.Float64Value([]int64{idx})
+	case gotch.ComplexFloat:
+		item, err = At[complex64](it.Content, idx)
+	case gotch.ComplexDouble:
+		item, err = At[complex128](it.Content, idx)
+	case gotch.Half:
+		item, err = At[half.Float16](it.Content, idx)
+	case gotch.BFloat16:
+		item, err = At[half.BFloat16](it.Content, idx)
 	default:
-		err := fmt.Errorf("Iterator error: unsupported item kind (%v).\n", it.ItemKind)
+		err = fmt.Errorf("Iterator error: unsupported item kind (%v).\n", it.ItemKind)
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
+	it.Index += 1
 
 	return item, true
 }
 
-// Iter creates an iterable object with specified item type.
+// Iter creates an iterable object with specified item type, one of `gotch.Int64`,
+// `Double`, `ComplexFloat`, `ComplexDouble`, `Half` or `BFloat16`.
 func (ts *Tensor) Iter(dtype gotch.DType) (*Iterable, error) {
 	num, err := ts.Size1() // size for 1D tensor
 	if err != nil {
@@ -66,3 +72,13 @@ func (ts *Tensor) Iter(dtype gotch.DType) (*Iterable, error) {
 		ItemKind: dtype,
 	}, nil
 }
+
+// MustIter creates an iterable object with specified item type. It panics if error.
+func (ts *Tensor) MustIter(dtype gotch.DType) *Iterable {
+	it, err := ts.Iter(dtype)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return it
+}
@@ -1,5 +1,7 @@
 package ts
 
+import "fmt"
+
 // include/c10/core/Layout.h
 type Layout int8
 
@@ -13,3 +15,24 @@ const (
 	SparseBsc                // 6
 	NumOptions               // 7
 )
+
+func (l Layout) String() string {
+	switch l {
+	case Strided:
+		return "Strided"
+	case Sparse:
+		return "Sparse"
+	case SparseCsr:
+		return "SparseCsr"
+	case Mkldnn:
+		return "Mkldnn"
+	case SparseCsc:
+		return "SparseCsc"
+	case SparseBsr:
+		return "SparseBsr"
+	case SparseBsc:
+		return "SparseBsc"
+	}
+
+	return fmt.Sprintf("Layout(%d)", int8(l))
+}
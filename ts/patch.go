@@ -4,6 +4,7 @@ package ts
 import "C"
 
 import (
+	"fmt"
 	"log"
 	"unsafe"
 
@@ -183,6 +184,77 @@ func (ts *Tensor) MustNLLLoss(target *Tensor, del bool) (retVal *Tensor) {
 	return retVal
 }
 
+// Index indexes tensor with a list of optional index tensors, one for each indexed
+// dimension, as in advanced indexing of NumPy. A nil index keeps the whole dimension.
+// Bool (mask) indexes span as many dimensions as their own.
+//
+// NOTE. `Index` is missing in tensor-generated as `indices` is a list of optional tensors.
+func (ts *Tensor) Index(indices []*Tensor, del bool) (retVal *Tensor, err error) {
+	ptr := (*lib.Ctensor)(unsafe.Pointer(C.malloc(0)))
+	if del {
+		defer ts.MustDrop()
+	}
+
+	cindices := make([]lib.Ctensor, len(indices))
+	for i, idx := range indices {
+		if idx != nil {
+			cindices[i] = idx.ctensor
+		}
+	}
+
+	lib.AtgIndex(ptr, ts.ctensor, cindices, len(cindices))
+	if err = TorchErr(); err != nil {
+		err = fmt.Errorf("Index() failed: %w", err)
+		return retVal, err
+	}
+
+	retVal = newTensor(*ptr, "Index")
+
+	return retVal, nil
+}
+
+func (ts *Tensor) MustIndex(indices []*Tensor, del bool) (retVal *Tensor) {
+	retVal, err := ts.Index(indices, del)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return retVal
+}
+
+// IndexPut_ puts values in-place at indexes given as in `Index`. Values are broadcast
+// to the shape of indexed result and summed up with existing values if `accumulate`.
+func (ts *Tensor) IndexPut_(indices []*Tensor, values *Tensor, accumulate bool) error {
+	ptr := (*lib.Ctensor)(unsafe.Pointer(C.malloc(0)))
+
+	cindices := make([]lib.Ctensor, len(indices))
+	for i, idx := range indices {
+		if idx != nil {
+			cindices[i] = idx.ctensor
+		}
+	}
+	var caccumulate int32 = 0
+	if accumulate {
+		caccumulate = 1
+	}
+
+	lib.AtgIndexPut_(ptr, ts.ctensor, cindices, len(cindices), values.ctensor, caccumulate)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("IndexPut_() failed: %w", err)
+		return err
+	}
+	ts.ctensor = *ptr
+
+	return nil
+}
+
+func (ts *Tensor) MustIndexPut_(indices []*Tensor, values *Tensor, accumulate bool) {
+	err := ts.IndexPut_(indices, values, accumulate)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
 // NOTE: the following 9 APIs are missing from `tensor-generated.go` with
 // pattern of **return tensor pointer**: `tensor *atg_FUNCTION_NAME()`.
 // The returning tensor pointer actually is the FIRST element of a vector
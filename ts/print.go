@@ -1,11 +1,14 @@
 package ts
 
+// Tensor printing with NumPy-like print options.
+
 import (
-	"bytes"
 	"fmt"
 	"log"
-	"reflect"
+	"math"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/nullbull/gotch"
 )
@@ -14,480 +17,525 @@ func (ts *Tensor) ValueGo() interface{} {
 	return ts.Vals()
 }
 
-func shapeToSize(shape []int64) int {
-	n := 1
-	for _, v := range shape {
-		if v == 0 {
-			continue
-		}
-		n = n * int(v)
-	}
-	return n
-}
-
-func shapeToNumels(shape []int) int {
-	n := 1
-	for _, d := range shape {
-		n *= int(d)
-	}
-	return n
-}
+// PrintSciMode specifies when floating point values are printed in scientific notation.
+type PrintSciMode int
 
-func shapeToStrides(shape []int) []int {
-	numel := shapeToNumels(shape)
-	var strides []int
-	for _, v := range shape {
-		numel /= int(v)
-		strides = append(strides, numel)
-	}
+const (
+	// PrintSciAuto uses scientific notation if values span a large range, i.e. max >= 1e8,
+	// min < 1e-4 or max/min > 1e3 of absolute non-zero finite values.
+	PrintSciAuto PrintSciMode = iota
+	PrintSciAlways
+	PrintSciNever
+)
 
-	return strides
+// PrintOptions holds options of tensor printing.
+type PrintOptions struct {
+	Precision int          // digits after decimal point of floating point values. Default=4
+	Threshold int          // number of elements above which values are summarized with `...`. Default=1000
+	EdgeItems int          // number of items at beginning and end of each summarized dimension. Default=3
+	LineWidth int          // number of characters per line before wrapping. Default=80
+	SciMode   PrintSciMode // Default=PrintSciAuto
+	Sign      rune         // '-' to sign negative values only, '+' to sign all values or ' ' to pad positive values with a space. Default='-'
+	IntBase   int          // base (2 to 36) of integer values. Default=10
+	Summary   bool         // print min, max, mean, std and NaN count instead of values. Default=false
 }
 
-func toSliceInt(in []int64) []int {
-	out := make([]int, len(in))
-	for i := 0; i < len(in); i++ {
-		out[i] = int(in[i])
+type PrintOpt func(*PrintOptions)
+
+func defaultPrintOptions() *PrintOptions {
+	return &PrintOptions{
+		Precision: 4,
+		Threshold: 1000,
+		EdgeItems: 3,
+		LineWidth: 80,
+		SciMode:   PrintSciAuto,
+		Sign:      '-',
+		IntBase:   10,
+		Summary:   false,
 	}
-	return out
 }
 
-func maxInt(a, b int) int {
-	if a >= b {
-		return a
+func WithPrintPrecision(v int) PrintOpt {
+	return func(o *PrintOptions) {
+		o.Precision = v
 	}
-	return b
 }
 
-func minInt(a, b int) int {
-	if a < b {
-		return a
-	} else {
-		return b
+func WithPrintThreshold(v int) PrintOpt {
+	return func(o *PrintOptions) {
+		o.Threshold = v
 	}
 }
 
-func toSlice(input interface{}) []interface{} {
-	vlen := reflect.ValueOf(input).Len()
-	out := make([]interface{}, vlen)
-	for i := 0; i < vlen; i++ {
-		out[i] = reflect.ValueOf(input).Index(i).Interface()
+func WithPrintEdgeItems(v int) PrintOpt {
+	return func(o *PrintOptions) {
+		o.EdgeItems = v
 	}
-
-	return out
-}
-
-func sliceInterface(data interface{}, start, end int) []interface{} {
-	return toSlice(data)[start:end]
-}
-
-// Implement Format interface for Tensor:
-// ======================================
-var (
-	fmtByte  = []byte("%")
-	precByte = []byte(".")
-	fmtFlags = [...]rune{'+', '-', '#', ' ', '0'}
-
-	ufVec    = []byte("Vector")
-	ufMat    = []byte("Matrix")
-	ufTensor = []byte("Tensor")
-)
-
-// fmtState is a custom formatter for Tensor that implements fmt.State interface
-type fmtState struct {
-	fmt.State
-	verb      rune          // format verb
-	flat      bool          // whether to print tensor in flatten format
-	meta      bool          // whether to print out meta data
-	ext       bool          // whether to print full tensor data (no truncation)
-	pad       []byte        // padding space
-	w         int           // width - total calculated space to print out tensor values
-	p         int           // precision for float dtype
-	base      int           // integer counting base for integer dtype cases
-	htrunc    []byte        // horizontal truncation symbol space
-	vtrunc    []byte        // vertical truncation symbol space
-	rows      int           // total rows
-	cols      int           // total columns
-	printRows int           // rows to print
-	printCols int           // columns to print
-	shape     []int         // shape of tensor to print out
-	buf       *bytes.Buffer // memory to hold formated tensor data to print
 }
 
-func newFmtState(s fmt.State, verb rune, shape []int) *fmtState {
-	w, _ := s.Width()
-	p, _ := s.Precision()
-
-	return &fmtState{
-		State:  s,
-		verb:   verb,
-		flat:   s.Flag('-'),
-		meta:   s.Flag('+'),
-		ext:    s.Flag('#'),
-		w:      w,
-		p:      p,
-		htrunc: []byte("..., "),
-		vtrunc: []byte("...,\n"),
-		shape:  shape,
-		buf:    bytes.NewBuffer(make([]byte, 0)),
+func WithPrintLineWidth(v int) PrintOpt {
+	return func(o *PrintOptions) {
+		o.LineWidth = v
 	}
 }
 
-// originalFmt returns original format.
-func (f *fmtState) originalFmt() string {
-	// write format symbol and verbs
-	buf := bytes.NewBuffer(fmtByte) // '%'
-	for _, flag := range fmtFlags {
-		if f.Flag(int(flag)) {
-			buf.WriteRune(flag)
-		}
-	}
-
-	// write width format
-	if w, ok := f.Width(); ok {
-		buf.WriteString(strconv.Itoa(w))
+func WithPrintSciMode(v PrintSciMode) PrintOpt {
+	return func(o *PrintOptions) {
+		o.SciMode = v
 	}
+}
 
-	// write precision verb
-	if p, ok := f.Precision(); ok {
-		buf.Write(precByte)
-		buf.WriteString(strconv.Itoa(p))
+func WithPrintSign(v rune) PrintOpt {
+	return func(o *PrintOptions) {
+		o.Sign = v
 	}
-
-	buf.WriteRune(f.verb)
-
-	return buf.String()
 }
 
-// cleanFmt returns a start of the format.
-func (f *fmtState) initFmt() string {
-	buf := bytes.NewBuffer(fmtByte)
-
-	// write width format
-	if w, ok := f.Width(); ok {
-		buf.WriteString(strconv.Itoa(w))
+func WithPrintIntBase(v int) PrintOpt {
+	return func(o *PrintOptions) {
+		o.IntBase = v
 	}
+}
 
-	// write precision verb
-	if p, ok := f.Precision(); ok {
-		buf.Write(precByte)
-		buf.WriteString(strconv.Itoa(p))
+func WithPrintSummary(v bool) PrintOpt {
+	return func(o *PrintOptions) {
+		o.Summary = v
 	}
-
-	buf.WriteRune(f.verb)
-
-	return buf.String()
 }
 
-// cast casts tensor data to the formatter.
-func (f *fmtState) cast(ts *Tensor) {
-	// rows and columns
-	if ts.Dim() == 1 {
-		f.rows = 1
-		f.cols = int(ts.Numel())
-	} else {
-		shape := ts.MustSize()
-		f.rows = int(shape[len(shape)-2])
-		f.cols = int(shape[len(shape)-1])
-	}
+var (
+	printOptions   = defaultPrintOptions()
+	printOptionsMu sync.RWMutex
+)
 
-	// printRows and printCols
-	switch {
-	case f.flat && f.ext:
-		f.printCols = int(ts.Numel())
-	case f.flat:
-		f.printCols = 10
-	case f.ext:
-		f.printCols = f.cols
-		f.printRows = f.rows
-	default:
-		f.printCols = minInt(f.cols, 6)
-		f.printRows = minInt(f.rows, 6)
+// SetPrintOptions sets global print options used by `fmt` verbs and `Sprint`.
+//
+// Example:
+//
+//	ts.SetPrintOptions(ts.WithPrintPrecision(2), ts.WithPrintThreshold(100))
+func SetPrintOptions(opts ...PrintOpt) {
+	printOptionsMu.Lock()
+	defer printOptionsMu.Unlock()
+
+	for _, opt := range opts {
+		opt(printOptions)
 	}
 }
 
-// fmtVerb formats verbs.
-func (f *fmtState) fmtVerb(ts *Tensor) {
-	if f.verb == 'H' { // print out only header.
-		f.meta = true
-		return
-	}
+// ResetPrintOptions resets global print options to defaults.
+func ResetPrintOptions() {
+	printOptionsMu.Lock()
+	defer printOptionsMu.Unlock()
 
-	// var typ T
-	typ := ts.DType()
+	printOptions = defaultPrintOptions()
+}
 
-	switch typ {
-	case gotch.Half, gotch.BFloat16, gotch.Float, gotch.Double:
-		switch f.verb {
-		case 'f', 'e', 'E', 'G', 'b':
-			// accepted. Do nothing
-		default:
-			f.verb = 'g'
-		}
+// CurrentPrintOptions returns a copy of global print options.
+func CurrentPrintOptions() PrintOptions {
+	printOptionsMu.RLock()
+	defer printOptionsMu.RUnlock()
 
-	case gotch.Uint8, gotch.Int8, gotch.Int16, gotch.Int, gotch.Int64:
-		switch f.verb {
-		case 'b':
-			f.base = 2
-		case 'd':
-			f.base = 10
-		case 'o':
-			f.base = 8
-		case 'x', 'X':
-			f.base = 16
-		default:
-			f.base = 10
-			f.verb = 'd'
-		}
-	case gotch.Bool:
-		f.verb = 't'
-	default:
-		f.verb = 'v'
-	}
+	return *printOptions
 }
 
-// computeWidth computes a width that can fit for every element.
-func (f *fmtState) computeWidth(values interface{}) {
-	format := f.initFmt()
-	vlen := reflect.ValueOf(values).Len()
-	f.w = 0
-	for i := 0; i < vlen; i++ {
-		val := reflect.ValueOf(values).Index(i)
-		w, _ := fmt.Fprintf(f.buf, format, val)
-
-		if w > f.w {
-			f.w = w
-		}
-		f.buf.Reset()
+// Sprint formats tensor values with global print options overridden by `opts`.
+func (ts *Tensor) Sprint(opts ...PrintOpt) string {
+	o := CurrentPrintOptions()
+	for _, opt := range opts {
+		opt(&o)
 	}
-}
 
-// makePad prepares white spaces for print-out format.
-func (f *fmtState) makePad() {
-	f.pad = make([]byte, maxInt(f.w, 2))
-	for i := range f.pad {
-		f.pad[i] = ' ' // one white space
+	s, err := ts.sprint(&o)
+	if err != nil {
+		log.Fatal(err)
 	}
-}
-
-func (f *fmtState) writeHTrunc() {
-	f.Write(f.htrunc)
-}
 
-func (f *fmtState) writeVTrunc() {
-	f.Write(f.vtrunc)
+	return s
 }
 
 // Format implements fmt.Formatter interface so that we can use
 // fmt.Print... and verbs to print out Tensor value in different formats.
+//
+// Values are formatted with global print options overridden by:
+//   - precision, e.g. `%.2v`.
+//   - verbs `%e` and `%f` for scientific and fixed point notation.
+//   - verbs `%b`, `%o`, `%d`, `%x` and `%X` for integer values in base 2, 8, 10 and 16.
+//   - flag `#` to print all values without summarization.
+//   - flag `-` to print values flattened.
+//   - flag ` ` to pad positive values with a space.
+//
+// Flag `+` prints shape and strides before values, verb `%H` only those, and verb `%i`
+// prints tensor info.
 func (ts *Tensor) Format(s fmt.State, verb rune) {
-	shape := toSliceInt(ts.MustSize())
-	strides := shapeToStrides(shape)
-	device := ts.MustDevice()
-	dtype := ts.DType()
-	defined := ts.MustDefined()
 	if verb == 'i' {
 		fmt.Fprintf(
 			s,
 			"\nTENSOR INFO:\n\tShape:\t\t%v\n\tDType:\t\t%v\n\tDevice:\t\t%v\n\tDefined:\t%v\n",
-			shape,
-			dtype,
-			device,
-			defined,
+			ts.MustSize(),
+			ts.DType(),
+			ts.MustDevice(),
+			ts.MustDefined(),
 		)
 		return
 	}
+	if !ts.MustDefined() {
+		fmt.Fprint(s, "Tensor(undefined)")
+		return
+	}
 
-	data := ts.ValueGo()
-
-	f := newFmtState(s, verb, shape)
-	f.computeWidth(data)
-	f.makePad()
-	f.cast(ts)
+	o := CurrentPrintOptions()
+	if p, ok := s.Precision(); ok {
+		o.Precision = p
+	}
+	switch verb {
+	case 'e', 'E':
+		o.SciMode = PrintSciAlways
+	case 'f', 'F':
+		o.SciMode = PrintSciNever
+	case 'b':
+		o.IntBase = 2
+	case 'o':
+		o.IntBase = 8
+	case 'd':
+		o.IntBase = 10
+	case 'x', 'X':
+		o.IntBase = 16
+	}
+	if s.Flag('#') {
+		o.Threshold = math.MaxInt
+	}
+	if s.Flag(' ') {
+		o.Sign = ' '
+	}
 
-	// Tensor meta data
-	if f.meta {
-		switch ts.Dim() {
+	shape := ts.MustSize()
+	if s.Flag('+') || verb == 'H' {
+		switch len(shape) {
 		case 1:
-			f.Write(ufVec)
+			fmt.Fprint(s, "Vector")
 		case 2:
-			f.Write(ufMat)
+			fmt.Fprint(s, "Matrix")
 		default:
-			f.Write(ufTensor)
-			fmt.Fprintf(f, ": Dim=%d, ", ts.Dim())
+			fmt.Fprintf(s, "Tensor: Dim=%d, ", len(shape))
 		}
-		fmt.Fprintf(f, "Shape=%v, Strides=%v\n", shape, strides)
+		fmt.Fprintf(s, "Shape=%v, Strides=%v\n", shape, ts.MustStride())
 	}
-
-	if f.verb == 'H' {
+	if verb == 'H' {
 		return
 	}
 
-	if f.flat {
-		// TODO.
-		// writeFlatTensor()
-		log.Printf("WARNING: f.writeFlatTensor() NotImplemedted.\n")
-		return
+	x := ts
+	if s.Flag('-') && len(shape) != 1 {
+		x = ts.MustReshape([]int64{-1}, false)
+		defer x.MustDrop()
+	}
+	str, err := x.sprint(&o)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if verb == 'X' && isIntDType(x.DType()) {
+		str = strings.ToUpper(str)
 	}
+	fmt.Fprintln(s, str)
+}
 
-	// 0d (scalar)
-	if len(shape) == 0 {
-		fmt.Printf("%v", data)
+// Print prints tensor meta data to stdout.
+func (ts *Tensor) Info() {
+	fmt.Printf("%i", ts)
+}
+
+func (ts *Tensor) sprint(o *PrintOptions) (string, error) {
+	if o.Summary {
+		return ts.summary(o)
 	}
 
-	// 1d (slice)
-	if len(shape) == 1 {
-		values := sliceInterface(data, 0, shape[0])
-		f.writeVector(values)
-		return
+	x, truncated, err := ts.summarize(o)
+	if err != nil {
+		return "", err
 	}
+	defer x.MustDrop()
 
-	// 2d (matrix)
-	if len(shape) == 2 {
-		vlen := shape[0] * shape[1]
-		values := sliceInterface(data, 0, vlen)
-		f.writeMatrix(values, shape)
-		return
+	vals, err := formatValues(x, o)
+	if err != nil {
+		return "", err
+	}
+	width := 0
+	for _, v := range vals {
+		width = max(width, len(v))
+	}
+	for i, v := range vals {
+		vals[i] = strings.Repeat(" ", width-len(v)) + v
 	}
 
-	// >= 3d (tensor)
-	f.Write([]byte("\n"))
-	f.writeTensor(ts, data)
+	var b strings.Builder
+	writeValues(&b, vals, x.MustSize(), truncated, o, 0)
+
+	return b.String(), nil
 }
 
-// writeTensor writes input tensor in specified format.
-func (f *fmtState) writeTensor(ts *Tensor, values interface{}) {
-	shape := toSliceInt(ts.MustSize())
-	strides := shapeToStrides(shape)
-	size := shapeToNumels(shape)
-	mSize := shape[len(shape)-1] * shape[len(shape)-2]
-	var (
-		offset   int
-		printOne = false
-	)
+// summarize returns a tensor keeping only `EdgeItems` at beginning and end of each long
+// dimension if tensor has more than `Threshold` elements, and which dimensions are truncated.
+func (ts *Tensor) summarize(o *PrintOptions) (*Tensor, []bool, error) {
+	shape := ts.MustSize()
+	truncated := make([]bool, len(shape))
+	x := ts.MustShallowClone()
+	if int(ts.Numel()) <= o.Threshold {
+		return x, truncated, nil
+	}
 
-	for i := 0; i < size; i += int(mSize) {
-		dims := make([]int, len(strides)-2)
-		for n := 0; n < len(strides[:len(strides)-2]); n++ {
-			stride := strides[n]
-			var dim int = offset
-			for _, s := range strides[:n] {
-				dim = dim % s
-			}
-			dim = dim / stride
-			dims[n] = dim
+	edge := int64(max(o.EdgeItems, 1))
+	for d, n := range shape {
+		if n <= 2*edge {
+			continue
+		}
+		head := x.MustNarrow(int64(d), 0, edge, false)
+		tail := x.MustNarrow(int64(d), n-edge, edge, false)
+		next, err := Cat([]*Tensor{head, tail}, int64(d))
+		head.MustDrop()
+		tail.MustDrop()
+		x.MustDrop()
+		if err != nil {
+			return nil, nil, err
 		}
+		x = next
+		truncated[d] = true
+	}
 
-		var (
-			vlimit      = f.printCols
-			shouldPrint = true
-			printVTrunc bool
-			conds       []bool
-		)
-		for i, val := range dims {
-			maxDim := shape[i]
-			if (val < vlimit/2) || (val >= maxDim-vlimit/2) {
-				conds = append(conds, true)
-				shouldPrint = shouldPrint && true
-			} else {
-				conds = append(conds, false)
-				shouldPrint = shouldPrint && false
-
-				printVTrunc = true
-			}
-		} // inner for
-		if shouldPrint {
-			dimsLabel := "("
-			for _, d := range dims {
-				dimsLabel += fmt.Sprintf("%v, ", d)
+	return x, truncated, nil
+}
+
+// formatValues formats values of tensor to strings.
+func formatValues(x *Tensor, o *PrintOptions) ([]string, error) {
+	var vals []string
+	switch dtype := x.DType(); dtype {
+	case gotch.Bool:
+		bs, err := ToSlice[bool](x)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range bs {
+			vals = append(vals, strconv.FormatBool(v))
+		}
+	case gotch.Uint8, gotch.Int8, gotch.Int16, gotch.Int, gotch.Int64:
+		is, err := ToSlice[int64](x)
+		if err != nil {
+			return nil, err
+		}
+		base := o.IntBase
+		if base < 2 || base > 36 {
+			base = 10
+		}
+		for _, v := range is {
+			vals = append(vals, withSign(strconv.FormatInt(v, base), o.Sign))
+		}
+	case gotch.Half, gotch.BFloat16, gotch.Float, gotch.Double:
+		fs, err := ToSlice[float64](x)
+		if err != nil {
+			return nil, err
+		}
+		f := newFloatFormatter(fs, o)
+		for _, v := range fs {
+			vals = append(vals, f.format(v))
+		}
+	case gotch.ComplexHalf, gotch.ComplexFloat, gotch.ComplexDouble:
+		cs, err := ToSlice[complex128](x)
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]float64, 0, 2*len(cs))
+		for _, v := range cs {
+			parts = append(parts, real(v), imag(v))
+		}
+		f := newFloatFormatter(parts, o)
+		for _, v := range cs {
+			im := f.format(imag(v))
+			if !strings.HasPrefix(im, "-") {
+				im = "+" + strings.TrimLeft(im, "+ ")
 			}
-			dimsLabel += ".,.) =\n"
-			f.Write([]byte(dimsLabel))
+			vals = append(vals, f.format(real(v))+im+"i")
+		}
+	default:
+		err := fmt.Errorf("unsupported dtype %v for printing", dtype)
+		return nil, err
+	}
 
-			// Print matrix [H, W]
-			data := sliceInterface(values, offset, offset+mSize)
-			f.writeMatrix(data, shape[len(shape)-2:])
+	return vals, nil
+}
 
-			printOne = false
-		}
+func isIntDType(dtype gotch.DType) bool {
+	switch dtype {
+	case gotch.Uint8, gotch.Int8, gotch.Int16, gotch.Int, gotch.Int64:
+		return true
+	}
 
-		if printVTrunc && !printOne {
-			// NOTE. vertical truncation at > 2D level
-			vtrunc := fmt.Sprintf("...,\n\n")
-			f.Write([]byte(vtrunc))
-			printOne = true
-		}
-		offset += mSize
-	} // outer for
+	return false
 }
 
-func (f *fmtState) writeMatrix(data []interface{}, shape []int) {
-	n := shapeToNumels(shape)
-	dataLen := len(data)
-	if dataLen != n {
-		log.Fatalf("mismatched: slice data has %v elements - shape: %v\n", dataLen, n)
-	}
-	if len(shape) != 2 {
-		log.Fatal("Shape must have length of 2.\n")
-	}
-
-	stride := int(shape[1])
-	currIdx := 0
-	nextIdx := stride
-	truncatedRows := shape[0] - f.printCols
-	for row := 0; row < int(shape[0]); row++ {
-		var slice []interface{}
-		switch {
-		case row < f.printCols/2: // First part
-			slice = data[currIdx:nextIdx]
-			f.writeVector(slice)
-			currIdx = nextIdx
-			nextIdx += stride
-		case row == f.printCols/2: // Truncated sign
-			if f.printCols != f.cols { // truncated mode
-				f.writeVTrunc()
-			} else { // full mode
-				// Do nothing
-			}
-			currIdx = nextIdx
-			nextIdx += stride
-		case row > f.printCols/2 && row < f.printCols/2+truncatedRows: // Skip part
-			currIdx = nextIdx
-			nextIdx += stride
-		case row >= f.printCols/2+truncatedRows: // Second part
-			slice = data[currIdx:nextIdx]
-			f.writeVector(slice)
-			currIdx = nextIdx
-			nextIdx += stride
+func withSign(s string, sign rune) string {
+	if strings.HasPrefix(s, "-") || sign == '-' {
+		return s
+	}
+
+	return string(sign) + s
+}
+
+type floatFormatter struct {
+	sci       bool
+	integral  bool
+	precision int
+	sign      rune
+}
+
+// newFloatFormatter chooses a notation for all values as torch.
+func newFloatFormatter(vals []float64, o *PrintOptions) *floatFormatter {
+	f := &floatFormatter{precision: o.Precision, sign: o.Sign, integral: true}
+	minAbs, maxAbs := math.Inf(1), 0.0
+	for _, v := range vals {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
 		}
+		if v != math.Trunc(v) {
+			f.integral = false
+		}
+		if a := math.Abs(v); a != 0 {
+			minAbs = math.Min(minAbs, a)
+			maxAbs = math.Max(maxAbs, a)
+		}
+	}
+
+	switch o.SciMode {
+	case PrintSciAlways:
+		f.sci = true
+	case PrintSciNever:
+		f.sci = false
+	default:
+		f.sci = maxAbs > 0 && (maxAbs >= 1e8 || (!f.integral && (minAbs < 1e-4 || maxAbs/minAbs > 1e3)))
 	}
 
-	// 1 line between matrix
-	f.Write([]byte("\n"))
+	return f
+}
+
+func (f *floatFormatter) format(v float64) string {
+	var s string
+	switch {
+	case math.IsNaN(v):
+		s = "nan"
+	case math.IsInf(v, 1):
+		s = "inf"
+	case math.IsInf(v, -1):
+		s = "-inf"
+	case f.sci:
+		s = strconv.FormatFloat(v, 'e', f.precision, 64)
+	case f.integral:
+		s = strconv.FormatFloat(v, 'f', 0, 64) + "."
+	default:
+		s = strconv.FormatFloat(v, 'f', f.precision, 64)
+	}
 
+	return withSign(s, f.sign)
 }
 
-func (f *fmtState) writeVector(data []interface{}) {
-	format := f.initFmt()
-	vlen := len(data)
-	for col := 0; col < vlen; col++ {
-		if f.cols <= f.printCols || (col < f.printCols/2 || (col >= f.cols-f.printCols/2)) {
-			el := data[col]
-			// TODO: more format options here
-			w, _ := fmt.Fprintf(f.buf, format, el)
-			f.Write(f.buf.Bytes())
-			f.Write(f.pad[:f.w-w]) // prepad
-			f.Write(f.pad[:2])     // pad
-			f.buf.Reset()
-		} else if col == f.printCols/2 {
-			f.writeHTrunc()
+// writeValues writes padded values in nested brackets, with `...` in place of values
+// of truncated dimensions and lines wrapped at `LineWidth`.
+func writeValues(b *strings.Builder, vals []string, shape []int64, truncated []bool, o *PrintOptions, indent int) {
+	if len(shape) == 0 {
+		b.WriteString(vals[0])
+		return
+	}
+
+	n := int(shape[0])
+	edge := max(o.EdgeItems, 1)
+	b.WriteByte('[')
+	if len(shape) == 1 {
+		col := indent + 1
+		for i := 0; i < n; i++ {
+			item := vals[i]
+			if truncated[0] && i == edge {
+				item = "..., " + item
+			}
+			if i > 0 {
+				if col+2+len(item) > o.LineWidth {
+					b.WriteString(",\n" + strings.Repeat(" ", indent+1))
+					col = indent + 1
+				} else {
+					b.WriteString(", ")
+					col += 2
+				}
+			}
+			b.WriteString(item)
+			col += len(item)
 		}
+		b.WriteByte(']')
+		return
 	}
 
-	f.Write([]byte("\n"))
+	stride := 0
+	if n > 0 {
+		stride = len(vals) / n
+	}
+	sep := "," + strings.Repeat("\n", len(shape)-1) + strings.Repeat(" ", indent+1)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		if truncated[0] && i == edge {
+			b.WriteString("..." + sep)
+		}
+		writeValues(b, vals[i*stride:(i+1)*stride], shape[1:], truncated[1:], o, indent+1)
+	}
+	b.WriteByte(']')
 }
 
-// Print prints tensor meta data to stdout.
-func (ts *Tensor) Info() {
-	fmt.Printf("%i", ts)
+// summary returns shape, dtype and device of tensor with min, max, mean and standard
+// deviation of its non-NaN values and number of NaN values.
+func (ts *Tensor) summary(o *PrintOptions) (string, error) {
+	fields := []string{
+		fmt.Sprintf("shape=%v", ts.MustSize()),
+		fmt.Sprintf("dtype=%v", ts.DType()),
+		fmt.Sprintf("device=%v", ts.MustDevice()),
+	}
+	if ts.Numel() == 0 {
+		return "Tensor(" + strings.Join(fields, ", ") + ")", nil
+	}
+
+	var (
+		x   *Tensor
+		err error
+	)
+	switch ts.DType() {
+	case gotch.ComplexHalf, gotch.ComplexFloat, gotch.ComplexDouble:
+		// Statistics of absolute values.
+		if x, err = ts.Abs(false); err == nil {
+			x, err = x.Totype(gotch.Double, true)
+		}
+	default:
+		x, err = ts.Totype(gotch.Double, false)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer x.MustDrop()
+
+	nanMask := x.MustIsnan(false)
+	defer nanMask.MustDrop()
+	nans := nanMask.MustSum(gotch.Int64, false).Int64Values(true)[0]
+	valid := x.MustMaskedSelect(nanMask.MustLogicalNot(false), false)
+	defer valid.MustDrop()
+
+	if valid.Numel() > 0 {
+		stats := []float64{
+			valid.MustMin(false).Float64Values(true)[0],
+			valid.MustMax(false).Float64Values(true)[0],
+			valid.MustMean(gotch.Double, false).Float64Values(true)[0],
+			valid.MustStd(false, false).Float64Values(true)[0],
+		}
+		f := newFloatFormatter(stats, o)
+		f.integral = false
+		for i, name := range []string{"min", "max", "mean", "std"} {
+			fields = append(fields, name+"="+f.format(stats[i]))
+		}
+	}
+	fields = append(fields, fmt.Sprintf("nan=%d", nans))
+
+	return "Tensor(" + strings.Join(fields, ", ") + ")", nil
 }
@@ -2,9 +2,12 @@ package ts_test
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/half"
 	"github.com/nullbull/gotch/ts"
 )
 
@@ -19,3 +22,93 @@ func TestTensor_Format(t *testing.T) {
 
 	// fmt.Printf("%#0.1f", x) // print full data
 }
+
+func TestTensor_Sprint(t *testing.T) {
+	tests := []struct {
+		x    *ts.Tensor
+		opts []ts.PrintOpt
+		want string
+	}{
+		{ts.MustArange(ts.IntScalar(6), gotch.Float, gotch.CPU).MustView([]int64{2, 3}, true), nil, "[[0., 1., 2.],\n [3., 4., 5.]]"},
+		{ts.MustFromSlice([]float64{1.5, -2.25}), []ts.PrintOpt{ts.WithPrintPrecision(2)}, "[ 1.50, -2.25]"},
+		{ts.MustFromSlice([]int64{1, -2}), []ts.PrintOpt{ts.WithPrintSign('+')}, "[+1, -2]"},
+		{ts.MustFromSlice([]float64{1e-5, 1}), nil, "[1.0000e-05, 1.0000e+00]"},
+		{ts.MustFromSlice([]float64{1e-5, 1}), []ts.PrintOpt{ts.WithPrintSciMode(ts.PrintSciNever), ts.WithPrintPrecision(5)}, "[0.00001, 1.00000]"},
+		{ts.MustArange(ts.IntScalar(10), gotch.Int64, gotch.CPU), []ts.PrintOpt{ts.WithPrintThreshold(5), ts.WithPrintEdgeItems(2)}, "[0, 1, ..., 8, 9]"},
+		{ts.MustArange(ts.IntScalar(25), gotch.Int64, gotch.CPU).MustView([]int64{5, 5}, true), []ts.PrintOpt{ts.WithPrintThreshold(10), ts.WithPrintEdgeItems(1)}, "[[ 0, ...,  4],\n ...,\n [20, ..., 24]]"},
+		{ts.MustFromSlice([]bool{true, false}), nil, "[ true, false]"},
+		{ts.MustFromSlice([]complex128{1 + 2i, -3.5i}), nil, "[1.0000+2.0000i, 0.0000-3.5000i]"},
+		{ts.MustFromSlice([]half.Float16{half.Fromfloat32(1.5)}), nil, "[1.5000]"},
+		{ts.MustFromSlice([]float32{float32(math.NaN()), float32(math.Inf(-1)), 0.5}), nil, "[   nan,   -inf, 0.5000]"},
+	}
+	for i, tt := range tests {
+		if got := tt.x.Sprint(tt.opts...); got != tt.want {
+			t.Errorf("%d: expected:\n%s\ngot:\n%s", i, tt.want, got)
+		}
+	}
+
+	// Lines are wrapped at line width.
+	got := ts.MustArange(ts.IntScalar(20), gotch.Int64, gotch.CPU).Sprint(ts.WithPrintLineWidth(20))
+	lines := strings.Split(got, "\n")
+	if len(lines) < 2 {
+		t.Errorf("Expected wrapped lines, got %q", got)
+	}
+	for _, line := range lines {
+		if len(line) > 20 {
+			t.Errorf("Expected line width <= 20, got %q", line)
+		}
+	}
+}
+
+func TestTensor_FormatIntBase(t *testing.T) {
+	x := ts.MustFromSlice([]int64{10, 255, -1})
+	for _, tt := range []struct{ format, want string }{
+		{"%v", "[ 10, 255,  -1]\n"},
+		{"%x", "[ a, ff, -1]\n"},
+		{"%X", "[ A, FF, -1]\n"},
+		{"%o", "[ 12, 377,  -1]\n"},
+		{"%b", "[    1010, 11111111,       -1]\n"},
+	} {
+		if got := fmt.Sprintf(tt.format, x); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.format, tt.want, got)
+		}
+	}
+}
+
+func TestTensor_SprintSummary(t *testing.T) {
+	x := ts.MustFromSlice([]float64{1, 2, 3, math.NaN()})
+	got := x.Sprint(ts.WithPrintSummary(true))
+	for _, want := range []string{"shape=[4]", "dtype=Double", "min=1.0000", "max=3.0000", "mean=2.0000", "std=0.8165", "nan=1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in summary, got %q", want, got)
+		}
+	}
+}
+
+func TestSetPrintOptions(t *testing.T) {
+	defer ts.ResetPrintOptions()
+
+	x := ts.MustFromSlice([]float64{0.5, 1.25})
+	ts.SetPrintOptions(ts.WithPrintPrecision(1))
+	if got, want := fmt.Sprintf("%v", x), "[0.5, 1.2]\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	// Verb precision overrides global option.
+	if got, want := fmt.Sprintf("%.3f", x), "[0.500, 1.250]\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	ts.ResetPrintOptions()
+	if got := ts.CurrentPrintOptions().Precision; got != 4 {
+		t.Errorf("Expected default precision 4, got %d", got)
+	}
+}
+
+func TestTensor_FormatFlat(t *testing.T) {
+	// Non-contiguous tensor.
+	x := ts.MustArange(ts.IntScalar(6), gotch.Float, gotch.CPU).MustView([]int64{2, 3}, true).MustT(true)
+	got := fmt.Sprintf("%-v", x)
+	if want := "[0., 3., 1., 4., 2., 5.]\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
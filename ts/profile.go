@@ -0,0 +1,400 @@
+package ts
+
+// Live tensor allocation profiler.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nullbull/gotch"
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+const (
+	allocMaxStack = 32
+	tsPkgPrefix   = "github.com/nullbull/gotch/ts."
+)
+
+// allocRecord holds creation info of a live tensor.
+type allocRecord struct {
+	stack  []uintptr
+	dtype  string
+	device string
+	nbytes int64
+}
+
+var (
+	allocProfiling int32                           // 1 when profiler is on.
+	allocRecords   = make(map[string]*allocRecord) // tensor name -> record. Guarded by `lock`.
+)
+
+// StartAllocProfile starts recording creation stack, dtype, device and size of
+// every tensor created from now on until it is freed.
+//
+// NOTE. Recording adds a stack walk and a few C calls to each tensor creation.
+// Size is taken at creation and is not updated by in-place resizing.
+func StartAllocProfile() {
+	atomic.StoreInt32(&allocProfiling, 1)
+}
+
+// StopAllocProfile stops recording and discards records of live tensors.
+func StopAllocProfile() {
+	atomic.StoreInt32(&allocProfiling, 0)
+	lock.Lock()
+	allocRecords = make(map[string]*allocRecord)
+	lock.Unlock()
+}
+
+// IsAllocProfileEnabled returns whether allocation profiler is on.
+func IsAllocProfileEnabled() bool {
+	return atomic.LoadInt32(&allocProfiling) == 1
+}
+
+// recordAlloc records a newly created tensor if profiler is on.
+func recordAlloc(x *Tensor) {
+	if atomic.LoadInt32(&allocProfiling) == 0 {
+		return
+	}
+
+	var pcs [allocMaxStack]uintptr
+	// skip runtime.Callers, recordAlloc and newTensor.
+	n := runtime.Callers(3, pcs[:])
+	r := &allocRecord{stack: append([]uintptr{}, pcs[:n]...)}
+
+	if lib.AtDefined(x.ctensor) {
+		r.dtype = x.DType().String()
+		r.nbytes = x.nbytes()
+		if d, err := x.Device(); err == nil {
+			r.device = deviceString(d)
+		}
+	}
+
+	lock.Lock()
+	if _, ok := ExistingTensors[x.name]; ok {
+		allocRecords[x.name] = r
+	}
+	lock.Unlock()
+}
+
+func deviceString(d gotch.Device) string {
+	if d.Name == gotch.CPU.Name {
+		return d.Name
+	}
+
+	return fmt.Sprintf("%s:%d", d.Name, d.Value)
+}
+
+// AllocSite is a source location creating live tensors.
+type AllocSite struct {
+	Function string
+	File     string
+	Line     int
+	Count    int64 // number of live tensors
+	Bytes    int64 // total size of live tensors
+}
+
+// String returns site as "function (file:line)".
+func (s AllocSite) String() string {
+	return fmt.Sprintf("%s (%s:%d)", s.Function, s.File, s.Line)
+}
+
+// allocSite returns the first frame of stack outside of this package, i.e. the user code
+// calling a tensor op.
+func allocSite(stack []uintptr) (fn, file string, line int) {
+	var f runtime.Frame
+	frames := runtime.CallersFrames(stack)
+	for more := true; more; {
+		f, more = frames.Next()
+		if !strings.HasPrefix(f.Function, tsPkgPrefix) {
+			break
+		}
+	}
+
+	return f.Function, f.File, f.Line
+}
+
+// AllocSites returns sites creating live tensors recorded by the profiler sorted
+// by total bytes then count in descending order.
+func AllocSites() []AllocSite {
+	lock.Lock()
+	stacks := make([][]uintptr, 0, len(allocRecords))
+	sizes := make([]int64, 0, len(allocRecords))
+	for _, r := range allocRecords {
+		stacks = append(stacks, r.stack)
+		sizes = append(sizes, r.nbytes)
+	}
+	lock.Unlock()
+
+	type key struct {
+		fn, file string
+		line     int
+	}
+	sites := make(map[key]*AllocSite)
+	for i, stack := range stacks {
+		fn, file, line := allocSite(stack)
+		k := key{fn, file, line}
+		s, ok := sites[k]
+		if !ok {
+			s = &AllocSite{Function: fn, File: file, Line: line}
+			sites[k] = s
+		}
+		s.Count++
+		s.Bytes += sizes[i]
+	}
+
+	retVal := make([]AllocSite, 0, len(sites))
+	for _, s := range sites {
+		retVal = append(retVal, *s)
+	}
+	sort.Slice(retVal, func(i, j int) bool {
+		a, b := retVal[i], retVal[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.String() < b.String()
+	})
+
+	return retVal
+}
+
+// AllocReport returns a report of top `n` sites creating live tensors. If `n` <= 0,
+// all sites are reported.
+func AllocReport(n int) string {
+	sites := AllocSites()
+	var count, nbytes int64
+	for _, s := range sites {
+		count += s.Count
+		nbytes += s.Bytes
+	}
+	if n > 0 && n < len(sites) {
+		sites = sites[:n]
+	}
+
+	var msg string
+	msg += fmt.Sprintf("============================= TENSOR ALLOCATION PROFILE ==============================\n")
+	msg += fmt.Sprintf("Live tensors: %d - %d bytes\n", count, nbytes)
+	msg += fmt.Sprintf("%14s %8s  %s\n", "BYTES", "COUNT", "SITE")
+	for _, s := range sites {
+		msg += fmt.Sprintf("%14d %8d  %s\n", s.Bytes, s.Count, s)
+	}
+	msg += fmt.Sprintf("======================================================================================\n")
+
+	return msg
+}
+
+// WriteAllocProfile writes live tensors recorded by the profiler as a gzipped pprof
+// heap profile with `inuse_objects` and `inuse_space` sample types and `dtype`, `device` labels.
+//
+// Example:
+//
+//	f, _ := os.Create("tensors.pb.gz")
+//	ts.WriteAllocProfile(f)
+//	// go tool pprof -top tensors.pb.gz
+func WriteAllocProfile(w io.Writer) error {
+	lock.Lock()
+	records := make([]*allocRecord, 0, len(allocRecords))
+	for _, r := range allocRecords {
+		records = append(records, r)
+	}
+	lock.Unlock()
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(encodeAllocProfile(records)); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// pprof encoding:
+// ===============
+// Ref. https://github.com/google/pprof/blob/main/proto/profile.proto
+
+// protobuf is a minimal protocol buffer encoder.
+type protobuf struct {
+	bytes.Buffer
+}
+
+func (b *protobuf) varint(v uint64) {
+	for v >= 0x80 {
+		b.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	b.WriteByte(byte(v))
+}
+
+func (b *protobuf) uint64(tag int, v uint64) {
+	b.varint(uint64(tag)<<3 | 0)
+	b.varint(v)
+}
+
+func (b *protobuf) int64(tag int, v int64) {
+	b.uint64(tag, uint64(v))
+}
+
+func (b *protobuf) bytes(tag int, v []byte) {
+	b.varint(uint64(tag)<<3 | 2)
+	b.varint(uint64(len(v)))
+	b.Write(v)
+}
+
+func (b *protobuf) string(tag int, v string) {
+	b.bytes(tag, []byte(v))
+}
+
+func (b *protobuf) packed(tag int, vs []uint64) {
+	var p protobuf
+	for _, v := range vs {
+		p.varint(v)
+	}
+	b.bytes(tag, p.Bytes())
+}
+
+// profileBuilder builds a pprof profile.
+type profileBuilder struct {
+	strings   []string
+	stringIdx map[string]int64
+	locs      map[uintptr]uint64
+	funcs     map[string]uint64
+	p         protobuf
+}
+
+func (pb *profileBuilder) str(s string) int64 {
+	if i, ok := pb.stringIdx[s]; ok {
+		return i
+	}
+	i := int64(len(pb.strings))
+	pb.strings = append(pb.strings, s)
+	pb.stringIdx[s] = i
+
+	return i
+}
+
+func (pb *profileBuilder) valueType(tag int, typ, unit string) {
+	var vt protobuf
+	vt.int64(1, pb.str(typ))
+	vt.int64(2, pb.str(unit))
+	pb.p.bytes(tag, vt.Bytes())
+}
+
+func (pb *profileBuilder) function(f runtime.Frame) uint64 {
+	key := f.Function + "\x00" + f.File
+	if id, ok := pb.funcs[key]; ok {
+		return id
+	}
+	id := uint64(len(pb.funcs) + 1)
+	pb.funcs[key] = id
+
+	var fn protobuf
+	fn.uint64(1, id)
+	fn.int64(2, pb.str(f.Function))
+	fn.int64(3, pb.str(f.Function))
+	fn.int64(4, pb.str(f.File))
+	pb.p.bytes(5, fn.Bytes())
+
+	return id
+}
+
+func (pb *profileBuilder) location(pc uintptr) uint64 {
+	if id, ok := pb.locs[pc]; ok {
+		return id
+	}
+	id := uint64(len(pb.locs) + 1)
+	pb.locs[pc] = id
+
+	var loc protobuf
+	loc.uint64(1, id)
+	loc.uint64(3, uint64(pc))
+	// NOTE. inlined frames come first.
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		f, more := frames.Next()
+		var line protobuf
+		line.uint64(1, pb.function(f))
+		line.int64(2, int64(f.Line))
+		loc.bytes(4, line.Bytes())
+		if !more {
+			break
+		}
+	}
+	pb.p.bytes(4, loc.Bytes())
+
+	return id
+}
+
+func encodeAllocProfile(records []*allocRecord) []byte {
+	pb := &profileBuilder{
+		strings:   []string{""},
+		stringIdx: map[string]int64{"": 0},
+		locs:      make(map[uintptr]uint64),
+		funcs:     make(map[string]uint64),
+	}
+
+	pb.valueType(1, "inuse_objects", "count")
+	pb.valueType(1, "inuse_space", "bytes")
+
+	// Aggregate tensors with the same stack, dtype and device.
+	type sample struct {
+		r      *allocRecord
+		count  int64
+		nbytes int64
+	}
+	var keys []string
+	samples := make(map[string]*sample)
+	for _, r := range records {
+		k := fmt.Sprint(r.stack, r.dtype, r.device)
+		s, ok := samples[k]
+		if !ok {
+			s = &sample{r: r}
+			samples[k] = s
+			keys = append(keys, k)
+		}
+		s.count++
+		s.nbytes += r.nbytes
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := samples[k]
+		locIDs := make([]uint64, len(s.r.stack))
+		for i, pc := range s.r.stack {
+			locIDs[i] = pb.location(pc)
+		}
+
+		var sp protobuf
+		sp.packed(1, locIDs)
+		sp.packed(2, []uint64{uint64(s.count), uint64(s.nbytes)})
+		for _, l := range [][2]string{{"dtype", s.r.dtype}, {"device", s.r.device}} {
+			if l[1] == "" {
+				continue
+			}
+			var label protobuf
+			label.int64(1, pb.str(l[0]))
+			label.int64(2, pb.str(l[1]))
+			sp.bytes(3, label.Bytes())
+		}
+		pb.p.bytes(2, sp.Bytes())
+	}
+
+	pb.p.int64(9, time.Now().UnixNano())
+	pb.valueType(11, "space", "bytes")
+	pb.p.int64(12, 1)
+	pb.p.int64(14, pb.str("inuse_space"))
+
+	// NOTE. string table is written last as strings are interned while encoding.
+	for _, s := range pb.strings {
+		pb.p.string(6, s)
+	}
+
+	return pb.p.Bytes()
+}
@@ -0,0 +1,71 @@
+package ts_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+func allocTensors(n int) []*ts.Tensor {
+	xs := make([]*ts.Tensor, n)
+	for i := range xs {
+		xs[i] = ts.MustZeros([]int64{2, 3}, gotch.Float, gotch.CPU)
+	}
+
+	return xs
+}
+
+func findSite(fn string) (ts.AllocSite, bool) {
+	for _, s := range ts.AllocSites() {
+		if strings.HasSuffix(s.Function, fn) {
+			return s, true
+		}
+	}
+
+	return ts.AllocSite{}, false
+}
+
+func TestAllocProfile(t *testing.T) {
+	ts.StartAllocProfile()
+	defer ts.StopAllocProfile()
+
+	xs := allocTensors(3)
+	s, ok := findSite(".allocTensors")
+	if !ok {
+		t.Fatalf("Expected allocation site of allocTensors in profile:\n%s", ts.AllocReport(0))
+	}
+	if s.Count != 3 || s.Bytes != 3*2*3*4 {
+		t.Errorf("Expected 3 tensors of 72 bytes, got %v tensors of %v bytes\n", s.Count, s.Bytes)
+	}
+	if !strings.Contains(ts.AllocReport(1), "allocTensors") {
+		t.Errorf("Expected allocTensors in top site report\n")
+	}
+
+	var buf bytes.Buffer
+	if err := ts.WriteAllocProfile(&buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte("allocTensors")) || !bytes.Contains(data, []byte("inuse_space")) {
+		t.Errorf("Expected pprof profile with allocTensors samples\n")
+	}
+
+	for _, x := range xs {
+		x.MustDrop()
+	}
+	if _, ok := findSite(".allocTensors"); ok {
+		t.Errorf("Expected no live tensors from allocTensors after drop\n")
+	}
+}
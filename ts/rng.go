@@ -0,0 +1,441 @@
+package ts
+
+// Random number generators and reproducibility.
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+
+	"github.com/nullbull/gotch"
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+// ManualSeed seeds the default random number generators of libtorch on all devices and
+// the Go random number generator of gotch packages (`gotch.SeedRand`) used by data
+// samplers (dutil) and image augmentations (vision/aug), so that a run can be reproduced.
+func ManualSeed(seed int64) {
+	lib.AtManualSeed(seed)
+	if err := TorchErr(); err != nil {
+		log.Fatal(err)
+	}
+
+	gotch.SeedRand(seed)
+}
+
+// SetDeterministicAlgorithms makes ops use deterministic algorithms when enabled. Ops
+// without a deterministic implementation then fail, or only warn if optional `warnOnly`
+// is true.
+//
+// NOTE. This is global in libtorch. Some CUDA ops additionally require environment
+// variable `CUBLAS_WORKSPACE_CONFIG=:4096:8` to be deterministic.
+func SetDeterministicAlgorithms(enabled bool, warnOnlyOpt ...bool) {
+	warnOnly := false
+	if len(warnOnlyOpt) > 0 {
+		warnOnly = warnOnlyOpt[0]
+	}
+
+	lib.AtSetDeterministicAlgorithms(boolToInt(enabled), boolToInt(warnOnly))
+}
+
+// AreDeterministicAlgorithmsEnabled returns whether deterministic algorithms are enabled.
+func AreDeterministicAlgorithmsEnabled() bool {
+	return lib.AtDeterministicAlgorithms() == 1
+}
+
+// WithDeterministicAlgorithms runs a closure with deterministic algorithms enabled and
+// restores the previous mode on return.
+func WithDeterministicAlgorithms(fn func()) {
+	prev := AreDeterministicAlgorithmsEnabled()
+	prevWarnOnly := lib.AtDeterministicAlgorithmsWarnOnly() == 1
+	SetDeterministicAlgorithms(true)
+	defer SetDeterministicAlgorithms(prev, prevWarnOnly)
+
+	fn()
+}
+
+// Generator is a random number generator of a device which can be passed to random ops,
+// independent from the default generator unless returned by `DefaultGenerator`.
+type Generator struct {
+	cgen   lib.Cgenerator
+	device gotch.Device
+}
+
+func newGenerator(cgen lib.Cgenerator, device gotch.Device) *Generator {
+	g := &Generator{cgen: cgen, device: device}
+	runtime.SetFinalizer(g, freeCGenerator)
+
+	return g
+}
+
+func freeCGenerator(g *Generator) error {
+	if g == nil || g.cgen == nil {
+		return nil
+	}
+
+	lib.AtGeneratorFree(g.cgen)
+	g.cgen = nil
+	return TorchErr()
+}
+
+// NewGenerator creates a random number generator of device seeded with seed.
+func NewGenerator(device gotch.Device, seed int64) (*Generator, error) {
+	cgen := lib.AtGeneratorNew(int(device.CInt()), seed)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("NewGenerator() failed: %w", err)
+		return nil, err
+	}
+
+	return newGenerator(cgen, device), nil
+}
+
+// MustNewGenerator creates a random number generator of device. It panics if error.
+func MustNewGenerator(device gotch.Device, seed int64) *Generator {
+	g, err := NewGenerator(device, seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return g
+}
+
+// DefaultGenerator returns the default random number generator of device, used by random
+// ops without generator.
+func DefaultGenerator(device gotch.Device) (*Generator, error) {
+	cgen := lib.AtDefaultGenerator(int(device.CInt()))
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("DefaultGenerator() failed: %w", err)
+		return nil, err
+	}
+
+	return newGenerator(cgen, device), nil
+}
+
+// MustDefaultGenerator returns the default random number generator of device. It panics if error.
+func MustDefaultGenerator(device gotch.Device) *Generator {
+	g, err := DefaultGenerator(device)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return g
+}
+
+// Device returns device of the generator.
+func (g *Generator) Device() gotch.Device {
+	return g.device
+}
+
+// ManualSeed seeds the generator.
+func (g *Generator) ManualSeed(seed int64) error {
+	lib.AtGeneratorManualSeed(g.cgen, seed)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("ManualSeed() failed: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+// MustManualSeed seeds the generator. It panics if error.
+func (g *Generator) MustManualSeed(seed int64) {
+	if err := g.ManualSeed(seed); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Seed returns the seed of the generator.
+func (g *Generator) Seed() (int64, error) {
+	seed := lib.AtGeneratorSeed(g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Seed() failed: %w", err)
+		return 0, err
+	}
+
+	return seed, nil
+}
+
+// MustSeed returns the seed of the generator. It panics if error.
+func (g *Generator) MustSeed() int64 {
+	seed, err := g.Seed()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return seed
+}
+
+// GetState returns the state of the generator as a uint8 CPU tensor, e.g. to save it in
+// a checkpoint.
+func (g *Generator) GetState() (*Tensor, error) {
+	ctensor := lib.AtGeneratorGetState(g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("GetState() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustGetState returns the state of the generator. It panics if error.
+func (g *Generator) MustGetState() *Tensor {
+	state, err := g.GetState()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return state
+}
+
+// SetState restores the state of the generator returned by `GetState`.
+func (g *Generator) SetState(state *Tensor) error {
+	lib.AtGeneratorSetState(g.cgen, state.ctensor)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("SetState() failed: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+// MustSetState restores the state of the generator. It panics if error.
+func (g *Generator) MustSetState(state *Tensor) {
+	if err := g.SetState(state); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Drop releases the generator. The default generator of a device remains valid.
+func (g *Generator) Drop() error {
+	runtime.SetFinalizer(g, nil)
+	return freeCGenerator(g)
+}
+
+// MustDrop releases the generator. It panics if error.
+func (g *Generator) MustDrop() {
+	if err := g.Drop(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// GetRNGState returns the state of the default random number generator of device.
+func GetRNGState(device gotch.Device) (*Tensor, error) {
+	g, err := DefaultGenerator(device)
+	if err != nil {
+		err = fmt.Errorf("GetRNGState() failed: %w", err)
+		return nil, err
+	}
+	defer g.MustDrop()
+
+	state, err := g.GetState()
+	if err != nil {
+		err = fmt.Errorf("GetRNGState() failed: %w", err)
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// MustGetRNGState returns the state of the default random number generator of device. It panics if error.
+func MustGetRNGState(device gotch.Device) *Tensor {
+	state, err := GetRNGState(device)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return state
+}
+
+// SetRNGState restores the state of the default random number generator of device
+// returned by `GetRNGState`.
+func SetRNGState(device gotch.Device, state *Tensor) error {
+	g, err := DefaultGenerator(device)
+	if err != nil {
+		err = fmt.Errorf("SetRNGState() failed: %w", err)
+		return err
+	}
+	defer g.MustDrop()
+
+	if err := g.SetState(state); err != nil {
+		err = fmt.Errorf("SetRNGState() failed: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+// MustSetRNGState restores the state of the default random number generator of device. It panics if error.
+func MustSetRNGState(device gotch.Device, state *Tensor) {
+	if err := SetRNGState(device, state); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Random ops with generator:
+// ==========================
+
+// Rand returns a tensor of shape with values drawn uniformly from [0, 1) by the generator.
+func (g *Generator) Rand(shape []int64, dtype gotch.DType) (*Tensor, error) {
+	ctensor := lib.AtRandGen(shape, int(dtype.CKind()), int(g.device.CInt()), g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Rand() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustRand returns a tensor of uniform random values. It panics if error.
+func (g *Generator) MustRand(shape []int64, dtype gotch.DType) *Tensor {
+	x, err := g.Rand(shape, dtype)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// Randn returns a tensor of shape with values drawn from the standard normal distribution
+// by the generator.
+func (g *Generator) Randn(shape []int64, dtype gotch.DType) (*Tensor, error) {
+	ctensor := lib.AtRandnGen(shape, int(dtype.CKind()), int(g.device.CInt()), g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Randn() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustRandn returns a tensor of normal random values. It panics if error.
+func (g *Generator) MustRandn(shape []int64, dtype gotch.DType) *Tensor {
+	x, err := g.Randn(shape, dtype)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// Randint returns a tensor of shape with integers drawn uniformly from [low, high) by
+// the generator.
+func (g *Generator) Randint(low, high int64, shape []int64, dtype gotch.DType) (*Tensor, error) {
+	ctensor := lib.AtRandintGen(low, high, shape, int(dtype.CKind()), int(g.device.CInt()), g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Randint() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustRandint returns a tensor of random integers. It panics if error.
+func (g *Generator) MustRandint(low, high int64, shape []int64, dtype gotch.DType) *Tensor {
+	x, err := g.Randint(low, high, shape, dtype)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// Randperm returns a random permutation of integers in [0, n) drawn by the generator.
+func (g *Generator) Randperm(n int64, dtype gotch.DType) (*Tensor, error) {
+	ctensor := lib.AtRandpermGen(n, int(dtype.CKind()), int(g.device.CInt()), g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Randperm() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustRandperm returns a random permutation. It panics if error.
+func (g *Generator) MustRandperm(n int64, dtype gotch.DType) *Tensor {
+	x, err := g.Randperm(n, dtype)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// Uniform_ fills tensor in-place with values drawn uniformly from [from, to) by the generator.
+func (g *Generator) Uniform_(x *Tensor, from, to float64) error {
+	lib.AtUniformGen_(x.ctensor, from, to, g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Uniform_() failed: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+// MustUniform_ fills tensor in-place with uniform random values. It panics if error.
+func (g *Generator) MustUniform_(x *Tensor, from, to float64) {
+	if err := g.Uniform_(x, from, to); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Normal_ fills tensor in-place with values drawn from the normal distribution of mean
+// and standard deviation stddev by the generator.
+func (g *Generator) Normal_(x *Tensor, mean, stddev float64) error {
+	lib.AtNormalGen_(x.ctensor, mean, stddev, g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Normal_() failed: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+// MustNormal_ fills tensor in-place with normal random values. It panics if error.
+func (g *Generator) MustNormal_(x *Tensor, mean, stddev float64) {
+	if err := g.Normal_(x, mean, stddev); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Bernoulli returns a tensor of 0s and 1s drawn by the generator with probabilities `p`.
+func (g *Generator) Bernoulli(p *Tensor) (*Tensor, error) {
+	ctensor := lib.AtBernoulliGen(p.ctensor, g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Bernoulli() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustBernoulli returns a tensor of Bernoulli samples. It panics if error.
+func (g *Generator) MustBernoulli(p *Tensor) *Tensor {
+	x, err := g.Bernoulli(p)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// Multinomial returns indices of numSamples drawn by the generator from the multinomial
+// distribution of weights `x` (for each row if 2-D).
+func (g *Generator) Multinomial(x *Tensor, numSamples int64, replacement bool) (*Tensor, error) {
+	ctensor := lib.AtMultinomialGen(x.ctensor, numSamples, boolToInt(replacement), g.cgen)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Multinomial() failed: %w", err)
+		return nil, err
+	}
+
+	return newTensor(ctensor), nil
+}
+
+// MustMultinomial returns indices of multinomial samples. It panics if error.
+func (g *Generator) MustMultinomial(x *Tensor, numSamples int64, replacement bool) *Tensor {
+	idx, err := g.Multinomial(x, numSamples, replacement)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return idx
+}
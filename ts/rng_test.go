@@ -0,0 +1,113 @@
+package ts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestManualSeed(t *testing.T) {
+	ts.ManualSeed(42)
+	x := ts.MustRand([]int64{5}, gotch.Float, gotch.CPU)
+	i := gotch.RandIntn(1000)
+
+	ts.ManualSeed(42)
+	y := ts.MustRand([]int64{5}, gotch.Float, gotch.CPU)
+	if got, want := ts.MustToSlice[float32](y), ts.MustToSlice[float32](x); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := gotch.RandIntn(1000); got != i {
+		t.Errorf("Expected Go random number %d, got %d", i, got)
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	g := ts.MustNewGenerator(gotch.CPU, 7)
+	defer g.MustDrop()
+	if got, want := g.MustSeed(), int64(7); got != want {
+		t.Errorf("Expected seed %d, got %d", want, got)
+	}
+
+	x := ts.MustToSlice[float32](g.MustRandn([]int64{4}, gotch.Float))
+	g.MustManualSeed(7)
+	y := ts.MustToSlice[float32](g.MustRandn([]int64{4}, gotch.Float))
+	if !reflect.DeepEqual(x, y) {
+		t.Errorf("Expected %v, got %v", x, y)
+	}
+
+	// Independent from default generator.
+	g.MustManualSeed(7)
+	ts.MustRandn([]int64{4}, gotch.Float, gotch.CPU)
+	z := ts.MustToSlice[float32](g.MustRandn([]int64{4}, gotch.Float))
+	if !reflect.DeepEqual(x, z) {
+		t.Errorf("Expected %v, got %v", x, z)
+	}
+
+	perm := ts.MustToSlice[int64](g.MustRandperm(5, gotch.Int64))
+	if len(perm) != 5 {
+		t.Errorf("Expected permutation of 5 elements, got %v", perm)
+	}
+	ints := ts.MustToSlice[int64](g.MustRandint(3, 5, []int64{10}, gotch.Int64))
+	for _, v := range ints {
+		if v < 3 || v >= 5 {
+			t.Errorf("Expected integers in [3, 5), got %v", ints)
+		}
+	}
+
+	u := ts.MustZeros([]int64{10}, gotch.Double, gotch.CPU)
+	g.MustUniform_(u, 2, 3)
+	for _, v := range ts.MustToSlice[float64](u) {
+		if v < 2 || v >= 3 {
+			t.Errorf("Expected values in [2, 3), got %v", v)
+		}
+	}
+
+	p := ts.MustFromSlice([]float32{0, 1, 0})
+	if got, want := ts.MustToSlice[int64](g.MustMultinomial(p, 2, true)), []int64{1, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got, want := ts.MustToSlice[float32](g.MustBernoulli(p)), []float32{0, 1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestRNGState(t *testing.T) {
+	g := ts.MustNewGenerator(gotch.CPU, 1)
+	defer g.MustDrop()
+	state := g.MustGetState()
+	x := ts.MustToSlice[float32](g.MustRand([]int64{3}, gotch.Float))
+	g.MustSetState(state)
+	if y := ts.MustToSlice[float32](g.MustRand([]int64{3}, gotch.Float)); !reflect.DeepEqual(x, y) {
+		t.Errorf("Expected %v, got %v", x, y)
+	}
+
+	// Default generator.
+	state = ts.MustGetRNGState(gotch.CPU)
+	x = ts.MustToSlice[float32](ts.MustRand([]int64{3}, gotch.Float, gotch.CPU))
+	ts.MustSetRNGState(gotch.CPU, state)
+	if y := ts.MustToSlice[float32](ts.MustRand([]int64{3}, gotch.Float, gotch.CPU)); !reflect.DeepEqual(x, y) {
+		t.Errorf("Expected %v, got %v", x, y)
+	}
+}
+
+func TestDeterministicAlgorithms(t *testing.T) {
+	prev := ts.AreDeterministicAlgorithmsEnabled()
+	ts.WithDeterministicAlgorithms(func() {
+		if !ts.AreDeterministicAlgorithmsEnabled() {
+			t.Errorf("Expected deterministic algorithms enabled")
+		}
+	})
+	if got := ts.AreDeterministicAlgorithmsEnabled(); got != prev {
+		t.Errorf("Expected deterministic algorithms mode %v restored, got %v", prev, got)
+	}
+}
+
+func TestGeneratorDoubleDrop(t *testing.T) {
+	g := ts.MustNewGenerator(gotch.CPU, 7)
+	g.MustDrop()
+	if err := g.Drop(); err != nil {
+		t.Errorf("Expected no error dropping generator twice, got %v", err)
+	}
+}
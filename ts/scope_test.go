@@ -0,0 +1,106 @@
+package ts_test
+
+import (
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+func TestScope(t *testing.T) {
+	var kept *ts.Tensor
+	ts.WithNamedScope("outer", func(s *ts.Scope) {
+		ts.WithNamedScope("inner", func(s1 *ts.Scope) {
+			if s1.Name() != "outer/inner" {
+				t.Errorf("Expected scope name %q, got %q\n", "outer/inner", s1.Name())
+			}
+			x := ts.MustOnes([]int64{2, 3}, gotch.Float, gotch.CPU)
+			x.MustMulScalar(ts.FloatScalar(2.0), false)
+			kept = x.MustAddScalar(ts.FloatScalar(1.0), false)
+			s1.Escape(kept)
+			if s1.Len() != 2 {
+				t.Errorf("Expected 2 tensors tracked in inner scope, got %v\n", s1.Len())
+			}
+		})
+
+		if _, ok := ts.CheckCMemLeakByScope()["outer/inner"]; ok {
+			t.Errorf("Expected tensors of inner scope released\n")
+		}
+		if s.Len() != 1 {
+			t.Errorf("Expected escaped tensor tracked by outer scope, got %v tensors\n", s.Len())
+		}
+		if got := kept.Float64Values(); got[0] != 2.0 {
+			t.Errorf("Expected escaped tensor value 2.0, got %v\n", got[0])
+		}
+	})
+
+	if _, ok := ts.CheckCMemLeakByScope()["outer"]; ok {
+		t.Errorf("Expected tensors of outer scope released\n")
+	}
+}
+
+func TestWithScopeTensor(t *testing.T) {
+	out := ts.WithScopeTensor(func(s *ts.Scope) *ts.Tensor {
+		x := ts.MustOnes([]int64{3}, gotch.Float, gotch.CPU)
+		y := x.MustMulScalar(ts.FloatScalar(3.0), false)
+		return y.MustSum(gotch.Float, true)
+	})
+	defer out.MustDrop()
+
+	if got := out.Float64Values()[0]; got != 9.0 {
+		t.Errorf("Expected 9.0, got %v\n", got)
+	}
+	if ts.CurrentScope() != nil {
+		t.Errorf("Expected no active scope after WithScopeTensor\n")
+	}
+}
+
+func TestScopeOtherGoroutine(t *testing.T) {
+	ts.WithScope(func(s *ts.Scope) {
+		done := make(chan *ts.Scope)
+		go func() {
+			x := ts.MustOnes([]int64{1}, gotch.Float, gotch.CPU)
+			defer x.MustDrop()
+			done <- ts.CurrentScope()
+		}()
+		if got := <-done; got != nil {
+			t.Errorf("Expected no scope in other goroutine, got %q\n", got.Name())
+		}
+		if s.Len() != 0 {
+			t.Errorf("Expected tensor of other goroutine not tracked, got %v tensors\n", s.Len())
+		}
+	})
+}
+
+func BenchmarkCurrentScope(b *testing.B) {
+	b.Run("NoScope", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ts.CurrentScope()
+		}
+	})
+
+	b.Run("InScope", func(b *testing.B) {
+		ts.WithScope(func(s *ts.Scope) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ts.CurrentScope()
+			}
+		})
+	})
+
+	// Scope active in another goroutine.
+	b.Run("OtherScope", func(b *testing.B) {
+		entered, exit := make(chan struct{}), make(chan struct{})
+		go ts.WithScope(func(s *ts.Scope) {
+			close(entered)
+			<-exit
+		})
+		<-entered
+		defer close(exit)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ts.CurrentScope()
+		}
+	})
+}
@@ -0,0 +1,321 @@
+package ts
+
+// Sparse tensor construction and operations.
+
+import (
+	"fmt"
+	"log"
+
+	lib "github.com/nullbull/gotch/libtch"
+)
+
+// Layout returns memory layout of the tensor.
+func (ts *Tensor) Layout() (Layout, error) {
+	layout := lib.AtLayout(ts.ctensor)
+	if err := TorchErr(); err != nil {
+		err = fmt.Errorf("Layout() failed: %w", err)
+		return Strided, err
+	}
+
+	return Layout(layout), nil
+}
+
+// MustLayout returns memory layout of the tensor. It panics if error.
+func (ts *Tensor) MustLayout() Layout {
+	layout, err := ts.Layout()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return layout
+}
+
+// SparseCOO creates a sparse tensor of COO layout and given shape from `indices`, an int64
+// tensor of shape [sparseDims, nnz], and `values` of shape [nnz, denseDims...]. The tensor
+// has the dtype and device of values.
+//
+// Duplicate indices are allowed and summed by `Coalesce`.
+func SparseCOO(indices, values *Tensor, shape []int64) (*Tensor, error) {
+	x, err := SparseCooTensorIndicesSize(indices, values, shape, values.DType(), values.MustDevice(), false)
+	if err != nil {
+		err = fmt.Errorf("SparseCOO() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustSparseCOO creates a sparse tensor of COO layout. It panics if error.
+func MustSparseCOO(indices, values *Tensor, shape []int64) *Tensor {
+	x, err := SparseCOO(indices, values, shape)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// SparseCOOFromSlices creates a sparse tensor of COO layout from indices, one slice of nnz
+// indices per sparse dimension, and nnz values.
+//
+// Example:
+//
+//	// [[0, 2, 0],
+//	//  [1, 0, 0]]
+//	x := ts.MustSparseCOOFromSlices([][]int64{{0, 1}, {1, 0}}, []float32{2, 1}, []int64{2, 3})
+func SparseCOOFromSlices[T Element](indices [][]int64, values []T, shape []int64) (*Tensor, error) {
+	nnz := len(values)
+	flat := make([]int64, 0, len(indices)*nnz)
+	for d, idx := range indices {
+		if len(idx) != nnz {
+			err := fmt.Errorf("SparseCOOFromSlices() failed: %d indices of dimension %d mismatched %d values", len(idx), d, nnz)
+			return nil, err
+		}
+		flat = append(flat, idx...)
+	}
+
+	indicesTs, err := FromSlice(flat, int64(len(indices)), int64(nnz))
+	if err != nil {
+		err = fmt.Errorf("SparseCOOFromSlices() failed: %w", err)
+		return nil, err
+	}
+	defer indicesTs.MustDrop()
+	valuesTs, err := FromSlice(values)
+	if err != nil {
+		err = fmt.Errorf("SparseCOOFromSlices() failed: %w", err)
+		return nil, err
+	}
+	defer valuesTs.MustDrop()
+
+	x, err := SparseCOO(indicesTs, valuesTs, shape)
+	if err != nil {
+		err = fmt.Errorf("SparseCOOFromSlices() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustSparseCOOFromSlices creates a sparse tensor of COO layout from slices. It panics if error.
+func MustSparseCOOFromSlices[T Element](indices [][]int64, values []T, shape []int64) *Tensor {
+	x, err := SparseCOOFromSlices(indices, values, shape)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// SparseCSR creates a sparse tensor of CSR layout and given shape [rows, cols] from
+// compressed row indices `crow` of shape [rows+1], column indices `col` of shape [nnz] and
+// `values` of shape [nnz]. The tensor has the dtype and device of values.
+func SparseCSR(crow, col, values *Tensor, shape []int64) (*Tensor, error) {
+	x, err := SparseCsrTensorCrowColValueSize(crow, col, values, shape, values.DType(), values.MustDevice())
+	if err != nil {
+		err = fmt.Errorf("SparseCSR() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustSparseCSR creates a sparse tensor of CSR layout. It panics if error.
+func MustSparseCSR(crow, col, values *Tensor, shape []int64) *Tensor {
+	x, err := SparseCSR(crow, col, values, shape)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// SparseCSRFromSlices creates a sparse tensor of CSR layout from compressed row indices,
+// column indices and values.
+//
+// Example:
+//
+//	// [[0, 2, 0],
+//	//  [1, 0, 0]]
+//	x := ts.MustSparseCSRFromSlices([]int64{0, 1, 2}, []int64{1, 0}, []float32{2, 1}, []int64{2, 3})
+func SparseCSRFromSlices[T Element](crow, col []int64, values []T, shape []int64) (*Tensor, error) {
+	tensors, err := slicesToTensors(crow, col, values)
+	if err != nil {
+		err = fmt.Errorf("SparseCSRFromSlices() failed: %w", err)
+		return nil, err
+	}
+	defer dropTensors(tensors)
+
+	x, err := SparseCSR(tensors[0], tensors[1], tensors[2], shape)
+	if err != nil {
+		err = fmt.Errorf("SparseCSRFromSlices() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustSparseCSRFromSlices creates a sparse tensor of CSR layout from slices. It panics if error.
+func MustSparseCSRFromSlices[T Element](crow, col []int64, values []T, shape []int64) *Tensor {
+	x, err := SparseCSRFromSlices(crow, col, values, shape)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// SparseCSC creates a sparse tensor of CSC layout and given shape [rows, cols] from
+// compressed column indices `ccol` of shape [cols+1], row indices `row` of shape [nnz] and
+// `values` of shape [nnz]. The tensor has the dtype and device of values.
+func SparseCSC(ccol, row, values *Tensor, shape []int64) (*Tensor, error) {
+	x, err := SparseCscTensorCcolRowValueSize(ccol, row, values, shape, values.DType(), values.MustDevice())
+	if err != nil {
+		err = fmt.Errorf("SparseCSC() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustSparseCSC creates a sparse tensor of CSC layout. It panics if error.
+func MustSparseCSC(ccol, row, values *Tensor, shape []int64) *Tensor {
+	x, err := SparseCSC(ccol, row, values, shape)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// SparseCSCFromSlices creates a sparse tensor of CSC layout from compressed column indices,
+// row indices and values.
+func SparseCSCFromSlices[T Element](ccol, row []int64, values []T, shape []int64) (*Tensor, error) {
+	tensors, err := slicesToTensors(ccol, row, values)
+	if err != nil {
+		err = fmt.Errorf("SparseCSCFromSlices() failed: %w", err)
+		return nil, err
+	}
+	defer dropTensors(tensors)
+
+	x, err := SparseCSC(tensors[0], tensors[1], tensors[2], shape)
+	if err != nil {
+		err = fmt.Errorf("SparseCSCFromSlices() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustSparseCSCFromSlices creates a sparse tensor of CSC layout from slices. It panics if error.
+func MustSparseCSCFromSlices[T Element](ccol, row []int64, values []T, shape []int64) *Tensor {
+	x, err := SparseCSCFromSlices(ccol, row, values, shape)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// slicesToTensors creates 1-D tensors of compressed indices, plain indices and values.
+func slicesToTensors[T Element](compressed, plain []int64, values []T) ([]*Tensor, error) {
+	compressedTs, err := FromSlice(compressed)
+	if err != nil {
+		return nil, err
+	}
+	plainTs, err := FromSlice(plain)
+	if err != nil {
+		compressedTs.MustDrop()
+		return nil, err
+	}
+	valuesTs, err := FromSlice(values)
+	if err != nil {
+		dropTensors([]*Tensor{compressedTs, plainTs})
+		return nil, err
+	}
+
+	return []*Tensor{compressedTs, plainTs, valuesTs}, nil
+}
+
+func dropTensors(tensors []*Tensor) {
+	for _, x := range tensors {
+		x.MustDrop()
+	}
+}
+
+// ToLayout converts the tensor to layout `Strided` (dense), `Sparse` (COO), `SparseCsr` or
+// `SparseCsc`. Block layouts are supported by `ToSparseBsr` and `ToSparseBsc`.
+//
+// If the tensor already has the layout, a shallow clone is returned, or the tensor itself
+// if del is true.
+func (ts *Tensor) ToLayout(layout Layout, del bool) (*Tensor, error) {
+	current, err := ts.Layout()
+	if err != nil {
+		err = fmt.Errorf("ToLayout() failed: %w", err)
+		return nil, err
+	}
+	if current == layout {
+		if del {
+			return ts, nil
+		}
+		return ts.ShallowClone()
+	}
+
+	var x *Tensor
+	switch layout {
+	case Strided:
+		x, err = ts.ToDense(ts.DType(), false, del)
+	case Sparse:
+		sparseDim := int64(ts.Dim())
+		if current != Strided {
+			sparseDim, err = ts.SparseDim(false)
+		}
+		if err == nil {
+			x, err = ts.ToSparseSparseDim(sparseDim, del)
+		}
+	case SparseCsr:
+		x, err = ts.ToSparseCsr(nil, del)
+	case SparseCsc:
+		x, err = ts.ToSparseCsc(nil, del)
+	default:
+		err = fmt.Errorf("unsupported layout %v", layout)
+	}
+	if err != nil {
+		err = fmt.Errorf("ToLayout() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustToLayout converts the tensor to a layout. It panics if error.
+func (ts *Tensor) MustToLayout(layout Layout, del bool) *Tensor {
+	x, err := ts.ToLayout(layout, del)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// SparseMm returns the matrix product of a sparse 2-D tensor of COO, CSR or CSC layout
+// and a dense or sparse 2-D tensor. As `torch.sparse.mm`, the result is dense if `other`
+// is dense and gradients flow to both inputs.
+func SparseMm(sparse, other *Tensor) (*Tensor, error) {
+	x, err := _SparseMm(sparse, other)
+	if err != nil {
+		err = fmt.Errorf("SparseMm() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustSparseMm returns the matrix product of a sparse and a dense or sparse tensor. It panics if error.
+func MustSparseMm(sparse, other *Tensor) *Tensor {
+	x, err := SparseMm(sparse, other)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
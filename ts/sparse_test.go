@@ -0,0 +1,104 @@
+package ts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch/ts"
+)
+
+// Dense matrix of test sparse tensors:
+//
+//	[[0, 2, 0],
+//	 [1, 0, 3]]
+var sparseDense = []float32{0, 2, 0, 1, 0, 3}
+
+func checkDense(t *testing.T, name string, x *ts.Tensor) {
+	t.Helper()
+	d := x.MustToLayout(ts.Strided, false)
+	if got, want := ts.MustToSlice[float32](d), sparseDense; !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: expected %v, got %v", name, want, got)
+	}
+}
+
+func TestSparseConstruct(t *testing.T) {
+	coo := ts.MustSparseCOOFromSlices([][]int64{{0, 1, 1}, {1, 0, 2}}, []float32{2, 1, 3}, []int64{2, 3})
+	if got, want := coo.MustLayout(), ts.Sparse; got != want {
+		t.Errorf("Expected layout %v, got %v", want, got)
+	}
+	if !coo.MustIsSparse() {
+		t.Errorf("Expected sparse tensor")
+	}
+	checkDense(t, "COO", coo)
+
+	csr := ts.MustSparseCSRFromSlices([]int64{0, 1, 3}, []int64{1, 0, 2}, []float32{2, 1, 3}, []int64{2, 3})
+	if got, want := csr.MustLayout(), ts.SparseCsr; got != want {
+		t.Errorf("Expected layout %v, got %v", want, got)
+	}
+	checkDense(t, "CSR", csr)
+
+	csc := ts.MustSparseCSCFromSlices([]int64{0, 1, 2, 3}, []int64{1, 0, 1}, []float32{1, 2, 3}, []int64{2, 3})
+	if got, want := csc.MustLayout(), ts.SparseCsc; got != want {
+		t.Errorf("Expected layout %v, got %v", want, got)
+	}
+	checkDense(t, "CSC", csc)
+
+	if _, err := ts.SparseCOOFromSlices([][]int64{{0, 1}, {1}}, []float32{2, 1}, []int64{2, 3}); err == nil {
+		t.Errorf("Expected error for mismatched indices")
+	}
+	if _, err := ts.SparseCSRFromSlices([]int64{0, 1, 3}, []int64{1, 0, 5}, []float32{2, 1, 3}, []int64{2, 3}); err == nil {
+		t.Errorf("Expected error for out of bounds column index")
+	}
+}
+
+func TestSparseLayoutConversion(t *testing.T) {
+	dense := ts.MustFromSlice(sparseDense, 2, 3)
+	for _, layout := range []ts.Layout{ts.Sparse, ts.SparseCsr, ts.SparseCsc} {
+		x := dense.MustToLayout(layout, false)
+		if got := x.MustLayout(); got != layout {
+			t.Errorf("Expected layout %v, got %v", layout, got)
+		}
+		checkDense(t, layout.String(), x)
+
+		// Between sparse layouts.
+		for _, to := range []ts.Layout{ts.Sparse, ts.SparseCsr, ts.SparseCsc} {
+			y := x.MustToLayout(to, false)
+			if got := y.MustLayout(); got != to {
+				t.Errorf("Expected layout %v, got %v", to, got)
+			}
+			checkDense(t, layout.String()+"->"+to.String(), y)
+		}
+	}
+
+	csr := dense.MustToLayout(ts.SparseCsr, false)
+	if got, want := ts.MustToSlice[int64](csr.MustCrowIndices(false)), []int64{0, 1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected crow indices %v, got %v", want, got)
+	}
+}
+
+func TestSparseCoalesce(t *testing.T) {
+	// Duplicate index (0, 1) summed on coalescing.
+	x := ts.MustSparseCOOFromSlices([][]int64{{0, 1, 0}, {1, 0, 1}}, []float32{2, 1, 3}, []int64{2, 3})
+	if x.MustIsCoalesced(false) {
+		t.Errorf("Expected uncoalesced tensor")
+	}
+	c := x.MustCoalesce(false)
+	if !c.MustIsCoalesced(false) {
+		t.Errorf("Expected coalesced tensor")
+	}
+	if got, want := ts.MustToSlice[float32](c.MustValues(false)), []float32{5, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected values %v, got %v", want, got)
+	}
+}
+
+func TestSparseMm(t *testing.T) {
+	dense := ts.MustFromSlice([]float32{1, 2, 3, 4, 5, 6}, 3, 2)
+	want := []float32{6, 8, 16, 20}
+	for _, layout := range []ts.Layout{ts.Sparse, ts.SparseCsr} {
+		a := ts.MustFromSlice(sparseDense, 2, 3).MustToLayout(layout, true)
+		y := ts.MustSparseMm(a, dense)
+		if got := ts.MustToSlice[float32](y); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: expected %v, got %v", layout, want, got)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"log"
 	"reflect"
 	"runtime"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -84,6 +85,9 @@ func newTensor(ctensor lib.Ctensor, nameOpt ...string) *Tensor {
 
 	x.calledFrom = "newTensor()"
 
+	trackNewTensor(x)
+	recordAlloc(x)
+
 	runtime.SetFinalizer(x, freeCTensor)
 
 	return x
@@ -107,6 +111,17 @@ func CheckCMemLeak() string {
 	msg += fmt.Sprintf("============================= C MEMORY CHECK RESULT ==================================\n")
 	msg += fmt.Sprintf("C memory allocated not been released: %v bytes\n", memUsed)
 	msg += fmt.Sprintf("Tensors not been released: %q\n", tensors)
+	byScope := CheckCMemLeakByScope()
+	scopeNames := make([]string, 0, len(byScope))
+	for scope := range byScope {
+		if scope != "" {
+			scopeNames = append(scopeNames, scope)
+		}
+	}
+	sort.Strings(scopeNames)
+	for _, scope := range scopeNames {
+		msg += fmt.Sprintf("  - created in scope %q: %q\n", scope, byScope[scope])
+	}
 	msg += fmt.Sprintf("======================================================================================\n")
 
 	return msg
@@ -166,6 +181,8 @@ func freeCTensor(ts *Tensor) error {
 	}
 
 	delete(ExistingTensors, ts.name)
+	delete(tensorScopes, ts.name)
+	delete(allocRecords, ts.name)
 
 	// IMPORTANT. make it nil so won't double free.
 	ts.ctensor = nil
@@ -417,10 +434,10 @@ func OfSlice(data interface{}, opts ...TensorOpt) (*Tensor, error) {
 		return nil, err
 	}
 
-	elementKind := reflect.TypeOf(data).Elem().Kind()
+	elementType := reflect.TypeOf(data).Elem()
 	dataLen := v.Len()
 
-	dtype, err := gotch.GoKind2DType(elementKind, gotch.HalfDTypePref(o.DType), gotch.WithQuantized(o.Quantized))
+	dtype, err := gotch.GoType2DType(elementType, gotch.HalfDTypePref(o.DType), gotch.WithQuantized(o.Quantized))
 	if err != nil {
 		return nil, err
 	}
@@ -508,15 +525,9 @@ func TensorFrom(data interface{}, opts ...TensorOpt) *Tensor {
 	return ts
 }
 
-// Print prints tensor values to console.
-//
-// NOTE: it is printed from C and will print ALL elements of tensor
-// with no truncation at all.
+// Print prints tensor values to stdout with global print options (see `SetPrintOptions`).
 func (ts *Tensor) Print() {
-	lib.AtPrint(ts.ctensor)
-	if err := TorchErr(); err != nil {
-		log.Fatal(err)
-	}
+	fmt.Println(ts.Sprint())
 }
 
 // NewTensorFromData creates tensor from given data and shape
@@ -811,7 +822,7 @@ func (ts *Tensor) ZeroGrad() {
 func (ts *Tensor) Backward() error {
 	lib.AtBackward(ts.ctensor, 0, 0)
 	if err := TorchErr(); err != nil {
-		return err
+		return anomalyErr(err)
 	}
 
 	return nil
@@ -862,7 +873,7 @@ func RunBackward(tensors []*Tensor, inputs []*Tensor, keepGraphB bool, createGra
 
 	lib.AtRunBackward(tensorsPtr, len(tensors), inputsPtr, len(inputs), outputsPtr[0], keepGraph, createGraph)
 	if err := TorchErr(); err != nil {
-		return nil, err
+		return nil, anomalyErr(err)
 	}
 
 	var oTensors []*Tensor
@@ -1255,30 +1266,86 @@ func MustGradSetEnabled(b bool) bool {
 	return state
 }
 
-// NoGrad runs a closure without keeping track of gradients.
-func NoGrad(fn func()) {
-	// Switch off Grad
-	MustGradSetEnabled(false)
+// IsGradEnabled returns whether GradMode is enabled on the current OS thread.
+//
+// NOTE. GradMode is thread-local in libtorch and goroutines can migrate between
+// OS threads. Use it inside a NoGrad, WithGradMode or InferenceMode scope for a reliable result.
+func IsGradEnabled() bool {
+	return lib.AtGradIsEnabled() == 1
+}
+
+// WithGradMode runs a closure with GradMode set to `enabled`.
+//
+// The goroutine is locked to its OS thread for the duration of the closure as
+// libtorch GradMode is thread-local. Previous state is restored afterward, even
+// if closure panics, so that scopes can be nested.
+func WithGradMode(enabled bool, fn func()) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	prev := MustGradSetEnabled(enabled)
+	defer MustGradSetEnabled(prev)
 
 	fn()
+}
+
+// NoGrad runs a closure without keeping track of gradients.
+//
+// See WithGradMode for thread safety.
+func NoGrad(fn func()) {
+	WithGradMode(false, fn)
+}
 
-	// Switch on Grad
-	MustGradSetEnabled(true)
+// EnableGrad runs a closure keeping track of gradients, e.g. inside a NoGrad scope.
+//
+// See WithGradMode for thread safety.
+func EnableGrad(fn func()) {
+	WithGradMode(true, fn)
 }
 
+// NoGrad1 runs a closure without keeping track of gradients and returns its result.
 func NoGrad1(fn func() interface{}) interface{} {
-	newTs := NewTensor()
-	newTs.Drop()
+	var retVal interface{}
+	NoGrad(func() {
+		retVal = fn()
+	})
 
-	// Switch off Grad
-	prev := MustGradSetEnabled(false)
+	return retVal
+}
 
-	retVal := fn()
+// InferenceMode runs a closure in libtorch inference mode. It is a stricter and faster
+// version of NoGrad which also disables view tracking and version counter bumps.
+//
+// The goroutine is locked to its OS thread for the duration of the closure and previous
+// mode is restored afterward, even if closure panics.
+//
+// NOTE. Tensors created in inference mode can not be used in autograd later.
+func InferenceMode(fn func()) {
+	WithInferenceMode(true, fn)
+}
 
-	// Switch on Grad
-	_ = MustGradSetEnabled(prev)
+// WithInferenceMode runs a closure with inference mode set to `enabled`.
+// See InferenceMode.
+func WithInferenceMode(enabled bool, fn func()) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
 
-	return retVal
+	var cenabled int
+	if enabled {
+		cenabled = 1
+	}
+	guard := lib.AtInferenceModeNew(cenabled)
+	if err := TorchErr(); err != nil {
+		log.Fatal(err)
+	}
+	defer lib.AtInferenceModeFree(guard)
+
+	fn()
+}
+
+// IsInferenceModeEnabled returns whether inference mode is enabled on the current OS thread.
+func IsInferenceModeEnabled() bool {
+	return lib.AtIsInferenceModeEnabled() == 1
 }
 
 // NoGradGuard is a RAII guard that prevents gradient tracking until deallocated.
@@ -1288,9 +1355,12 @@ func NoGrad1(fn func() interface{}) interface{} {
 // That way it is similar to a with torch.no_grad(): block in python.
 // Ref. https://discuss.pytorch.org/t/how-does-nogradguard-works-in-cpp/34960/2
 //
-// TODO: should we implement Go `mutex` here???
+// NOTE. The guard locks the goroutine to its OS thread until `Drop()` is called
+// which must be called from the same goroutine. Prefer NoGrad where possible.
 type NoGradGuard struct {
 	enabled bool
+	prev    bool
+	dropped bool
 }
 
 // Init NoGradGuard and disables gradient tracking
@@ -1301,13 +1371,19 @@ func NewNoGradGuard() *NoGradGuard {
 // Disables gradient tracking, this will be enabled back when the
 // returned value gets deallocated.
 func noGradGuardInit() *NoGradGuard {
-	return &NoGradGuard{enabled: MustGradSetEnabled(false)}
+	runtime.LockOSThread()
+	return &NoGradGuard{prev: MustGradSetEnabled(false)}
 }
 
-// Drop drops the NoGradGuard state.
+// Drop restores gradient tracking state before the guard was created.
 func (ngg *NoGradGuard) Drop() {
-	ngg.enabled = true
-	_ = MustGradSetEnabled(ngg.enabled)
+	if ngg.dropped {
+		return
+	}
+	ngg.dropped = true
+	ngg.enabled = ngg.prev
+	_ = MustGradSetEnabled(ngg.prev)
+	runtime.UnlockOSThread()
 }
 
 func (ngg *NoGradGuard) Enable() {
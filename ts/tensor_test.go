@@ -1,10 +1,13 @@
 package ts_test
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/half"
 	"github.com/nullbull/gotch/ts"
 )
 
@@ -132,6 +135,65 @@ func TestOfSlice(t *testing.T) {
 	}
 }
 
+func TestOfSliceComplexHalf(t *testing.T) {
+	c64 := []complex64{1 + 2i, -3.5i, 4}
+	c128 := []complex128{1 + 2i, -3.5i, 4}
+	f16 := []half.Float16{half.Fromfloat32(1.5), half.Fromfloat32(-0.25)}
+	bf16 := []half.BFloat16{half.BFloat16Fromfloat32(1.5), half.BFloat16Fromfloat32(-0.25)}
+
+	tests := []struct {
+		data  interface{}
+		dtype gotch.DType
+	}{
+		{c64, gotch.ComplexFloat},
+		{c128, gotch.ComplexDouble},
+		{f16, gotch.Half},
+		{bf16, gotch.BFloat16},
+	}
+	for _, tt := range tests {
+		x := ts.MustOfSlice(tt.data)
+		if got := x.DType(); got != tt.dtype {
+			t.Errorf("Expected dtype %v, got %v", tt.dtype, got)
+		}
+		if got := x.Vals(); !reflect.DeepEqual(got, tt.data) {
+			t.Errorf("Expected %v, got %v", tt.data, got)
+		}
+
+		// CopyData into a slice of same Go type.
+		dst := reflect.MakeSlice(reflect.TypeOf(tt.data), int(x.Numel()), int(x.Numel())).Interface()
+		x.MustCopyData(dst, x.Numel())
+		if !reflect.DeepEqual(dst, tt.data) {
+			t.Errorf("Expected %v, got %v", tt.data, dst)
+		}
+	}
+
+	// Iterate over complex and half tensors.
+	iter := ts.MustOfSlice(c128).MustIter(gotch.ComplexDouble)
+	var gotC []complex128
+	for item, ok := iter.Next(); ok; item, ok = iter.Next() {
+		gotC = append(gotC, item.(complex128))
+	}
+	if !reflect.DeepEqual(gotC, c128) {
+		t.Errorf("Expected %v, got %v", c128, gotC)
+	}
+	iter = ts.MustOfSlice(f16).MustIter(gotch.Half)
+	var gotH []half.Float16
+	for item, ok := iter.Next(); ok; item, ok = iter.Next() {
+		gotH = append(gotH, item.(half.Float16))
+	}
+	if !reflect.DeepEqual(gotH, f16) {
+		t.Errorf("Expected %v, got %v", f16, gotH)
+	}
+
+	// Half values are printed as floats.
+	if got := fmt.Sprintf("%.2f", ts.MustOfSlice(bf16)); !strings.Contains(got, "1.50") || !strings.Contains(got, "-0.25") {
+		t.Errorf("Expected formatted BFloat16 values, got %q", got)
+	}
+	if got := fmt.Sprintf("%v", ts.MustOfSlice(c64)); !strings.Contains(got, "1.0000+2.0000i") {
+		t.Errorf("Expected formatted complex values, got %q", got)
+	}
+}
+
 func TestCudaCurrentDevice(t *testing.T) {
 	cudaIdx, err := ts.CudaCurrentDevice()
 	if err != nil {
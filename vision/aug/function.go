@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
-	"time"
 
 	"github.com/nullbull/gotch"
 	"github.com/nullbull/gotch/ts"
@@ -1096,10 +1094,9 @@ func genAffineGrid(theta *ts.Tensor, w, h, ow, oh int64) *ts.Tensor {
 
 // randPvalue generates a random propability value [0, 1]
 func randPvalue() float64 {
-	rand.Seed(time.Now().UnixNano())
 	var min, max float64 = 0.0, 1.0
 
-	r := min + rand.Float64()*(max-min)
+	r := min + gotch.RandFloat64()*(max-min)
 	return r
 }
 
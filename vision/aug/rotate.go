@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
-	"time"
 
 	"github.com/nullbull/gotch"
 	"github.com/nullbull/gotch/ts"
@@ -24,8 +22,7 @@ func RandomRotate(img *ts.Tensor, min, max float64) (*ts.Tensor, error) {
 	}
 	// device := img.MustDevice()
 	dtype := gotch.Double
-	rand.Seed(time.Now().UnixNano())
-	angle := min + rand.Float64()*(max-min)
+	angle := min + gotch.RandFloat64()*(max-min)
 
 	theta := float64(angle) * (math.Pi / 180)
 	input := img.MustUnsqueeze(0, false).MustTotype(dtype, true)
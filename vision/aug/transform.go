@@ -1,9 +1,7 @@
 package aug
 
 import (
-	"math/rand"
-	"time"
-
+	"github.com/nullbull/gotch"
 	"github.com/nullbull/gotch/nn"
 	"github.com/nullbull/gotch/ts"
 )
@@ -199,8 +197,7 @@ func OneOf(pvalue float64, tfOpts ...Option) Option {
 		return nil
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	idx := rand.Intn(tfsNum)
+	idx := gotch.RandIntn(tfsNum)
 
 	return tfOpts[idx]
 }
@@ -5,9 +5,8 @@ package vision
 import (
 	// "fmt"
 	"log"
-	"math/rand"
-	"time"
 
+	"github.com/nullbull/gotch"
 	"github.com/nullbull/gotch/ts"
 )
 
@@ -54,7 +53,7 @@ func RandomFlip(t *ts.Tensor) *ts.Tensor {
 		tView := t.Idx(ts.NewSelect(int64(batchIdx)))
 
 		var src *ts.Tensor
-		if rand.Float64() == 1.0 {
+		if gotch.RandFloat64() == 1.0 {
 			src = tView
 		} else {
 			src = tView.MustFlip([]int64{2}, false)
@@ -92,9 +91,8 @@ func RandomCrop(t *ts.Tensor, pad int64) *ts.Tensor {
 		idx := ts.NewSelect(int64(bidx))
 		outputView := output.Idx(idx)
 
-		rand.Seed(time.Now().UnixNano())
-		startW := rand.Intn(int(2 * pad))
-		startH := rand.Intn(int(2 * pad))
+		startW := gotch.RandIntn(int(2 * pad))
+		startH := gotch.RandIntn(int(2 * pad))
 
 		var srcIdx []ts.TensorIndexer
 		nIdx := ts.NewSelect(int64(bidx))
@@ -137,9 +135,8 @@ func RandomCutout(t *ts.Tensor, sz int64) *ts.Tensor {
 
 	for bidx := 0; bidx < int(size[0]); bidx++ {
 
-		rand.Seed(time.Now().UnixNano())
-		startH := rand.Intn(int(size[2] - sz + 1))
-		startW := rand.Intn(int(size[3] - sz + 1))
+		startH := gotch.RandIntn(int(size[2] - sz + 1))
+		startW := gotch.RandIntn(int(size[3] - sz + 1))
 
 		var srcIdx []ts.TensorIndexer
 		nIdx := ts.NewSelect(int64(bidx))
@@ -45,6 +45,22 @@ func (l *denseLayer) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
 	return res
 }
 
+// Name implements nn.ModuleInfo for denseLayer.
+func (l *denseLayer) Name() string { return "DenseLayer" }
+
+// Children implements nn.ModuleInfo for denseLayer.
+func (l *denseLayer) Children() []nn.NamedModule {
+	return []nn.NamedModule{
+		{Name: "norm1", Module: l.Bn1},
+		{Name: "conv1", Module: l.Conv1},
+		{Name: "norm2", Module: l.Bn2},
+		{Name: "conv2", Module: l.Conv2},
+	}
+}
+
+// Parameters implements nn.ModuleInfo for denseLayer.
+func (l *denseLayer) Parameters() []ts.NamedTensor { return nil }
+
 func newDenseLayer(p *nn.Path, cIn, bnSize, growth int64) ts.ModuleT {
 	cInter := bnSize * growth
 	bn1 := nn.BatchNorm2D(p.Sub("norm1"), cIn, nn.DefaultBatchNormConfig())
@@ -78,7 +78,7 @@ func enConv2d(vs *nn.Path, i, o, k int64, c *nn.Conv2DConfig, train bool) ts.Mod
 	conv2d := nn.NewConv2D(vs, i, o, k, c)
 	s := c.Stride
 
-	return nn.NewFunc(func(xs *ts.Tensor) *ts.Tensor {
+	return nn.NewNamedFunc("Conv2DSame", func(xs *ts.Tensor) *ts.Tensor {
 		size := xs.MustSize()
 		ih := size[2]
 		iw := size[3]
@@ -104,7 +104,7 @@ func enConv2d(vs *nn.Path, i, o, k int64, c *nn.Conv2DConfig, train bool) ts.Mod
 			res = xs.ApplyT(conv2d, train)
 			return res
 		}
-	})
+	}, nn.NamedModule{Name: "conv", Module: conv2d})
 }
 
 func newParams(width, depth float64, res int64, dropout float64) *params {
@@ -200,7 +200,23 @@ func block(p *nn.Path, args BlockArgs) ts.ModuleT {
 
 	projectBn := nn.BatchNorm2D(p.Sub("_bn2"), finalOup, bn2d)
 
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
+	var children []nn.NamedModule
+	if args.ExpandRatio != 1 {
+		children = append(children, nn.NamedModule{Name: "_expansion", Module: expansion})
+	}
+	children = append(children,
+		nn.NamedModule{Name: "_depthwise_conv", Module: depthwiseConv},
+		nn.NamedModule{Name: "_bn1", Module: depthwiseBn},
+	)
+	if se != nil {
+		children = append(children, nn.NamedModule{Name: "_se", Module: se})
+	}
+	children = append(children,
+		nn.NamedModule{Name: "_project_conv", Module: projectConv},
+		nn.NamedModule{Name: "_bn2", Module: projectBn},
+	)
+
+	return nn.NewNamedFuncT("MBConvBlock", func(xs *ts.Tensor, train bool) *ts.Tensor {
 		var ys *ts.Tensor
 		if args.ExpandRatio == 1 {
 			ys = xs.MustShallowClone()
@@ -239,7 +255,7 @@ func block(p *nn.Path, args BlockArgs) ts.ModuleT {
 		} else {
 			return ys6
 		}
-	})
+	}, children...)
 }
 
 func efficientnet(p *nn.Path, params *params, nclasses int64) ts.ModuleT {
@@ -297,7 +313,7 @@ func efficientnet(p *nn.Path, params *params, nclasses int64) ts.ModuleT {
 
 	classifier.Add(nn.NewLinear(p.Sub("_fc"), outC, nclasses, nn.DefaultLinearConfig()))
 
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
+	return nn.NewNamedFuncT("EfficientNet", func(xs *ts.Tensor, train bool) *ts.Tensor {
 		tmp1 := xs.ApplyT(convStem, false)
 		tmp2 := tmp1.ApplyT(bn0, train)
 		tmp1.MustDrop()
@@ -319,7 +335,14 @@ func efficientnet(p *nn.Path, params *params, nclasses int64) ts.ModuleT {
 		res := tmp10.ApplyT(classifier, train)
 		tmp10.MustDrop()
 		return res
-	})
+	},
+		nn.NamedModule{Name: "_conv_stem", Module: convStem},
+		nn.NamedModule{Name: "_bn0", Module: bn0},
+		nn.NamedModule{Name: "_blocks", Module: blocks},
+		nn.NamedModule{Name: "_conv_head", Module: convHead},
+		nn.NamedModule{Name: "_bn1", Module: bn1},
+		nn.NamedModule{Name: "classifier", Module: classifier},
+	)
 
 }
 
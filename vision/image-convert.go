@@ -0,0 +1,254 @@
+package vision
+
+// Conversions between tensors and Go `image.Image` values.
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+)
+
+// ImageOpts holds options of FromImage.
+type ImageOpts struct {
+	DType gotch.DType // dtype of tensor. Uint8 keeps values in [0, 255], float dtypes are scaled to [0, 1]. Default=Uint8
+	Alpha bool        // whether to keep alpha channel of color images. Default=false
+}
+
+type ImageOpt func(*ImageOpts)
+
+func defaultImageOpts() *ImageOpts {
+	return &ImageOpts{
+		DType: gotch.Uint8,
+		Alpha: false,
+	}
+}
+
+func WithImageDType(v gotch.DType) ImageOpt {
+	return func(o *ImageOpts) {
+		o.DType = v
+	}
+}
+
+func WithImageAlpha(v bool) ImageOpt {
+	return func(o *ImageOpts) {
+		o.Alpha = v
+	}
+}
+
+func isGrayImage(img image.Image) bool {
+	switch img.ColorModel() {
+	case color.GrayModel, color.Gray16Model:
+		return true
+	}
+
+	return false
+}
+
+// FromImage converts an image to a tensor of shape [channel, height, width] without going
+// through files. Gray images have 1 channel, color images 3 (RGB) or 4 (RGBA) channels with
+// non-premultiplied values.
+//
+// *image.Gray, *image.RGBA, *image.NRGBA and *image.YCbCr are converted directly, other
+// images via their color model.
+func FromImage(img image.Image, opts ...ImageOpt) (*ts.Tensor, error) {
+	o := defaultImageOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	c := 3
+	switch {
+	case isGrayImage(img):
+		c = 1
+	case o.Alpha:
+		c = 4
+	}
+
+	// Values are written in CHW layout, `plane` values per channel.
+	plane := w * h
+	data := make([]uint8, c*plane)
+	setRGBA := func(i int, r, g, b, a uint8) {
+		data[i], data[plane+i], data[2*plane+i] = r, g, b
+		if c == 4 {
+			data[3*plane+i] = a
+		}
+	}
+
+	switch img := img.(type) {
+	case *image.Gray:
+		for y := 0; y < h; y++ {
+			off := img.PixOffset(b.Min.X, b.Min.Y+y)
+			copy(data[y*w:(y+1)*w], img.Pix[off:off+w])
+		}
+	case *image.NRGBA:
+		for y := 0; y < h; y++ {
+			off := img.PixOffset(b.Min.X, b.Min.Y+y)
+			for x := 0; x < w; x++ {
+				p := img.Pix[off+4*x : off+4*x+4]
+				setRGBA(y*w+x, p[0], p[1], p[2], p[3])
+			}
+		}
+	case *image.RGBA:
+		for y := 0; y < h; y++ {
+			off := img.PixOffset(b.Min.X, b.Min.Y+y)
+			for x := 0; x < w; x++ {
+				p := img.Pix[off+4*x : off+4*x+4]
+				if a := p[3]; a != 0xff && a != 0 {
+					n := color.NRGBAModel.Convert(color.RGBA{p[0], p[1], p[2], a}).(color.NRGBA)
+					setRGBA(y*w+x, n.R, n.G, n.B, n.A)
+					continue
+				}
+				setRGBA(y*w+x, p[0], p[1], p[2], p[3])
+			}
+		}
+	case *image.YCbCr:
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				yi := img.YOffset(b.Min.X+x, b.Min.Y+y)
+				ci := img.COffset(b.Min.X+x, b.Min.Y+y)
+				r, g, bl := color.YCbCrToRGB(img.Y[yi], img.Cb[ci], img.Cr[ci])
+				setRGBA(y*w+x, r, g, bl, 0xff)
+			}
+		}
+	default:
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				px := img.At(b.Min.X+x, b.Min.Y+y)
+				if c == 1 {
+					data[y*w+x] = color.GrayModel.Convert(px).(color.Gray).Y
+					continue
+				}
+				n := color.NRGBAModel.Convert(px).(color.NRGBA)
+				setRGBA(y*w+x, n.R, n.G, n.B, n.A)
+			}
+		}
+	}
+
+	x, err := ts.FromSlice(data, int64(c), int64(h), int64(w))
+	if err == nil && o.DType != gotch.Uint8 {
+		x, err = x.Totype(o.DType, true)
+		if err == nil && gotch.IsFloatDType(o.DType) {
+			x, err = x.DivScalar(ts.FloatScalar(255), true)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("FromImage() failed: %w", err)
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// MustFromImage converts an image to a tensor of shape [channel, height, width]. It panics if error.
+func MustFromImage(img image.Image, opts ...ImageOpt) *ts.Tensor {
+	x, err := FromImage(img, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return x
+}
+
+// ToImage converts a tensor of shape [channel, height, width] or [1, channel, height, width]
+// to an image: *image.Gray for 1 channel, *image.RGBA (opaque) for 3 channels and
+// *image.NRGBA for 4 channels.
+//
+// Values of float tensors are expected in [0, 1], other tensors in [0, 255]. Values out of
+// range are clamped.
+func ToImage(x *ts.Tensor) (image.Image, error) {
+	shape, err := x.Size()
+	if err != nil {
+		err = fmt.Errorf("ToImage() failed: %w", err)
+		return nil, err
+	}
+	if len(shape) == 4 && shape[0] == 1 {
+		shape = shape[1:]
+	}
+	if len(shape) != 3 {
+		err = fmt.Errorf("ToImage() failed: unexpected shape %v for image tensor", x.MustSize())
+		return nil, err
+	}
+	c, h, w := int(shape[0]), int(shape[1]), int(shape[2])
+	if c != 1 && c != 3 && c != 4 {
+		err = fmt.Errorf("ToImage() failed: unsupported number of channels %d", c)
+		return nil, err
+	}
+
+	data, err := imageBytes(x)
+	if err != nil {
+		err = fmt.Errorf("ToImage() failed: %w", err)
+		return nil, err
+	}
+
+	plane := w * h
+	if c == 1 {
+		img := image.NewGray(image.Rect(0, 0, w, h))
+		copy(img.Pix, data)
+		return img, nil
+	}
+
+	rect := image.Rect(0, 0, w, h)
+	var pix []uint8
+	var img image.Image
+	if c == 3 {
+		rgba := image.NewRGBA(rect)
+		pix, img = rgba.Pix, rgba
+	} else {
+		nrgba := image.NewNRGBA(rect)
+		pix, img = nrgba.Pix, nrgba
+	}
+	for i := 0; i < plane; i++ {
+		pix[4*i], pix[4*i+1], pix[4*i+2] = data[i], data[plane+i], data[2*plane+i]
+		pix[4*i+3] = 0xff
+		if c == 4 {
+			pix[4*i+3] = data[3*plane+i]
+		}
+	}
+
+	return img, nil
+}
+
+// MustToImage converts a tensor of shape [channel, height, width] to an image. It panics if error.
+func MustToImage(x *ts.Tensor) image.Image {
+	img, err := ToImage(x)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return img
+}
+
+// imageBytes returns tensor values as bytes in [0, 255], scaling float values by 255.
+func imageBytes(x *ts.Tensor) ([]uint8, error) {
+	if x.DType() == gotch.Uint8 {
+		return ts.ToSlice[uint8](x)
+	}
+
+	var (
+		y   *ts.Tensor
+		err error
+	)
+	if gotch.IsFloatDType(x.DType()) {
+		y, err = x.MulScalar(ts.FloatScalar(255), false)
+		if err == nil {
+			y, err = y.Round(true)
+		}
+	} else {
+		y, err = x.Totype(gotch.Int64, false)
+	}
+	if err == nil {
+		y, err = y.Clamp(ts.FloatScalar(0), ts.FloatScalar(255), true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer y.MustDrop()
+
+	return ts.ToSlice[uint8](y)
+}
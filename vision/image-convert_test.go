@@ -0,0 +1,147 @@
+package vision_test
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/nullbull/gotch"
+	"github.com/nullbull/gotch/ts"
+	"github.com/nullbull/gotch/vision"
+)
+
+// chw returns expected tensor values of an image in CHW layout from its pixels
+// converted to non-premultiplied colors.
+func chw(img image.Image, c int) []uint8 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	plane := w * h
+	data := make([]uint8, c*plane)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			px := img.At(b.Min.X+x, b.Min.Y+y)
+			if c == 1 {
+				data[i] = color.GrayModel.Convert(px).(color.Gray).Y
+				continue
+			}
+			if ycc, ok := px.(color.YCbCr); ok {
+				// NOTE. color.YCbCr.RGBA() may differ slightly from YCbCrToRGB.
+				data[i], data[plane+i], data[2*plane+i] = color.YCbCrToRGB(ycc.Y, ycc.Cb, ycc.Cr)
+				continue
+			}
+			n := color.NRGBAModel.Convert(px).(color.NRGBA)
+			data[i], data[plane+i], data[2*plane+i] = n.R, n.G, n.B
+			if c == 4 {
+				data[3*plane+i] = n.A
+			}
+		}
+	}
+	return data
+}
+
+func checkFromImage(t *testing.T, name string, img image.Image, c int, opts ...vision.ImageOpt) {
+	t.Helper()
+	x := vision.MustFromImage(img, opts...)
+	b := img.Bounds()
+	if got, want := x.MustSize(), []int64{int64(c), int64(b.Dy()), int64(b.Dx())}; !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: expected shape %v, got %v", name, want, got)
+	}
+	if got, want := ts.MustToSlice[uint8](x), chw(img, c); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: expected %v, got %v", name, want, got)
+	}
+}
+
+func TestFromImage(t *testing.T) {
+	rect := image.Rect(0, 0, 3, 2)
+
+	gray := image.NewGray(rect)
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i * 40)
+	}
+	checkFromImage(t, "Gray", gray, 1)
+
+	// Partially transparent pixels are un-premultiplied.
+	rgba := image.NewRGBA(rect)
+	for i := 0; i < len(rgba.Pix); i += 4 {
+		rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2], rgba.Pix[i+3] = uint8(i*4), uint8(i*2), 0, 0xff
+	}
+	rgba.SetRGBA(1, 0, color.RGBA{100, 50, 0, 128})
+	checkFromImage(t, "RGBA", rgba, 3)
+	checkFromImage(t, "RGBA alpha", rgba, 4, vision.WithImageAlpha(true))
+	x := vision.MustFromImage(rgba, vision.WithImageAlpha(true))
+	want := color.NRGBAModel.Convert(color.RGBA{100, 50, 0, 128}).(color.NRGBA)
+	if got := ts.MustToSlice[uint8](x); got[1] != want.R || got[7] != want.G || got[19] != want.A {
+		t.Errorf("Expected un-premultiplied %v, got %v", want, got)
+	}
+
+	nrgba := image.NewNRGBA(rect)
+	for i := range nrgba.Pix {
+		nrgba.Pix[i] = uint8(i * 10)
+	}
+	checkFromImage(t, "NRGBA", nrgba, 4, vision.WithImageAlpha(true))
+
+	// Chroma subsampled by 2 in both directions.
+	ycbcr := image.NewYCbCr(image.Rect(0, 0, 4, 4), image.YCbCrSubsampleRatio420)
+	for i := range ycbcr.Y {
+		ycbcr.Y[i] = uint8(i * 15)
+	}
+	for i := range ycbcr.Cb {
+		ycbcr.Cb[i], ycbcr.Cr[i] = uint8(64*i), uint8(255-64*i)
+	}
+	checkFromImage(t, "YCbCr", ycbcr, 3)
+
+	// Sub-images with non-zero origin.
+	checkFromImage(t, "Gray sub-image", gray.SubImage(image.Rect(1, 1, 3, 2)), 1)
+	checkFromImage(t, "RGBA sub-image", rgba.SubImage(image.Rect(1, 0, 3, 2)), 3)
+	checkFromImage(t, "YCbCr sub-image", ycbcr.SubImage(image.Rect(1, 1, 4, 3)), 3)
+
+	// Other images via color model.
+	paletted := image.NewPaletted(rect, color.Palette{color.Black, color.White})
+	paletted.SetColorIndex(2, 1, 1)
+	checkFromImage(t, "Paletted", paletted, 3)
+}
+
+func TestToImage(t *testing.T) {
+	rect := image.Rect(0, 0, 3, 2)
+	nrgba := image.NewNRGBA(rect)
+	for i := range nrgba.Pix {
+		nrgba.Pix[i] = uint8(i * 10)
+	}
+	gray := image.NewGray(rect)
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i * 40)
+	}
+
+	for _, dtype := range []gotch.DType{gotch.Uint8, gotch.Float} {
+		x := vision.MustFromImage(nrgba, vision.WithImageDType(dtype), vision.WithImageAlpha(true))
+		if got := x.DType(); got != dtype {
+			t.Errorf("Expected dtype %v, got %v", dtype, got)
+		}
+		img := vision.MustToImage(x)
+		if _, ok := img.(*image.NRGBA); !ok {
+			t.Errorf("%v: expected *image.NRGBA, got %T", dtype, img)
+		}
+		if got, want := chw(img, 4), chw(nrgba, 4); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: expected %v, got %v", dtype, want, got)
+		}
+
+		x = vision.MustFromImage(gray, vision.WithImageDType(dtype))
+		img = vision.MustToImage(x)
+		if got, ok := img.(*image.Gray); !ok || !reflect.DeepEqual(got.Pix, gray.Pix) {
+			t.Errorf("%v: expected gray image %v, got %v", dtype, gray.Pix, img)
+		}
+	}
+
+	// Out of range values are clamped.
+	x := ts.MustFromSlice([]float32{-1, 0.5, 2}, 1, 1, 3)
+	img := vision.MustToImage(x).(*image.Gray)
+	if got, want := img.Pix, []uint8{0, 128, 255}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := vision.ToImage(ts.MustFromSlice([]uint8{0, 1}, 2, 1, 1)); err == nil {
+		t.Errorf("Expected error for 2 channels")
+	}
+}
@@ -4,6 +4,7 @@ package vision
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math"
@@ -53,17 +54,64 @@ func Load(path string) (*ts.Tensor, error) {
 // The tensor input should be of kind UInt8 with values ranging from
 // 0 to 255.
 func Save(tensor *ts.Tensor, path string) error {
-	t, err := tensor.Totype(gotch.Uint8, false) // false to keep the input tensor
+	tsHWC, err := imageHWC(tensor, "Save")
 	if err != nil {
-		err = fmt.Errorf("Save - Tensor.Totype() error: %v\n", err)
 		return err
 	}
 
-	shape, err := t.Size()
+	if err = ts.SaveHwc(tsHWC, path); err != nil {
+		return err
+	}
+
+	tsHWC.MustDrop()
+	return nil
+}
+
+// Decode reads and decodes an image from r, e.g. bytes of an HTTP request body, without
+// going through a file. JPEG images are rotated according to their EXIF orientation.
+//
+// On success returns a tensor of shape [channel, height, width].
+func Decode(r io.Reader) (*ts.Tensor, error) {
+	tensor, err := ts.DecodeImage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decodedTs := hwcToCHW(tensor)
+	tensor.MustDrop()
+
+	return decodedTs, nil
+}
+
+// Encode encodes an image and writes it to w.
+//
+// This expects as input a tensor of shape [channel, height, width] as Save.
+// Supported formats are jpg, png, tga, and bmp. Quality in [1, 100] is only
+// used by jpg.
+func Encode(tensor *ts.Tensor, w io.Writer, format string, quality int) error {
+	tsHWC, err := imageHWC(tensor, "Encode")
 	if err != nil {
-		err = fmt.Errorf("Save - Tensor.Size() error: %v\n", err)
 		return err
 	}
+	defer tsHWC.MustDrop()
+
+	return ts.EncodeImage(tsHWC, w, format, quality)
+}
+
+// imageHWC converts an image tensor of shape [channel, height, width] or
+// [1, channel, height, width] to a uint8 CPU tensor of shape [height, width, channel].
+func imageHWC(tensor *ts.Tensor, caller string) (*ts.Tensor, error) {
+	t, err := tensor.Totype(gotch.Uint8, false) // false to keep the input tensor
+	if err != nil {
+		err = fmt.Errorf("%v - Tensor.Totype() error: %v\n", caller, err)
+		return nil, err
+	}
+
+	shape, err := t.Size()
+	if err != nil {
+		err = fmt.Errorf("%v - Tensor.Size() error: %v\n", caller, err)
+		return nil, err
+	}
 
 	var tsCHW, tsHWC *ts.Tensor
 	switch {
@@ -77,16 +125,12 @@ func Save(tensor *ts.Tensor, path string) error {
 		tsHWC = chwToHWC(chwTs)
 		chwTs.MustDrop()
 	default:
+		t.MustDrop()
 		err = fmt.Errorf("Unexpected size (%v) for image tensor.\n", len(shape))
-		return err
-	}
-
-	if err = ts.SaveHwc(tsHWC, path); err != nil {
-		return err
+		return nil, err
 	}
 
-	tsHWC.MustDrop()
-	return nil
+	return tsHWC, nil
 }
 
 // Resize resizes an image.
@@ -2,6 +2,7 @@ package vision
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 
@@ -124,6 +125,23 @@ func (in *ImageNet) LoadImage(path string) (*ts.Tensor, error) {
 	return loadedTs, nil
 }
 
+// DecodeImage reads and decodes an image from r and applies the ImageNet normalization.
+func (in *ImageNet) DecodeImage(r io.Reader) (*ts.Tensor, error) {
+	tensor, err := Decode(r)
+	if err != nil {
+		err = fmt.Errorf("ImageNet - DecodeImage method call: %v", err)
+		return nil, err
+	}
+
+	decodedTs, err := in.Normalize(tensor)
+	if err != nil {
+		return nil, err
+	}
+
+	tensor.MustDrop()
+	return decodedTs, nil
+}
+
 // LoadImageAndResize loads an image from a file and resize it to the specified width and height.
 //
 // NOTE: This will apply the ImageNet normalization.
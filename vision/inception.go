@@ -56,167 +56,143 @@ func inMaxPool2D(xs *ts.Tensor, ksize, stride int64) *ts.Tensor {
 	return xs.MustMaxPool2d([]int64{ksize, ksize}, []int64{stride, stride}, []int64{0, 0}, []int64{1, 1}, false, false)
 }
 
-func inceptionA(p *nn.Path, cIn, cPool int64) ts.ModuleT {
-	b1 := convBn(p.Sub("branch1x1"), cIn, 64, 1, 0, 1)
-	b21 := convBn(p.Sub("branch5x5_1"), cIn, 48, 1, 0, 1)
-	b22 := convBn(p.Sub("branch5x5_2"), 48, 64, 5, 2, 1)
-	b31 := convBn(p.Sub("branch3x3dbl_1"), cIn, 64, 1, 0, 1)
-	b32 := convBn(p.Sub("branch3x3dbl_2"), 64, 96, 3, 1, 1)
-	b33 := convBn(p.Sub("branch3x3dbl_3"), 96, 96, 3, 1, 1)
-	bpool := convBn(p.Sub("branch_pool"), cIn, cPool, 1, 0, 1)
-
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
-		b1Ts := xs.ApplyT(b1, train)
-
-		b2Tmp := xs.ApplyT(b21, train)
-		b2Ts := b2Tmp.ApplyT(b22, train)
-		b2Tmp.MustDrop()
-
-		b3Tmp1 := xs.ApplyT(b31, train)
-		b3Tmp2 := b3Tmp1.ApplyT(b32, train)
-		b3Tmp1.MustDrop()
-		b3Ts := b3Tmp2.ApplyT(b33, train)
-		b3Tmp2.MustDrop()
+// inBranch chains convBn layers of a branch named by their variable store paths.
+func inBranch(layers ...nn.NamedModule) ts.ModuleT {
+	seq := nn.SeqT()
+	for _, l := range layers {
+		seq.AddNamed(l.Name, l.Module.(ts.ModuleT))
+	}
 
-		bpoolTmp := xs.MustAvgPool2d([]int64{3, 3}, []int64{1, 1}, []int64{1, 1}, false, true, []int64{9}, false)
-		bpoolTs := bpoolTmp.ApplyT(bpool, train)
+	return seq
+}
 
-		res := ts.MustCat([]*ts.Tensor{b1Ts, b2Ts, b3Ts, bpoolTs}, 1)
+func inAvgPool() nn.Func {
+	return nn.NewNamedFunc("AvgPool2D", func(xs *ts.Tensor) *ts.Tensor {
+		return xs.MustAvgPool2d([]int64{3, 3}, []int64{1, 1}, []int64{1, 1}, false, true, []int64{9}, false)
+	})
+}
 
-		return res
+func inMaxPool() nn.Func {
+	return nn.NewNamedFunc("MaxPool2D", func(xs *ts.Tensor) *ts.Tensor {
+		return inMaxPool2D(xs, 3, 2)
 	})
 }
 
-func inceptionB(p *nn.Path, cIn int64) ts.ModuleT {
-	b1 := convBn(p.Sub("branch3x3"), cIn, 384, 3, 0, 2)
-	b21 := convBn(p.Sub("branch3x3dbl_1"), cIn, 64, 1, 0, 1)
-	b22 := convBn(p.Sub("branch3x3dbl_2"), 64, 96, 3, 1, 1)
-	b23 := convBn(p.Sub("branch3x3dbl_3"), 96, 96, 3, 0, 2)
+func inceptionA(p *nn.Path, cIn, cPool int64) ts.ModuleT {
+	c := nn.NewConcat(1)
 
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
-		b1Ts := xs.ApplyT(b1, train)
+	c.AddNamed("branch1x1", convBn(p.Sub("branch1x1"), cIn, 64, 1, 0, 1))
 
-		b2Tmp1 := xs.ApplyT(b21, train)
-		b2Tmp2 := b2Tmp1.ApplyT(b22, train)
-		b2Tmp1.MustDrop()
-		b2Ts := b2Tmp2.ApplyT(b23, train)
-		b2Tmp2.MustDrop()
+	c.AddNamed("branch5x5", inBranch(
+		nn.NamedModule{Name: "branch5x5_1", Module: convBn(p.Sub("branch5x5_1"), cIn, 48, 1, 0, 1)},
+		nn.NamedModule{Name: "branch5x5_2", Module: convBn(p.Sub("branch5x5_2"), 48, 64, 5, 2, 1)},
+	))
 
-		bpoolTs := inMaxPool2D(xs, 3, 2)
+	c.AddNamed("branch3x3dbl", inBranch(
+		nn.NamedModule{Name: "branch3x3dbl_1", Module: convBn(p.Sub("branch3x3dbl_1"), cIn, 64, 1, 0, 1)},
+		nn.NamedModule{Name: "branch3x3dbl_2", Module: convBn(p.Sub("branch3x3dbl_2"), 64, 96, 3, 1, 1)},
+		nn.NamedModule{Name: "branch3x3dbl_3", Module: convBn(p.Sub("branch3x3dbl_3"), 96, 96, 3, 1, 1)},
+	))
 
-		res := ts.MustCat([]*ts.Tensor{b1Ts, b2Ts, bpoolTs}, 1)
+	c.AddNamed("pool", inBranch(
+		nn.NamedModule{Name: "avg_pool", Module: inAvgPool()},
+		nn.NamedModule{Name: "branch_pool", Module: convBn(p.Sub("branch_pool"), cIn, cPool, 1, 0, 1)},
+	))
 
-		return res
-	})
+	return c
 }
 
-func inceptionC(p *nn.Path, cIn int64, c7 int64) ts.ModuleT {
-
-	b1 := convBn(p.Sub("branch1x1"), cIn, 192, 1, 0, 1)
-
-	b21 := convBn(p.Sub("branch7x7_1"), cIn, c7, 1, 0, 1)
-	b22 := convBn2(p.Sub("branch7x7_2"), c7, c7, []int64{1, 7}, []int64{0, 3})
-	b23 := convBn2(p.Sub("branch7x7_3"), c7, 192, []int64{7, 1}, []int64{3, 0})
-
-	b31 := convBn(p.Sub("branch7x7dbl_1"), cIn, c7, 1, 0, 1)
-	b32 := convBn2(p.Sub("branch7x7dbl_2"), c7, c7, []int64{7, 1}, []int64{3, 0})
-	b33 := convBn2(p.Sub("branch7x7dbl_3"), c7, c7, []int64{1, 7}, []int64{0, 3})
-	b34 := convBn2(p.Sub("branch7x7dbl_4"), c7, c7, []int64{7, 1}, []int64{3, 0})
-	b35 := convBn2(p.Sub("branch7x7dbl_5"), c7, 192, []int64{1, 7}, []int64{0, 3})
-
-	bpool := convBn(p.Sub("branch_pool"), cIn, 192, 1, 0, 1)
+func inceptionB(p *nn.Path, cIn int64) ts.ModuleT {
+	c := nn.NewConcat(1)
 
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
-		b1Ts := xs.ApplyT(b1, train)
+	c.AddNamed("branch3x3", convBn(p.Sub("branch3x3"), cIn, 384, 3, 0, 2))
 
-		b2Tmp1 := xs.ApplyT(b21, train)
-		b2Tmp2 := b2Tmp1.ApplyT(b22, train)
-		b2Tmp1.MustDrop()
-		b2Ts := b2Tmp2.ApplyT(b23, train)
-		b2Tmp2.MustDrop()
+	c.AddNamed("branch3x3dbl", inBranch(
+		nn.NamedModule{Name: "branch3x3dbl_1", Module: convBn(p.Sub("branch3x3dbl_1"), cIn, 64, 1, 0, 1)},
+		nn.NamedModule{Name: "branch3x3dbl_2", Module: convBn(p.Sub("branch3x3dbl_2"), 64, 96, 3, 1, 1)},
+		nn.NamedModule{Name: "branch3x3dbl_3", Module: convBn(p.Sub("branch3x3dbl_3"), 96, 96, 3, 0, 2)},
+	))
 
-		b3Tmp1 := xs.ApplyT(b31, train)
-		b3Tmp2 := b3Tmp1.ApplyT(b32, train)
-		b3Tmp1.MustDrop()
-		b3Tmp3 := b3Tmp2.ApplyT(b33, train)
-		b3Tmp2.MustDrop()
-		b3Tmp4 := b3Tmp3.ApplyT(b34, train)
-		b3Tmp3.MustDrop()
-		b3Ts := b3Tmp4.ApplyT(b35, train)
-		b3Tmp4.MustDrop()
+	c.AddNamed("pool", inMaxPool())
 
-		bpTmp1 := xs.MustAvgPool2d([]int64{3, 3}, []int64{1, 1}, []int64{1, 1}, false, true, []int64{9}, false)
-		bpoolTs := bpTmp1.ApplyT(bpool, train)
+	return c
+}
 
-		return ts.MustCat([]*ts.Tensor{b1Ts, b2Ts, b3Ts, bpoolTs}, 1)
-	})
+func inceptionC(p *nn.Path, cIn int64, c7 int64) ts.ModuleT {
+	c := nn.NewConcat(1)
+
+	c.AddNamed("branch1x1", convBn(p.Sub("branch1x1"), cIn, 192, 1, 0, 1))
+
+	c.AddNamed("branch7x7", inBranch(
+		nn.NamedModule{Name: "branch7x7_1", Module: convBn(p.Sub("branch7x7_1"), cIn, c7, 1, 0, 1)},
+		nn.NamedModule{Name: "branch7x7_2", Module: convBn2(p.Sub("branch7x7_2"), c7, c7, []int64{1, 7}, []int64{0, 3})},
+		nn.NamedModule{Name: "branch7x7_3", Module: convBn2(p.Sub("branch7x7_3"), c7, 192, []int64{7, 1}, []int64{3, 0})},
+	))
+
+	c.AddNamed("branch7x7dbl", inBranch(
+		nn.NamedModule{Name: "branch7x7dbl_1", Module: convBn(p.Sub("branch7x7dbl_1"), cIn, c7, 1, 0, 1)},
+		nn.NamedModule{Name: "branch7x7dbl_2", Module: convBn2(p.Sub("branch7x7dbl_2"), c7, c7, []int64{7, 1}, []int64{3, 0})},
+		nn.NamedModule{Name: "branch7x7dbl_3", Module: convBn2(p.Sub("branch7x7dbl_3"), c7, c7, []int64{1, 7}, []int64{0, 3})},
+		nn.NamedModule{Name: "branch7x7dbl_4", Module: convBn2(p.Sub("branch7x7dbl_4"), c7, c7, []int64{7, 1}, []int64{3, 0})},
+		nn.NamedModule{Name: "branch7x7dbl_5", Module: convBn2(p.Sub("branch7x7dbl_5"), c7, 192, []int64{1, 7}, []int64{0, 3})},
+	))
+
+	c.AddNamed("pool", inBranch(
+		nn.NamedModule{Name: "avg_pool", Module: inAvgPool()},
+		nn.NamedModule{Name: "branch_pool", Module: convBn(p.Sub("branch_pool"), cIn, 192, 1, 0, 1)},
+	))
+
+	return c
 }
 
 func inceptionD(p *nn.Path, cIn int64) ts.ModuleT {
+	c := nn.NewConcat(1)
 
-	b11 := convBn(p.Sub("branch3x3_1"), cIn, 192, 1, 0, 1)
-	b12 := convBn(p.Sub("branch3x3_2"), 192, 320, 3, 0, 2)
-
-	b21 := convBn(p.Sub("branch7x7x3_1"), cIn, 192, 1, 0, 1)
-	b22 := convBn2(p.Sub("branch7x7x3_2"), 192, 192, []int64{1, 7}, []int64{0, 3})
-	b23 := convBn2(p.Sub("branch7x7x3_3"), 192, 192, []int64{7, 1}, []int64{3, 0})
-	b24 := convBn(p.Sub("branch7x7x3_4"), 192, 192, 3, 0, 2)
+	c.AddNamed("branch3x3", inBranch(
+		nn.NamedModule{Name: "branch3x3_1", Module: convBn(p.Sub("branch3x3_1"), cIn, 192, 1, 0, 1)},
+		nn.NamedModule{Name: "branch3x3_2", Module: convBn(p.Sub("branch3x3_2"), 192, 320, 3, 0, 2)},
+	))
 
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
-		b1Tmp := xs.ApplyT(b11, train)
-		b1Ts := b1Tmp.ApplyT(b12, train)
-		b1Tmp.MustDrop()
+	c.AddNamed("branch7x7x3", inBranch(
+		nn.NamedModule{Name: "branch7x7x3_1", Module: convBn(p.Sub("branch7x7x3_1"), cIn, 192, 1, 0, 1)},
+		nn.NamedModule{Name: "branch7x7x3_2", Module: convBn2(p.Sub("branch7x7x3_2"), 192, 192, []int64{1, 7}, []int64{0, 3})},
+		nn.NamedModule{Name: "branch7x7x3_3", Module: convBn2(p.Sub("branch7x7x3_3"), 192, 192, []int64{7, 1}, []int64{3, 0})},
+		nn.NamedModule{Name: "branch7x7x3_4", Module: convBn(p.Sub("branch7x7x3_4"), 192, 192, 3, 0, 2)},
+	))
 
-		b2Tmp1 := xs.ApplyT(b21, train)
-		b2Tmp2 := b2Tmp1.ApplyT(b22, train)
-		b2Tmp1.MustDrop()
-		b2Tmp3 := b2Tmp2.ApplyT(b23, train)
-		b2Tmp2.MustDrop()
-		b2Ts := b2Tmp3.ApplyT(b24, train)
-		b2Tmp3.MustDrop()
+	c.AddNamed("pool", inMaxPool())
 
-		bpoolTs := inMaxPool2D(xs, 3, 2)
-
-		return ts.MustCat([]*ts.Tensor{b1Ts, b2Ts, bpoolTs}, 1)
-
-	})
+	return c
 }
 
 func inceptionE(p *nn.Path, cIn int64) ts.ModuleT {
-	b1 := convBn(p.Sub("branch1x1"), cIn, 320, 1, 0, 1)
-
-	b21 := convBn(p.Sub("branch3x3_1"), cIn, 384, 1, 0, 1)
-	b22a := convBn2(p.Sub("branch3x3_2a"), 384, 384, []int64{1, 3}, []int64{0, 1})
-	b22b := convBn2(p.Sub("branch3x3_2b"), 384, 384, []int64{3, 1}, []int64{1, 0})
-
-	b31 := convBn(p.Sub("branch3x3dbl_1"), cIn, 448, 1, 0, 1)
-	b32 := convBn(p.Sub("branch3x3dbl_2"), 448, 384, 3, 1, 1)
-	b33a := convBn2(p.Sub("branch3x3dbl_3a"), 384, 384, []int64{1, 3}, []int64{0, 1})
-	b33b := convBn2(p.Sub("branch3x3dbl_3b"), 384, 384, []int64{3, 1}, []int64{1, 0})
-
-	bpool := convBn(p.Sub("branch_pool"), cIn, 192, 1, 0, 1)
-
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
-		b1Ts := xs.ApplyT(b1, train)
-
-		b2Tmp := xs.ApplyT(b21, train)
-		b2aTs := b2Tmp.ApplyT(b22a, train)
-		b2bTs := b2Tmp.ApplyT(b22b, train)
-		b2Ts := ts.MustCat([]*ts.Tensor{b2aTs, b2bTs}, 1)
-
-		b3Tmp1 := xs.ApplyT(b31, train)
-		b3Tmp2 := b3Tmp1.ApplyT(b32, train)
-		b3Tmp1.MustDrop()
-		b3aTs := b3Tmp2.ApplyT(b33a, train)
-		b3bTs := b3Tmp2.ApplyT(b33b, train)
-		b3Ts := ts.MustCat([]*ts.Tensor{b3aTs, b3bTs}, 1)
-
-		bpTmp1 := xs.MustAvgPool2d([]int64{3, 3}, []int64{1, 1}, []int64{1, 1}, false, true, []int64{9}, false)
-		bpoolTs := bpTmp1.ApplyT(bpool, train)
-
-		return ts.MustCat([]*ts.Tensor{b1Ts, b2Ts, b3Ts, bpoolTs}, 1)
-	})
-
+	c := nn.NewConcat(1)
+
+	c.AddNamed("branch1x1", convBn(p.Sub("branch1x1"), cIn, 320, 1, 0, 1))
+
+	b2 := nn.NewConcat(1)
+	b2.AddNamed("branch3x3_2a", convBn2(p.Sub("branch3x3_2a"), 384, 384, []int64{1, 3}, []int64{0, 1}))
+	b2.AddNamed("branch3x3_2b", convBn2(p.Sub("branch3x3_2b"), 384, 384, []int64{3, 1}, []int64{1, 0}))
+	c.AddNamed("branch3x3", inBranch(
+		nn.NamedModule{Name: "branch3x3_1", Module: convBn(p.Sub("branch3x3_1"), cIn, 384, 1, 0, 1)},
+		nn.NamedModule{Name: "branch3x3_2", Module: b2},
+	))
+
+	b3 := nn.NewConcat(1)
+	b3.AddNamed("branch3x3dbl_3a", convBn2(p.Sub("branch3x3dbl_3a"), 384, 384, []int64{1, 3}, []int64{0, 1}))
+	b3.AddNamed("branch3x3dbl_3b", convBn2(p.Sub("branch3x3dbl_3b"), 384, 384, []int64{3, 1}, []int64{1, 0}))
+	c.AddNamed("branch3x3dbl", inBranch(
+		nn.NamedModule{Name: "branch3x3dbl_1", Module: convBn(p.Sub("branch3x3dbl_1"), cIn, 448, 1, 0, 1)},
+		nn.NamedModule{Name: "branch3x3dbl_2", Module: convBn(p.Sub("branch3x3dbl_2"), 448, 384, 3, 1, 1)},
+		nn.NamedModule{Name: "branch3x3dbl_3", Module: b3},
+	))
+
+	c.AddNamed("pool", inBranch(
+		nn.NamedModule{Name: "avg_pool", Module: inAvgPool()},
+		nn.NamedModule{Name: "branch_pool", Module: convBn(p.Sub("branch_pool"), cIn, 192, 1, 0, 1)},
+	))
+
+	return c
 }
 
 func InceptionV3(p *nn.Path, nclasses int64) ts.ModuleT {
@@ -54,7 +54,7 @@ func inv(p *nn.Path, cIn, cOut, stride, er int64) ts.ModuleT {
 
 	seq.Add(nn.BatchNorm2D(p.Sub(fmt.Sprintf("%v", id+2)), cOut, nn.DefaultBatchNormConfig()))
 
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
+	return nn.NewNamedFuncT("InvertedResidual", func(xs *ts.Tensor, train bool) *ts.Tensor {
 		ys := xs.ApplyT(seq, train)
 		if stride == 1 && cIn == cOut {
 			res := ys.MustAdd(xs, true)
@@ -62,7 +62,7 @@ func inv(p *nn.Path, cIn, cOut, stride, er int64) ts.ModuleT {
 		} else {
 			return ys
 		}
-	})
+	}, nn.NamedModule{Name: "conv", Module: seq})
 }
 
 var invertedResidualSettings [][]int64 = [][]int64{
@@ -114,7 +114,7 @@ func MobileNetV2(p *nn.Path, nclasses int64) ts.ModuleT {
 
 	classifier.Add(nn.NewLinear(cp.Sub("1"), 1280, nclasses, nn.DefaultLinearConfig()))
 
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
+	return nn.NewNamedFuncT("MobileNetV2", func(xs *ts.Tensor, train bool) *ts.Tensor {
 		tmp1 := xs.ApplyT(features, train)
 
 		tmp2 := tmp1.MustMeanDim([]int64{2}, false, gotch.Float, true)
@@ -124,6 +124,9 @@ func MobileNetV2(p *nn.Path, nclasses int64) ts.ModuleT {
 		tmp3.MustDrop()
 
 		return res
-	})
+	},
+		nn.NamedModule{Name: "features", Module: features},
+		nn.NamedModule{Name: "classifier", Module: classifier},
+	)
 
 }
@@ -101,6 +101,23 @@ func (bb *basicBlock) ForwardT(x *ts.Tensor, train bool) *ts.Tensor {
 	return res
 }
 
+// Name implements nn.ModuleInfo for basicBlock.
+func (bb *basicBlock) Name() string { return "BasicBlock" }
+
+// Children implements nn.ModuleInfo for basicBlock.
+func (bb *basicBlock) Children() []nn.NamedModule {
+	return []nn.NamedModule{
+		{Name: "conv1", Module: bb.Conv1},
+		{Name: "bn1", Module: bb.Bn1},
+		{Name: "conv2", Module: bb.Conv2},
+		{Name: "bn2", Module: bb.Bn2},
+		{Name: "downsample", Module: bb.Downsample},
+	}
+}
+
+// Parameters implements nn.ModuleInfo for basicBlock.
+func (bb *basicBlock) Parameters() []ts.NamedTensor { return nil }
+
 func resnet(p *nn.Path, nclasses int64, c1, c2, c3, c4 int64) nn.FuncT {
 	seq := nn.SeqT()
 	layer0 := layerZero(p)
@@ -118,7 +135,7 @@ func resnet(p *nn.Path, nclasses int64, c1, c2, c3, c4 int64) nn.FuncT {
 		// With final layer
 		linearConfig := nn.DefaultLinearConfig()
 		fc := nn.NewLinear(p.Sub("fc"), 512, nclasses, linearConfig)
-		return nn.NewFuncT(func(x *ts.Tensor, train bool) *ts.Tensor {
+		return nn.NewNamedFuncT("ResNet", func(x *ts.Tensor, train bool) *ts.Tensor {
 			output := seq.ForwardT(x, train)
 			avgpool := output.MustAdaptiveAvgPool2d([]int64{1, 1}, true)
 			fv := avgpool.FlatView()
@@ -127,17 +144,17 @@ func resnet(p *nn.Path, nclasses int64, c1, c2, c3, c4 int64) nn.FuncT {
 			fv.MustDrop()
 
 			return retVal
-		})
+		}, nn.NamedModule{Name: "features", Module: seq}, nn.NamedModule{Name: "fc", Module: fc})
 	} else {
 		// no final layer
-		return nn.NewFuncT(func(x *ts.Tensor, train bool) *ts.Tensor {
+		return nn.NewNamedFuncT("ResNet", func(x *ts.Tensor, train bool) *ts.Tensor {
 			output := seq.ForwardT(x, train)
 			avgpool := output.MustAdaptiveAvgPool2d([]int64{1, 1}, true)
 			retVal := avgpool.FlatView()
 			avgpool.MustDrop()
 
 			return retVal
-		})
+		}, nn.NamedModule{Name: "features", Module: seq})
 	}
 }
 
@@ -172,6 +189,25 @@ func (b *bottleneckBlock) ForwardT(xs *ts.Tensor, train bool) *ts.Tensor {
 	return res
 }
 
+// Name implements nn.ModuleInfo for bottleneckBlock.
+func (b *bottleneckBlock) Name() string { return "Bottleneck" }
+
+// Children implements nn.ModuleInfo for bottleneckBlock.
+func (b *bottleneckBlock) Children() []nn.NamedModule {
+	return []nn.NamedModule{
+		{Name: "conv1", Module: b.Conv1},
+		{Name: "bn1", Module: b.Bn1},
+		{Name: "conv2", Module: b.Conv2},
+		{Name: "bn2", Module: b.Bn2},
+		{Name: "conv3", Module: b.Conv3},
+		{Name: "bn3", Module: b.Bn3},
+		{Name: "downsample", Module: b.Downsample},
+	}
+}
+
+// Parameters implements nn.ModuleInfo for bottleneckBlock.
+func (b *bottleneckBlock) Parameters() []ts.NamedTensor { return nil }
+
 // Bottleneck versions for ResNet 50, 101, and 152.
 func newBottleneckBlock(path *nn.Path, cIn, cOut, stride, e int64) *bottleneckBlock {
 	eDim := e * cOut
@@ -225,7 +261,7 @@ func bottleneckResnet(path *nn.Path, nclasses int64, c1, c2, c3, c4 int64) ts.Mo
 		// With final layer
 		linearConfig := nn.DefaultLinearConfig()
 		fc := nn.NewLinear(path.Sub("fc"), 4*512, nclasses, linearConfig)
-		return nn.NewFuncT(func(x *ts.Tensor, train bool) *ts.Tensor {
+		return nn.NewNamedFuncT("ResNet", func(x *ts.Tensor, train bool) *ts.Tensor {
 			output := seq.ForwardT(x, train)
 			avgpool := output.MustAdaptiveAvgPool2d([]int64{1, 1}, true)
 			fv := avgpool.FlatView()
@@ -234,17 +270,17 @@ func bottleneckResnet(path *nn.Path, nclasses int64, c1, c2, c3, c4 int64) ts.Mo
 			fv.MustDrop()
 
 			return retVal
-		})
+		}, nn.NamedModule{Name: "features", Module: seq}, nn.NamedModule{Name: "fc", Module: fc})
 	} else {
 		// no final layer
-		return nn.NewFuncT(func(x *ts.Tensor, train bool) *ts.Tensor {
+		return nn.NewNamedFuncT("ResNet", func(x *ts.Tensor, train bool) *ts.Tensor {
 			output := seq.ForwardT(x, train)
 			avgpool := output.MustAdaptiveAvgPool2d([]int64{1, 1}, true)
 			retVal := avgpool.FlatView()
 			avgpool.MustDrop()
 
 			return retVal
-		})
+		}, nn.NamedModule{Name: "features", Module: seq})
 	}
 }
 
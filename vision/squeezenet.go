@@ -21,7 +21,7 @@ func fire(p *nn.Path, cIn int64, cSqueeze int64, cExp1 int64, cExp3 int64) ts.Mo
 	exp3 := nn.NewConv2D(p.Sub("expand3x3"), cSqueeze, cExp3, 3, cfg3)
 
 	// NOTE: train will not be used
-	return nn.NewFuncT(func(xs *ts.Tensor, train bool) *ts.Tensor {
+	return nn.NewNamedFuncT("Fire", func(xs *ts.Tensor, train bool) *ts.Tensor {
 		tmp1 := xs.Apply(squeeze)
 		tmp2 := tmp1.MustRelu(true)
 
@@ -32,7 +32,11 @@ func fire(p *nn.Path, cIn int64, cSqueeze int64, cExp1 int64, cExp3 int64) ts.Mo
 		exp3Ts := exp3Tmp.MustRelu(true)
 
 		return ts.MustCat([]*ts.Tensor{exp1Ts, exp3Ts}, 1)
-	})
+	},
+		nn.NamedModule{Name: "squeeze", Module: squeeze},
+		nn.NamedModule{Name: "expand1x1", Module: exp1},
+		nn.NamedModule{Name: "expand3x3", Module: exp3},
+	)
 }
 
 func squeezenet(p *nn.Path, v1_0 bool, nclasses int64) ts.ModuleT {